@@ -0,0 +1,21 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+// Hub marks this type as a conversion hub.
+func (*CronJob) Hub() {}
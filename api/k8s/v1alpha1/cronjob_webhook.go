@@ -0,0 +1,90 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *CronJob) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&CronJob{}).
+		WithValidator(&CronJob{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-k8s-tensegrity-fastforge-io-v1alpha1-cronjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=k8s.tensegrity.fastforge.io,resources=cronjobs,verbs=create;update,versions=v1alpha1,name=mcronjob.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &CronJob{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *CronJob) Default(_ context.Context, obj runtime.Object) error {
+	cronJob, ok := obj.(*CronJob)
+	if !ok {
+		return fmt.Errorf("expected a CronJob but got a %T", obj)
+	}
+	cronJob.Spec.TensegritySpec.SetDefaultProducesName(cronJob.GetName())
+	cronJob.Spec.TensegritySpec.SetDefaultNamespaceDelegate(cronJob.GetNamespace())
+	cronJob.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(cronJob.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
+	cronJob.Spec.TensegritySpec.SetDefaultConsumesSecretName(cronJob.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
+	cronJob.Spec.TensegritySpec.SetDefaultProducesConfigMapName(cronJob.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
+	cronJob.Spec.TensegritySpec.SetDefaultProducesSecretName(cronJob.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-k8s-tensegrity-fastforge-io-v1alpha1-cronjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=k8s.tensegrity.fastforge.io,resources=cronjobs,verbs=create;update,versions=v1alpha1,name=vcronjob.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &CronJob{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *CronJob) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateCronJob(obj)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *CronJob) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateCronJob(newObj)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *CronJob) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateCronJob(obj)
+}
+
+// validateCronJob validates the Tensegrity spec of a CronJob being admitted.
+func validateCronJob(obj runtime.Object) error {
+	cronJob, ok := obj.(*CronJob)
+	if !ok {
+		return fmt.Errorf("expected a CronJob but got a %T", obj)
+	}
+	if errs := cronJob.Spec.TensegritySpec.Validate(); len(errs) > 0 {
+		return apierrors.NewInvalid(cronJob.GetObjectKind().GroupVersionKind().GroupKind(), cronJob.GetName(), errs)
+	}
+	return nil
+}
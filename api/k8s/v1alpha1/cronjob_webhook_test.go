@@ -0,0 +1,126 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("CronJob Webhook", func() {
+	newCronJob := func() *CronJob {
+		return &CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+			Spec: CronJobSpec{
+				TensegritySpec: v1alpha1.TensegritySpec{
+					Consumes: []v1alpha1.ConsumesSpec{
+						{ObjectReference: corev1.ObjectReference{
+							APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "postgres"},
+							Maps: map[string]string{"POSTGRES_HOST": "host"}},
+					},
+					Produces: []v1alpha1.ProducesSpec{
+						{Key: "schedule", ProducesSourceSpec: v1alpha1.ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: "batch/v1", Kind: "CronJob", FieldPath: "{ .spec.schedule }"}}},
+					},
+				},
+			},
+		}
+	}
+
+	Context("When creating CronJob under Defaulting Webhook", func() {
+		It("Should fill in the default value if a required field is empty", func() {
+			cronJob := newCronJob()
+			Expect(new(CronJob).Default(context.Background(), cronJob)).To(Succeed())
+			Expect(cronJob.Spec.Produces[0].Name).To(Equal("report"))
+			Expect(cronJob.Spec.Delegates).To(ConsistOf(v1alpha1.DelegateSpec{
+				ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "default"}}))
+			Expect(cronJob.Spec.ConsumesConfigMapName).To(Equal("report-consumed"))
+			Expect(cronJob.Spec.ConsumesSecretName).To(Equal("report-consumed"))
+			Expect(cronJob.Spec.ProducesConfigMapName).To(Equal("report-produced"))
+			Expect(cronJob.Spec.ProducesSecretName).To(Equal("report-produced"))
+		})
+
+		It("Should keep values which are set", func() {
+			cronJob := newCronJob()
+			cronJob.Spec.ConsumesConfigMapName = "report-env"
+			cronJob.Spec.Delegates = []v1alpha1.DelegateSpec{
+				{ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "shared"}}}
+			Expect(new(CronJob).Default(context.Background(), cronJob)).To(Succeed())
+			Expect(cronJob.Spec.ConsumesConfigMapName).To(Equal("report-env"))
+			Expect(cronJob.Spec.Delegates).To(HaveLen(1))
+			Expect(cronJob.Spec.Delegates[0].Name).To(Equal("shared"))
+		})
+	})
+
+	Context("When creating CronJob under Validating Webhook", func() {
+		It("Should deny if a required field is empty", func() {
+			cronJob := newCronJob()
+			cronJob.Spec.Consumes[0].Name = ""
+			cronJob.Spec.Consumes[0].Maps = nil
+			Expect(new(CronJob).Default(context.Background(), cronJob)).To(Succeed())
+			_, err := new(CronJob).ValidateCreate(context.Background(), cronJob)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].maps"))
+		})
+
+		It("Should admit if all required fields are provided", func() {
+			cronJob := newCronJob()
+			Expect(new(CronJob).Default(context.Background(), cronJob)).To(Succeed())
+			_, err := new(CronJob).ValidateCreate(context.Background(), cronJob)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = new(CronJob).ValidateUpdate(context.Background(), cronJob, cronJob)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When admitting CronJob through the API server", func() {
+		newAdmittedCronJob := func(name string) *CronJob {
+			cronJob := newCronJob()
+			cronJob.Name = name
+			cronJob.Spec.Schedule = "*/5 * * * *"
+			cronJob.Spec.JobTemplate.Spec.Template = corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers:    []corev1.Container{{Name: "report", Image: "report"}},
+			}}
+			return cronJob
+		}
+
+		It("Should default the CronJob being created", func() {
+			cronJob := newAdmittedCronJob("report-admitted")
+			Expect(k8sClient.Create(ctx, cronJob)).To(Succeed())
+			Expect(cronJob.Spec.ConsumesConfigMapName).To(Equal("report-admitted-consumed"))
+			Expect(cronJob.Spec.ProducesSecretName).To(Equal("report-admitted-produced"))
+			Expect(k8sClient.Delete(ctx, cronJob)).To(Succeed())
+		})
+
+		It("Should deny the CronJob being created with an invalid spec", func() {
+			cronJob := newAdmittedCronJob("report-denied")
+			cronJob.Spec.Consumes[0].Maps = nil
+			Expect(k8sClient.Create(ctx, cronJob)).To(MatchError(ContainSubstring("spec.consumes[0].maps")))
+		})
+	})
+})
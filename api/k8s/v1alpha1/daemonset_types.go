@@ -36,13 +36,37 @@ type DaemonSetSpec struct {
 type DaemonSetStatus struct {
 	// Tensegrity status.
 	v1alpha1.TensegrityStatus `json:",inline"`
+	// DesiredNumberScheduled is a number of nodes which should run a pod of the child DaemonSet.
+	// +optional
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled,omitempty"`
+	// CurrentNumberScheduled is a number of nodes running a pod of the child DaemonSet.
+	// +optional
+	CurrentNumberScheduled int32 `json:"currentNumberScheduled,omitempty"`
+	// NumberReady is a number of nodes running a ready pod of the child DaemonSet.
+	// +optional
+	NumberReady int32 `json:"numberReady,omitempty"`
+	// UpdatedNumberScheduled is a number of nodes running an updated pod of the child DaemonSet.
+	// +optional
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled,omitempty"`
+	// NumberAvailable is a number of nodes running an available pod of the child DaemonSet.
+	// +optional
+	NumberAvailable int32 `json:"numberAvailable,omitempty"`
+	// WorkloadConditions are conditions of the child DaemonSet.
+	// +optional
+	WorkloadConditions []appsv1.DaemonSetCondition `json:"workloadConditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredNumberScheduled`
+// +kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.status.currentNumberScheduled`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.numberReady`
+// +kubebuilder:printcolumn:name="Up-to-date",type=integer,JSONPath=`.status.updatedNumberScheduled`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.numberAvailable`
 // +kubebuilder:printcolumn:name="Produced",type=string,JSONPath=`.status.produced`
 // +kubebuilder:printcolumn:name="Produced Config Map",type=string,JSONPath=`.status.producedConfigMapName`
 // +kubebuilder:printcolumn:name="Produced Secret",type=string,JSONPath=`.status.producedSecretName`
+// +kubebuilder:printcolumn:name="Consumers",type=integer,JSONPath=`.status.consumerCount`,priority=1
 // +kubebuilder:printcolumn:name="Consumed",type=string,JSONPath=`.status.consumed`
 // +kubebuilder:printcolumn:name="Consumed Config Map",type=string,JSONPath=`.status.consumedConfigMapName`
 // +kubebuilder:printcolumn:name="Consumed Secret",type=string,JSONPath=`.status.consumedSecretName`
@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,8 @@ import (
 func (r *DaemonSet) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&DaemonSet{}).
+		WithValidator(&DaemonSet{}).
 		Complete()
 }
 
@@ -41,13 +44,17 @@ func (r *DaemonSet) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.CustomDefaulter = &DaemonSet{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
-func (r *DaemonSet) Default(_ context.Context, _ runtime.Object) error {
-	r.Spec.TensegritySpec.SetDefaultProducesName(r.GetName())
-	r.Spec.TensegritySpec.SetDefaultNamespaceDelegate(r.GetNamespace())
-	r.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(r.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultConsumesSecretName(r.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesConfigMapName(r.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesSecretName(r.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
+func (r *DaemonSet) Default(_ context.Context, obj runtime.Object) error {
+	daemonSet, ok := obj.(*DaemonSet)
+	if !ok {
+		return fmt.Errorf("expected a DaemonSet but got a %T", obj)
+	}
+	daemonSet.Spec.TensegritySpec.SetDefaultProducesName(daemonSet.GetName())
+	daemonSet.Spec.TensegritySpec.SetDefaultNamespaceDelegate(daemonSet.GetNamespace())
+	daemonSet.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(daemonSet.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
+	daemonSet.Spec.TensegritySpec.SetDefaultConsumesSecretName(daemonSet.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
+	daemonSet.Spec.TensegritySpec.SetDefaultProducesConfigMapName(daemonSet.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
+	daemonSet.Spec.TensegritySpec.SetDefaultProducesSecretName(daemonSet.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
 	return nil
 }
 
@@ -56,25 +63,28 @@ func (r *DaemonSet) Default(_ context.Context, _ runtime.Object) error {
 var _ webhook.CustomValidator = &DaemonSet{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *DaemonSet) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *DaemonSet) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateDaemonSet(obj)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *DaemonSet) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *DaemonSet) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateDaemonSet(newObj)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *DaemonSet) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
+func (r *DaemonSet) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateDaemonSet(obj)
+}
+
+// validateDaemonSet validates the Tensegrity spec of a DaemonSet being admitted.
+func validateDaemonSet(obj runtime.Object) error {
+	daemonSet, ok := obj.(*DaemonSet)
+	if !ok {
+		return fmt.Errorf("expected a DaemonSet but got a %T", obj)
+	}
+	if errs := daemonSet.Spec.TensegritySpec.Validate(); len(errs) > 0 {
+		return apierrors.NewInvalid(daemonSet.GetObjectKind().GroupVersionKind().GroupKind(), daemonSet.GetName(), errs)
 	}
-	return nil, nil
+	return nil
 }
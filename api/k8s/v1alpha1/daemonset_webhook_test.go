@@ -18,31 +18,113 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
 )
 
 var _ = Describe("DaemonSet Webhook", func() {
+	newDaemonSet := func() *DaemonSet {
+		return &DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+			Spec: DaemonSetSpec{
+				TensegritySpec: v1alpha1.TensegritySpec{
+					Consumes: []v1alpha1.ConsumesSpec{
+						{ObjectReference: corev1.ObjectReference{
+							APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "postgres"},
+							Maps: map[string]string{"POSTGRES_HOST": "host"}},
+					},
+					Produces: []v1alpha1.ProducesSpec{
+						{Key: "minReadySeconds", ProducesSourceSpec: v1alpha1.ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: "apps/v1", Kind: "DaemonSet", FieldPath: "{ .spec.minReadySeconds }"}}},
+					},
+				},
+			},
+		}
+	}
 
 	Context("When creating DaemonSet under Defaulting Webhook", func() {
 		It("Should fill in the default value if a required field is empty", func() {
+			daemonSet := newDaemonSet()
+			Expect(new(DaemonSet).Default(context.Background(), daemonSet)).To(Succeed())
+			Expect(daemonSet.Spec.Produces[0].Name).To(Equal("agent"))
+			Expect(daemonSet.Spec.Delegates).To(ConsistOf(v1alpha1.DelegateSpec{
+				ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "default"}}))
+			Expect(daemonSet.Spec.ConsumesConfigMapName).To(Equal("agent-consumed"))
+			Expect(daemonSet.Spec.ConsumesSecretName).To(Equal("agent-consumed"))
+			Expect(daemonSet.Spec.ProducesConfigMapName).To(Equal("agent-produced"))
+			Expect(daemonSet.Spec.ProducesSecretName).To(Equal("agent-produced"))
+		})
 
-			// TODO(user): Add your logic here
-
+		It("Should keep values which are set", func() {
+			daemonSet := newDaemonSet()
+			daemonSet.Spec.ConsumesConfigMapName = "agent-env"
+			daemonSet.Spec.Delegates = []v1alpha1.DelegateSpec{
+				{ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "shared"}}}
+			Expect(new(DaemonSet).Default(context.Background(), daemonSet)).To(Succeed())
+			Expect(daemonSet.Spec.ConsumesConfigMapName).To(Equal("agent-env"))
+			Expect(daemonSet.Spec.Delegates).To(HaveLen(1))
+			Expect(daemonSet.Spec.Delegates[0].Name).To(Equal("shared"))
 		})
 	})
 
 	Context("When creating DaemonSet under Validating Webhook", func() {
 		It("Should deny if a required field is empty", func() {
-
-			// TODO(user): Add your logic here
-
+			daemonSet := newDaemonSet()
+			daemonSet.Spec.Consumes[0].Name = ""
+			daemonSet.Spec.Consumes[0].Maps = nil
+			Expect(new(DaemonSet).Default(context.Background(), daemonSet)).To(Succeed())
+			_, err := new(DaemonSet).ValidateCreate(context.Background(), daemonSet)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].maps"))
 		})
 
 		It("Should admit if all required fields are provided", func() {
+			daemonSet := newDaemonSet()
+			Expect(new(DaemonSet).Default(context.Background(), daemonSet)).To(Succeed())
+			_, err := new(DaemonSet).ValidateCreate(context.Background(), daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = new(DaemonSet).ValidateUpdate(context.Background(), daemonSet, daemonSet)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 
-			// TODO(user): Add your logic here
+	Context("When admitting DaemonSet through the API server", func() {
+		newAdmittedDaemonSet := func(name string) *DaemonSet {
+			daemonSet := newDaemonSet()
+			daemonSet.Name = name
+			labels := map[string]string{"app": name}
+			daemonSet.Spec.DaemonSetSpec = appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent", Image: "agent"}}},
+				},
+			}
+			return daemonSet
+		}
+
+		It("Should default the DaemonSet being created", func() {
+			daemonSet := newAdmittedDaemonSet("agent-admitted")
+			Expect(k8sClient.Create(ctx, daemonSet)).To(Succeed())
+			Expect(daemonSet.Spec.ConsumesConfigMapName).To(Equal("agent-admitted-consumed"))
+			Expect(daemonSet.Spec.ProducesSecretName).To(Equal("agent-admitted-produced"))
+			Expect(k8sClient.Delete(ctx, daemonSet)).To(Succeed())
+		})
 
+		It("Should deny the DaemonSet being created with an invalid spec", func() {
+			daemonSet := newAdmittedDaemonSet("agent-denied")
+			daemonSet.Spec.Consumes[0].Maps = nil
+			Expect(k8sClient.Create(ctx, daemonSet)).To(MatchError(ContainSubstring("spec.consumes[0].maps")))
 		})
 	})
-
 })
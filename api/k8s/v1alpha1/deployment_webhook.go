@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,8 @@ import (
 func (r *Deployment) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&Deployment{}).
+		WithValidator(&Deployment{}).
 		Complete()
 }
 
@@ -41,13 +44,17 @@ func (r *Deployment) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.CustomDefaulter = &Deployment{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
-func (r *Deployment) Default(_ context.Context, _ runtime.Object) error {
-	r.Spec.TensegritySpec.SetDefaultProducesName(r.GetName())
-	r.Spec.TensegritySpec.SetDefaultNamespaceDelegate(r.GetNamespace())
-	r.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(r.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultConsumesSecretName(r.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesConfigMapName(r.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesSecretName(r.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
+func (r *Deployment) Default(_ context.Context, obj runtime.Object) error {
+	deployment, ok := obj.(*Deployment)
+	if !ok {
+		return fmt.Errorf("expected a Deployment but got a %T", obj)
+	}
+	deployment.Spec.TensegritySpec.SetDefaultProducesName(deployment.GetName())
+	deployment.Spec.TensegritySpec.SetDefaultNamespaceDelegate(deployment.GetNamespace())
+	deployment.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(deployment.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
+	deployment.Spec.TensegritySpec.SetDefaultConsumesSecretName(deployment.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
+	deployment.Spec.TensegritySpec.SetDefaultProducesConfigMapName(deployment.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
+	deployment.Spec.TensegritySpec.SetDefaultProducesSecretName(deployment.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
 	return nil
 }
 
@@ -56,25 +63,28 @@ func (r *Deployment) Default(_ context.Context, _ runtime.Object) error {
 var _ webhook.CustomValidator = &Deployment{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *Deployment) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *Deployment) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateDeployment(obj)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *Deployment) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *Deployment) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateDeployment(newObj)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *Deployment) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
+func (r *Deployment) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateDeployment(obj)
+}
+
+// validateDeployment validates the Tensegrity spec of a Deployment being admitted.
+func validateDeployment(obj runtime.Object) error {
+	deployment, ok := obj.(*Deployment)
+	if !ok {
+		return fmt.Errorf("expected a Deployment but got a %T", obj)
+	}
+	if errs := deployment.Spec.TensegritySpec.Validate(); len(errs) > 0 {
+		return apierrors.NewInvalid(deployment.GetObjectKind().GroupVersionKind().GroupKind(), deployment.GetName(), errs)
 	}
-	return nil, nil
+	return nil
 }
@@ -18,31 +18,113 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
 )
 
 var _ = Describe("Deployment Webhook", func() {
+	newDeployment := func() *Deployment {
+		return &Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: DeploymentSpec{
+				TensegritySpec: v1alpha1.TensegritySpec{
+					Consumes: []v1alpha1.ConsumesSpec{
+						{ObjectReference: corev1.ObjectReference{
+							APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "postgres"},
+							Maps: map[string]string{"POSTGRES_HOST": "host"}},
+					},
+					Produces: []v1alpha1.ProducesSpec{
+						{Key: "replicas", ProducesSourceSpec: v1alpha1.ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: "apps/v1", Kind: "Deployment", FieldPath: "{ .spec.replicas }"}}},
+					},
+				},
+			},
+		}
+	}
 
 	Context("When creating Deployment under Defaulting Webhook", func() {
 		It("Should fill in the default value if a required field is empty", func() {
+			deployment := newDeployment()
+			Expect(new(Deployment).Default(context.Background(), deployment)).To(Succeed())
+			Expect(deployment.Spec.Produces[0].Name).To(Equal("api"))
+			Expect(deployment.Spec.Delegates).To(ConsistOf(v1alpha1.DelegateSpec{
+				ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "default"}}))
+			Expect(deployment.Spec.ConsumesConfigMapName).To(Equal("api-consumed"))
+			Expect(deployment.Spec.ConsumesSecretName).To(Equal("api-consumed"))
+			Expect(deployment.Spec.ProducesConfigMapName).To(Equal("api-produced"))
+			Expect(deployment.Spec.ProducesSecretName).To(Equal("api-produced"))
+		})
 
-			// TODO(user): Add your logic here
-
+		It("Should keep values which are set", func() {
+			deployment := newDeployment()
+			deployment.Spec.ConsumesConfigMapName = "api-env"
+			deployment.Spec.Delegates = []v1alpha1.DelegateSpec{
+				{ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "shared"}}}
+			Expect(new(Deployment).Default(context.Background(), deployment)).To(Succeed())
+			Expect(deployment.Spec.ConsumesConfigMapName).To(Equal("api-env"))
+			Expect(deployment.Spec.Delegates).To(HaveLen(1))
+			Expect(deployment.Spec.Delegates[0].Name).To(Equal("shared"))
 		})
 	})
 
 	Context("When creating Deployment under Validating Webhook", func() {
 		It("Should deny if a required field is empty", func() {
-
-			// TODO(user): Add your logic here
-
+			deployment := newDeployment()
+			deployment.Spec.Consumes[0].Name = ""
+			deployment.Spec.Consumes[0].Maps = nil
+			Expect(new(Deployment).Default(context.Background(), deployment)).To(Succeed())
+			_, err := new(Deployment).ValidateCreate(context.Background(), deployment)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].maps"))
 		})
 
 		It("Should admit if all required fields are provided", func() {
+			deployment := newDeployment()
+			Expect(new(Deployment).Default(context.Background(), deployment)).To(Succeed())
+			_, err := new(Deployment).ValidateCreate(context.Background(), deployment)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = new(Deployment).ValidateUpdate(context.Background(), deployment, deployment)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 
-			// TODO(user): Add your logic here
+	Context("When admitting Deployment through the API server", func() {
+		newAdmittedDeployment := func(name string) *Deployment {
+			deployment := newDeployment()
+			deployment.Name = name
+			labels := map[string]string{"app": name}
+			deployment.Spec.DeploymentSpec = appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "api"}}},
+				},
+			}
+			return deployment
+		}
+
+		It("Should default the Deployment being created", func() {
+			deployment := newAdmittedDeployment("api-admitted")
+			Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+			Expect(deployment.Spec.ConsumesConfigMapName).To(Equal("api-admitted-consumed"))
+			Expect(deployment.Spec.ProducesSecretName).To(Equal("api-admitted-produced"))
+			Expect(k8sClient.Delete(ctx, deployment)).To(Succeed())
+		})
 
+		It("Should deny the Deployment being created with an invalid spec", func() {
+			deployment := newAdmittedDeployment("api-denied")
+			deployment.Spec.Consumes[0].Maps = nil
+			Expect(k8sClient.Create(ctx, deployment)).To(MatchError(ContainSubstring("spec.consumes[0].maps")))
 		})
 	})
-
 })
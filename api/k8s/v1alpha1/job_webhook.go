@@ -0,0 +1,90 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *Job) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&Job{}).
+		WithValidator(&Job{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-k8s-tensegrity-fastforge-io-v1alpha1-job,mutating=true,failurePolicy=fail,sideEffects=None,groups=k8s.tensegrity.fastforge.io,resources=jobs,verbs=create;update,versions=v1alpha1,name=mjob.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &Job{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *Job) Default(_ context.Context, obj runtime.Object) error {
+	job, ok := obj.(*Job)
+	if !ok {
+		return fmt.Errorf("expected a Job but got a %T", obj)
+	}
+	job.Spec.TensegritySpec.SetDefaultProducesName(job.GetName())
+	job.Spec.TensegritySpec.SetDefaultNamespaceDelegate(job.GetNamespace())
+	job.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(job.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
+	job.Spec.TensegritySpec.SetDefaultConsumesSecretName(job.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
+	job.Spec.TensegritySpec.SetDefaultProducesConfigMapName(job.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
+	job.Spec.TensegritySpec.SetDefaultProducesSecretName(job.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-k8s-tensegrity-fastforge-io-v1alpha1-job,mutating=false,failurePolicy=fail,sideEffects=None,groups=k8s.tensegrity.fastforge.io,resources=jobs,verbs=create;update,versions=v1alpha1,name=vjob.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &Job{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Job) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateJob(obj)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Job) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateJob(newObj)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Job) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateJob(obj)
+}
+
+// validateJob validates the Tensegrity spec of a Job being admitted.
+func validateJob(obj runtime.Object) error {
+	job, ok := obj.(*Job)
+	if !ok {
+		return fmt.Errorf("expected a Job but got a %T", obj)
+	}
+	if errs := job.Spec.TensegritySpec.Validate(); len(errs) > 0 {
+		return apierrors.NewInvalid(job.GetObjectKind().GroupVersionKind().GroupKind(), job.GetName(), errs)
+	}
+	return nil
+}
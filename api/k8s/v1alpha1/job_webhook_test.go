@@ -0,0 +1,125 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("Job Webhook", func() {
+	newJob := func() *Job {
+		return &Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+			Spec: JobSpec{
+				TensegritySpec: v1alpha1.TensegritySpec{
+					Consumes: []v1alpha1.ConsumesSpec{
+						{ObjectReference: corev1.ObjectReference{
+							APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "postgres"},
+							Maps: map[string]string{"POSTGRES_HOST": "host"}},
+					},
+					Produces: []v1alpha1.ProducesSpec{
+						{Key: "completions", ProducesSourceSpec: v1alpha1.ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: "batch/v1", Kind: "Job", FieldPath: "{ .spec.completions }"}}},
+					},
+				},
+			},
+		}
+	}
+
+	Context("When creating Job under Defaulting Webhook", func() {
+		It("Should fill in the default value if a required field is empty", func() {
+			job := newJob()
+			Expect(new(Job).Default(context.Background(), job)).To(Succeed())
+			Expect(job.Spec.Produces[0].Name).To(Equal("migrate"))
+			Expect(job.Spec.Delegates).To(ConsistOf(v1alpha1.DelegateSpec{
+				ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "default"}}))
+			Expect(job.Spec.ConsumesConfigMapName).To(Equal("migrate-consumed"))
+			Expect(job.Spec.ConsumesSecretName).To(Equal("migrate-consumed"))
+			Expect(job.Spec.ProducesConfigMapName).To(Equal("migrate-produced"))
+			Expect(job.Spec.ProducesSecretName).To(Equal("migrate-produced"))
+		})
+
+		It("Should keep values which are set", func() {
+			job := newJob()
+			job.Spec.ConsumesConfigMapName = "migrate-env"
+			job.Spec.Delegates = []v1alpha1.DelegateSpec{
+				{ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "shared"}}}
+			Expect(new(Job).Default(context.Background(), job)).To(Succeed())
+			Expect(job.Spec.ConsumesConfigMapName).To(Equal("migrate-env"))
+			Expect(job.Spec.Delegates).To(HaveLen(1))
+			Expect(job.Spec.Delegates[0].Name).To(Equal("shared"))
+		})
+	})
+
+	Context("When creating Job under Validating Webhook", func() {
+		It("Should deny if a required field is empty", func() {
+			job := newJob()
+			job.Spec.Consumes[0].Name = ""
+			job.Spec.Consumes[0].Maps = nil
+			Expect(new(Job).Default(context.Background(), job)).To(Succeed())
+			_, err := new(Job).ValidateCreate(context.Background(), job)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].maps"))
+		})
+
+		It("Should admit if all required fields are provided", func() {
+			job := newJob()
+			Expect(new(Job).Default(context.Background(), job)).To(Succeed())
+			_, err := new(Job).ValidateCreate(context.Background(), job)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = new(Job).ValidateUpdate(context.Background(), job, job)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When admitting Job through the API server", func() {
+		newAdmittedJob := func(name string) *Job {
+			job := newJob()
+			job.Name = name
+			job.Spec.Template = corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers:    []corev1.Container{{Name: "migrate", Image: "migrate"}},
+			}}
+			return job
+		}
+
+		It("Should default the Job being created", func() {
+			job := newAdmittedJob("migrate-admitted")
+			Expect(k8sClient.Create(ctx, job)).To(Succeed())
+			Expect(job.Spec.ConsumesConfigMapName).To(Equal("migrate-admitted-consumed"))
+			Expect(job.Spec.ProducesSecretName).To(Equal("migrate-admitted-produced"))
+			Expect(k8sClient.Delete(ctx, job)).To(Succeed())
+		})
+
+		It("Should deny the Job being created with an invalid spec", func() {
+			job := newAdmittedJob("migrate-denied")
+			job.Spec.Consumes[0].Maps = nil
+			Expect(k8sClient.Create(ctx, job)).To(MatchError(ContainSubstring("spec.consumes[0].maps")))
+		})
+	})
+})
@@ -36,13 +36,36 @@ type StatefulSetSpec struct {
 type StatefulSetStatus struct {
 	// Tensegrity status.
 	v1alpha1.TensegrityStatus `json:",inline"`
+	// Replicas is a number of pods of the child StatefulSet.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// ReadyReplicas is a number of ready pods of the child StatefulSet.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// CurrentReplicas is a number of pods of the child StatefulSet with the current revision.
+	// +optional
+	CurrentReplicas int32 `json:"currentReplicas,omitempty"`
+	// UpdatedReplicas is a number of pods of the child StatefulSet with the update revision.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+	// AvailableReplicas is a number of available pods of the child StatefulSet.
+	// +optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+	// WorkloadConditions are conditions of the child StatefulSet.
+	// +optional
+	WorkloadConditions []appsv1.StatefulSetCondition `json:"workloadConditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.status.replicas`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Up-to-date",type=integer,JSONPath=`.status.updatedReplicas`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
 // +kubebuilder:printcolumn:name="Produced",type=string,JSONPath=`.status.produced`
 // +kubebuilder:printcolumn:name="Produced Config Map",type=string,JSONPath=`.status.producedConfigMapName`
 // +kubebuilder:printcolumn:name="Produced Secret",type=string,JSONPath=`.status.producedSecretName`
+// +kubebuilder:printcolumn:name="Consumers",type=integer,JSONPath=`.status.consumerCount`,priority=1
 // +kubebuilder:printcolumn:name="Consumed",type=string,JSONPath=`.status.consumed`
 // +kubebuilder:printcolumn:name="Consumed Config Map",type=string,JSONPath=`.status.consumedConfigMapName`
 // +kubebuilder:printcolumn:name="Consumed Secret",type=string,JSONPath=`.status.consumedSecretName`
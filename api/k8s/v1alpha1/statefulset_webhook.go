@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,8 @@ import (
 func (r *StatefulSet) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&StatefulSet{}).
+		WithValidator(&StatefulSet{}).
 		Complete()
 }
 
@@ -41,13 +44,17 @@ func (r *StatefulSet) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.CustomDefaulter = &StatefulSet{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
-func (r *StatefulSet) Default(_ context.Context, _ runtime.Object) error {
-	r.Spec.TensegritySpec.SetDefaultProducesName(r.GetName())
-	r.Spec.TensegritySpec.SetDefaultNamespaceDelegate(r.GetNamespace())
-	r.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(r.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultConsumesSecretName(r.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesConfigMapName(r.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesSecretName(r.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
+func (r *StatefulSet) Default(_ context.Context, obj runtime.Object) error {
+	statefulSet, ok := obj.(*StatefulSet)
+	if !ok {
+		return fmt.Errorf("expected a StatefulSet but got a %T", obj)
+	}
+	statefulSet.Spec.TensegritySpec.SetDefaultProducesName(statefulSet.GetName())
+	statefulSet.Spec.TensegritySpec.SetDefaultNamespaceDelegate(statefulSet.GetNamespace())
+	statefulSet.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(statefulSet.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
+	statefulSet.Spec.TensegritySpec.SetDefaultConsumesSecretName(statefulSet.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
+	statefulSet.Spec.TensegritySpec.SetDefaultProducesConfigMapName(statefulSet.GetName() + v1alpha1.DefaultProducesConfigMapNamePrefix)
+	statefulSet.Spec.TensegritySpec.SetDefaultProducesSecretName(statefulSet.GetName() + v1alpha1.DefaultProducesSecretNamePrefix)
 	return nil
 }
 
@@ -56,25 +63,28 @@ func (r *StatefulSet) Default(_ context.Context, _ runtime.Object) error {
 var _ webhook.CustomValidator = &StatefulSet{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *StatefulSet) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *StatefulSet) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateStatefulSet(obj)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *StatefulSet) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *StatefulSet) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateStatefulSet(newObj)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *StatefulSet) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
+func (r *StatefulSet) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateStatefulSet(obj)
+}
+
+// validateStatefulSet validates the Tensegrity spec of a StatefulSet being admitted.
+func validateStatefulSet(obj runtime.Object) error {
+	statefulSet, ok := obj.(*StatefulSet)
+	if !ok {
+		return fmt.Errorf("expected a StatefulSet but got a %T", obj)
+	}
+	if errs := statefulSet.Spec.TensegritySpec.Validate(); len(errs) > 0 {
+		return apierrors.NewInvalid(statefulSet.GetObjectKind().GroupVersionKind().GroupKind(), statefulSet.GetName(), errs)
 	}
-	return nil, nil
+	return nil
 }
@@ -18,31 +18,113 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
 )
 
 var _ = Describe("StatefulSet Webhook", func() {
+	newStatefulSet := func() *StatefulSet {
+		return &StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec: StatefulSetSpec{
+				TensegritySpec: v1alpha1.TensegritySpec{
+					Consumes: []v1alpha1.ConsumesSpec{
+						{ObjectReference: corev1.ObjectReference{
+							APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "postgres"},
+							Maps: map[string]string{"POSTGRES_HOST": "host"}},
+					},
+					Produces: []v1alpha1.ProducesSpec{
+						{Key: "serviceName", ProducesSourceSpec: v1alpha1.ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: "apps/v1", Kind: "StatefulSet", FieldPath: "{ .spec.serviceName }"}}},
+					},
+				},
+			},
+		}
+	}
 
 	Context("When creating StatefulSet under Defaulting Webhook", func() {
 		It("Should fill in the default value if a required field is empty", func() {
+			statefulSet := newStatefulSet()
+			Expect(new(StatefulSet).Default(context.Background(), statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.Produces[0].Name).To(Equal("postgres"))
+			Expect(statefulSet.Spec.Delegates).To(ConsistOf(v1alpha1.DelegateSpec{
+				ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "default"}}))
+			Expect(statefulSet.Spec.ConsumesConfigMapName).To(Equal("postgres-consumed"))
+			Expect(statefulSet.Spec.ConsumesSecretName).To(Equal("postgres-consumed"))
+			Expect(statefulSet.Spec.ProducesConfigMapName).To(Equal("postgres-produced"))
+			Expect(statefulSet.Spec.ProducesSecretName).To(Equal("postgres-produced"))
+		})
 
-			// TODO(user): Add your logic here
-
+		It("Should keep values which are set", func() {
+			statefulSet := newStatefulSet()
+			statefulSet.Spec.ConsumesConfigMapName = "postgres-env"
+			statefulSet.Spec.Delegates = []v1alpha1.DelegateSpec{
+				{ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "shared"}}}
+			Expect(new(StatefulSet).Default(context.Background(), statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.ConsumesConfigMapName).To(Equal("postgres-env"))
+			Expect(statefulSet.Spec.Delegates).To(HaveLen(1))
+			Expect(statefulSet.Spec.Delegates[0].Name).To(Equal("shared"))
 		})
 	})
 
 	Context("When creating StatefulSet under Validating Webhook", func() {
 		It("Should deny if a required field is empty", func() {
-
-			// TODO(user): Add your logic here
-
+			statefulSet := newStatefulSet()
+			statefulSet.Spec.Consumes[0].Name = ""
+			statefulSet.Spec.Consumes[0].Maps = nil
+			Expect(new(StatefulSet).Default(context.Background(), statefulSet)).To(Succeed())
+			_, err := new(StatefulSet).ValidateCreate(context.Background(), statefulSet)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.consumes[0].maps"))
 		})
 
 		It("Should admit if all required fields are provided", func() {
+			statefulSet := newStatefulSet()
+			Expect(new(StatefulSet).Default(context.Background(), statefulSet)).To(Succeed())
+			_, err := new(StatefulSet).ValidateCreate(context.Background(), statefulSet)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = new(StatefulSet).ValidateUpdate(context.Background(), statefulSet, statefulSet)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 
-			// TODO(user): Add your logic here
+	Context("When admitting StatefulSet through the API server", func() {
+		newAdmittedStatefulSet := func(name string) *StatefulSet {
+			statefulSet := newStatefulSet()
+			statefulSet.Name = name
+			labels := map[string]string{"app": name}
+			statefulSet.Spec.StatefulSetSpec = appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "postgres", Image: "postgres"}}},
+				},
+			}
+			return statefulSet
+		}
+
+		It("Should default the StatefulSet being created", func() {
+			statefulSet := newAdmittedStatefulSet("postgres-admitted")
+			Expect(k8sClient.Create(ctx, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.ConsumesConfigMapName).To(Equal("postgres-admitted-consumed"))
+			Expect(statefulSet.Spec.ProducesSecretName).To(Equal("postgres-admitted-produced"))
+			Expect(k8sClient.Delete(ctx, statefulSet)).To(Succeed())
+		})
 
+		It("Should deny the StatefulSet being created with an invalid spec", func() {
+			statefulSet := newAdmittedStatefulSet("postgres-denied")
+			statefulSet.Spec.Consumes[0].Maps = nil
+			Expect(k8sClient.Create(ctx, statefulSet)).To(MatchError(ContainSubstring("spec.consumes[0].maps")))
 		})
 	})
-
 })
@@ -0,0 +1,84 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	apiv1beta1 "github.com/fastforgeinc/tensegrity/api/v1beta1"
+)
+
+var _ = Describe("Deployment conversion", func() {
+	newHub := func() *v1alpha1.Deployment {
+		return &v1alpha1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: v1alpha1.DeploymentSpec{
+				DeploymentSpec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](2),
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "api", Image: "api:latest"}},
+					}},
+				},
+				TensegritySpec: apiv1alpha1.TensegritySpec{
+					Consumes: []apiv1alpha1.ConsumesSpec{{
+						ObjectReference: corev1.ObjectReference{
+							APIVersion: "k8s.tensegrity.fastforge.io/v1alpha1", Kind: "StatefulSet", Name: "postgres"},
+						Maps: map[string]string{"DATABASE_HOST": "host"},
+					}},
+					Produces: []apiv1alpha1.ProducesSpec{{
+						Key: "port",
+						ProducesSourceSpec: apiv1alpha1.ProducesSourceSpec{ObjectReference: corev1.ObjectReference{
+							APIVersion: "k8s.tensegrity.fastforge.io/v1alpha1", Kind: "Deployment", Name: "api",
+							FieldPath: "8080"}},
+					}},
+				},
+			},
+			Status: v1alpha1.DeploymentStatus{
+				TensegrityStatus: apiv1alpha1.TensegrityStatus{Consumed: ptr.To(apiv1alpha1.ConsumedSuccess)},
+				Replicas:         2,
+				ReadyReplicas:    1,
+				WorkloadConditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+				},
+			},
+		}
+	}
+
+	It("should round-trip v1alpha1 without loss", func() {
+		hub := newHub()
+		spoke := new(Deployment)
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Spec.Replicas).To(Equal(ptr.To[int32](2)))
+		Expect(spoke.Spec.Consumes[0].Envs).To(HaveKeyWithValue("DATABASE_HOST", apiv1beta1.ConsumedEnvSpec{Key: "host"}))
+		Expect(spoke.Spec.Produces[0].Value).To(Equal("8080"))
+		Expect(spoke.Status.ReadyReplicas).To(Equal(int32(1)))
+		Expect(spoke.Status.Consumed).To(Equal(ptr.To(apiv1beta1.ConsumedSuccess)))
+
+		restored := new(v1alpha1.Deployment)
+		Expect(spoke.ConvertTo(restored)).To(Succeed())
+		Expect(restored).To(Equal(hub))
+	})
+})
@@ -0,0 +1,63 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apiv1beta1 "github.com/fastforgeinc/tensegrity/api/v1beta1"
+)
+
+var _ conversion.Convertible = &DaemonSet{}
+
+// ConvertTo converts this DaemonSet to the Hub version (v1alpha1).
+func (src *DaemonSet) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.DaemonSet)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	src.Spec.DaemonSetSpec.DeepCopyInto(&dst.Spec.DaemonSetSpec)
+	if err := apiv1beta1.ConvertSpecToHub(&src.Spec.TensegritySpec, &dst.Spec.TensegritySpec,
+		hubReference("DaemonSet", src.Name), &dst.ObjectMeta); err != nil {
+		return err
+	}
+	dst.Status.DesiredNumberScheduled = src.Status.DesiredNumberScheduled
+	dst.Status.CurrentNumberScheduled = src.Status.CurrentNumberScheduled
+	dst.Status.NumberReady = src.Status.NumberReady
+	dst.Status.UpdatedNumberScheduled = src.Status.UpdatedNumberScheduled
+	dst.Status.NumberAvailable = src.Status.NumberAvailable
+	dst.Status.WorkloadConditions = src.Status.WorkloadConditions
+	return apiv1beta1.ConvertStatusToHub(&src.Status.TensegrityStatus, &dst.Status.TensegrityStatus)
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version.
+func (dst *DaemonSet) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.DaemonSet)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	src.Spec.DaemonSetSpec.DeepCopyInto(&dst.Spec.DaemonSetSpec)
+	if err := apiv1beta1.ConvertSpecFromHub(&src.Spec.TensegritySpec, &dst.Spec.TensegritySpec,
+		hubReference("DaemonSet", src.Name), &dst.ObjectMeta); err != nil {
+		return err
+	}
+	dst.Status.DesiredNumberScheduled = src.Status.DesiredNumberScheduled
+	dst.Status.CurrentNumberScheduled = src.Status.CurrentNumberScheduled
+	dst.Status.NumberReady = src.Status.NumberReady
+	dst.Status.UpdatedNumberScheduled = src.Status.UpdatedNumberScheduled
+	dst.Status.NumberAvailable = src.Status.NumberAvailable
+	dst.Status.WorkloadConditions = src.Status.WorkloadConditions
+	return apiv1beta1.ConvertStatusFromHub(&src.Status.TensegrityStatus, &dst.Status.TensegrityStatus)
+}
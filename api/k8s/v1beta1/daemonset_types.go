@@ -0,0 +1,95 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1beta1"
+)
+
+// DaemonSetSpec defines the desired state of DaemonSet.
+type DaemonSetSpec struct {
+	// DaemonSetSpec is k8s.io/api/apps/v1.DaemonSetSpec type.
+	appsv1.DaemonSetSpec `json:",inline"`
+	// TensegritySpec defines which keys a workload consumes and/or produces, and its delegates.
+	v1beta1.TensegritySpec `json:",inline"`
+}
+
+// DaemonSetStatus defines the observed state of DaemonSet
+type DaemonSetStatus struct {
+	// Tensegrity status.
+	v1beta1.TensegrityStatus `json:",inline"`
+	// DesiredNumberScheduled is a number of nodes which should run a pod of the child DaemonSet.
+	// +optional
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled,omitempty"`
+	// CurrentNumberScheduled is a number of nodes running a pod of the child DaemonSet.
+	// +optional
+	CurrentNumberScheduled int32 `json:"currentNumberScheduled,omitempty"`
+	// NumberReady is a number of nodes running a ready pod of the child DaemonSet.
+	// +optional
+	NumberReady int32 `json:"numberReady,omitempty"`
+	// UpdatedNumberScheduled is a number of nodes running an updated pod of the child DaemonSet.
+	// +optional
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled,omitempty"`
+	// NumberAvailable is a number of nodes running an available pod of the child DaemonSet.
+	// +optional
+	NumberAvailable int32 `json:"numberAvailable,omitempty"`
+	// WorkloadConditions are conditions of the child DaemonSet.
+	// +optional
+	WorkloadConditions []appsv1.DaemonSetCondition `json:"workloadConditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredNumberScheduled`
+// +kubebuilder:printcolumn:name="Current",type=integer,JSONPath=`.status.currentNumberScheduled`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.numberReady`
+// +kubebuilder:printcolumn:name="Up-to-date",type=integer,JSONPath=`.status.updatedNumberScheduled`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.numberAvailable`
+// +kubebuilder:printcolumn:name="Produced",type=string,JSONPath=`.status.produced`
+// +kubebuilder:printcolumn:name="Produced Config Map",type=string,JSONPath=`.status.producedConfigMapName`
+// +kubebuilder:printcolumn:name="Produced Secret",type=string,JSONPath=`.status.producedSecretName`
+// +kubebuilder:printcolumn:name="Consumers",type=integer,JSONPath=`.status.consumerCount`,priority=1
+// +kubebuilder:printcolumn:name="Consumed",type=string,JSONPath=`.status.consumed`
+// +kubebuilder:printcolumn:name="Consumed Config Map",type=string,JSONPath=`.status.consumedConfigMapName`
+// +kubebuilder:printcolumn:name="Consumed Secret",type=string,JSONPath=`.status.consumedSecretName`
+
+// DaemonSet is a wrapper type of the k8s.io/api/apps/v1.DaemonSet type.
+type DaemonSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DaemonSetSpec   `json:"spec"`
+	Status DaemonSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DaemonSetList contains a list of DaemonSet.
+type DaemonSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DaemonSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DaemonSet{}, &DaemonSetList{})
+}
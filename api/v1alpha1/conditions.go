@@ -39,6 +39,22 @@ const (
 	KeysConsumedReason = "KeyConsumed"
 	// KeysConsumedMessage is added in Tensegrity resource when keys are fully consumed.
 	KeysConsumedMessage = "All keys are consumed."
+	// KeysStaleReason is added in Tensegrity resource when last known good values are retained for keys.
+	KeysStaleReason = "KeysStale"
+	// KeysStaleMessage is added in Tensegrity resource when last known good values are retained for keys.
+	KeysStaleMessage = "Keys are stale since %s for envs: %s."
+	// ReadyReason is added in Tensegrity resource when it is ready.
+	ReadyReason = "Ready"
+	// ReadyMessage is added in Tensegrity resource when it is ready.
+	ReadyMessage = "Resource is ready."
+	// ReconcileFailedReason is added in Tensegrity resource when reconciliation is failed and is retried.
+	ReconcileFailedReason = "ReconcileFailed"
+	// ReconcileFailedMessage is added in Tensegrity resource when reconciliation is failed and is retried.
+	ReconcileFailedMessage = "Reconciliation is failed: %s."
+	// RolloutInProgressReason is added in Tensegrity resource when a child workload is not rolled out yet.
+	RolloutInProgressReason = "RolloutInProgress"
+	// RolloutInProgressMessage is added in Tensegrity resource when a child workload is not rolled out yet.
+	RolloutInProgressMessage = "Workload rollout is in progress: %s."
 	// SpecInvalidReason is added in Tensegrity resource when spec is invalid.
 	SpecInvalidReason = "SpecInvalid"
 	// SpecInvalidMessage is added in Tensegrity resource when spec is invalid.
@@ -55,6 +71,14 @@ const (
 	TensegrityProduced TensegrityConditionType = "Produced"
 	// TensegrityInvalid means spec is not valid.
 	TensegrityInvalid TensegrityConditionType = "Invalid"
+	// TensegrityDegraded means last known good values are retained for keys which are not consumed.
+	TensegrityDegraded TensegrityConditionType = "Degraded"
+	// TensegrityReady means spec is valid, keys are consumed and produced, and a child workload is rolled out.
+	TensegrityReady TensegrityConditionType = "Ready"
+	// TensegrityReconciling means the resource is not ready yet and is expected to become ready, present only when true.
+	TensegrityReconciling TensegrityConditionType = "Reconciling"
+	// TensegrityStalled means the resource can't become ready without a spec change, present only when true.
+	TensegrityStalled TensegrityConditionType = "Stalled"
 )
 
 type TensegrityCondition struct {
@@ -23,3 +23,31 @@ const (
 	DefaultProducesSecretNamePrefix    = "-produced"
 	DefaultProducesConfigMapNamePrefix = "-produced"
 )
+
+// MaxConsumers limits consumers listed by TensegrityStatus, so the status of a widely consumed resource stays small.
+const MaxConsumers = 100
+
+const (
+	// SpecAnnotation holds TensegritySpec in JSON or YAML on a native workload resource,
+	// only delegates and consumes are allowed.
+	SpecAnnotation = "tensegrity.fastforge.io/spec"
+	// InjectedAnnotation lists ConfigMaps and Secrets injected into a native workload resource.
+	InjectedAnnotation = "tensegrity.fastforge.io/injected"
+	// StaticLabel binds a Pod to a Static by name, consumed Secret and ConfigMap of the Static
+	// are injected into the Pod at creation time. It is looked up as a label or annotation
+	// on the Pod first and then on its controller owners.
+	StaticLabel = "tensegrity.fastforge.io/static"
+	// InjectionLabel enables the Pod webhook in a Namespace labeled with the "enabled" value,
+	// Pods of other namespaces are not sent to the webhook.
+	InjectionLabel = "tensegrity.fastforge.io/injection"
+	// PriorityLabel orders namespaces selected by NamespaceSelector delegate, it is looked up
+	// as a label or annotation on a Namespace, holds an integer and defaults to 0.
+	PriorityLabel = "tensegrity.fastforge.io/priority"
+	// ChangedAnnotation holds a time in RFC 3339 format produced keys of a ConfigMap or a Secret are changed last time,
+	// it is missing until keys are changed after creation.
+	ChangedAnnotation = "tensegrity.fastforge.io/changed"
+	// KubeconfigSecretKey is a key of a Secret referenced by Cluster delegate holding a kubeconfig.
+	KubeconfigSecretKey = "kubeconfig"
+	// SealedValuePrefix starts a value of StaticSpec.SealedData, followed by an ID of the key it is sealed with.
+	SealedValuePrefix = "sealed:v1:"
+)
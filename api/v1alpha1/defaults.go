@@ -47,17 +47,24 @@ func (s *TensegritySpec) SetDefaultProducesConfigMapName(name string) {
 
 func (s *TensegritySpec) SetDefaultProducesName(name string) {
 	for i, produce := range s.Produces {
-		if len(produce.Name) == 0 {
+		if len(produce.Name) == 0 && produce.Selector == nil {
 			s.Produces[i].Name = name
 		}
+		for j, fallback := range produce.Fallbacks {
+			if len(fallback.Name) == 0 && fallback.Selector == nil {
+				s.Produces[i].Fallbacks[j].Name = name
+			}
+		}
 	}
 }
 
 func (s *TensegritySpec) SetDefaultNamespaceDelegate(namespace string) {
 	if len(s.Delegates) == 0 {
-		s.Delegates = append(s.Delegates, v1.ObjectReference{
-			Kind: "Namespace",
-			Name: namespace,
+		s.Delegates = append(s.Delegates, DelegateSpec{
+			ObjectReference: v1.ObjectReference{
+				Kind: "Namespace",
+				Name: namespace,
+			},
 		})
 	}
 }
@@ -0,0 +1,145 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"github.com/pkg/errors"
+	"k8s.io/utils/lru"
+)
+
+const (
+	// ExpressionCostLimit limits the runtime cost of a single expression evaluation.
+	ExpressionCostLimit = 1000000
+	// ExpressionCacheSize limits the number of compiled expressions kept in memory,
+	// least recently used ones are compiled again when needed.
+	ExpressionCacheSize = 1024
+)
+
+var (
+	expressionEnv     *cel.Env
+	expressionEnvErr  error
+	expressionEnvOnce sync.Once
+	expressionCache   = lru.New(ExpressionCacheSize)
+)
+
+func getExpressionEnv() (*cel.Env, error) {
+	expressionEnvOnce.Do(func() {
+		expressionEnv, expressionEnvErr = cel.NewEnv(
+			cel.Variable("self", cel.DynType),
+			ext.Strings(),
+			ext.Encoders(),
+		)
+	})
+	return expressionEnv, expressionEnvErr
+}
+
+type compiledExpression struct {
+	ast     *cel.Ast
+	program cel.Program
+}
+
+// compileExpression parses and type-checks a CEL expression, compiled programs are cached by the expression source.
+func compileExpression(expression, result string, outputs ...*cel.Type) (cel.Program, error) {
+	compiled, ok := expressionCache.Get(expression)
+	if !ok {
+		env, err := getExpressionEnv()
+		if err != nil {
+			return nil, err
+		}
+		ast, issues := env.Compile(expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, issues.Err()
+		}
+		program, err := env.Program(ast, cel.CostLimit(ExpressionCostLimit))
+		if err != nil {
+			return nil, err
+		}
+		compiled = compiledExpression{ast: ast, program: program}
+		expressionCache.Add(expression, compiled)
+	}
+	c := compiled.(compiledExpression)
+	for _, output := range outputs {
+		if c.ast.OutputType().IsExactType(output) {
+			return c.program, nil
+		}
+	}
+	return nil, fmt.Errorf("expression must evaluate to %s, got %s", result, c.ast.OutputType())
+}
+
+// CompileExpression parses and type-checks a CEL expression of ProducesSourceSpec.
+func CompileExpression(expression string) (cel.Program, error) {
+	return compileExpression(expression, "a scalar value",
+		cel.StringType, cel.IntType, cel.UintType, cel.DoubleType, cel.BoolType, cel.DynType)
+}
+
+// CompileReadyExpression parses and type-checks a CEL expression of ReadySpec.
+func CompileReadyExpression(expression string) (cel.Program, error) {
+	return compileExpression(expression, "a bool", cel.BoolType, cel.DynType)
+}
+
+// EvalReadyExpression evaluates a CEL expression of ReadySpec against an object.
+func EvalReadyExpression(expression string, object map[string]interface{}) (bool, error) {
+	program, err := CompileReadyExpression(expression)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := program.Eval(map[string]interface{}{"self": object})
+	if err != nil {
+		return false, err
+	}
+	ready, ok := out.(types.Bool)
+	if !ok {
+		return false, errors.Errorf("unsupported expression result type %s", out.Type().TypeName())
+	}
+	return bool(ready), nil
+}
+
+// EvalExpression evaluates a CEL expression against an object and formats the result as a key value.
+func EvalExpression(expression string, object map[string]interface{}) (string, error) {
+	program, err := CompileExpression(expression)
+	if err != nil {
+		return "", err
+	}
+	out, _, err := program.Eval(map[string]interface{}{"self": object})
+	if err != nil {
+		return "", err
+	}
+	switch v := out.(type) {
+	case types.String:
+		return string(v), nil
+	case types.Int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case types.Uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case types.Double:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64), nil
+	case types.Bool:
+		return strconv.FormatBool(bool(v)), nil
+	case types.Null:
+		return "", nil
+	default:
+		return "", errors.Errorf("unsupported expression result type %s", out.Type().TypeName())
+	}
+}
@@ -0,0 +1,95 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Expressions", func() {
+	object := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "postgres"},
+		"spec": map[string]interface{}{
+			"host":     "postgres.testing",
+			"port":     int64(5432),
+			"replicas": 1.5,
+			"ssl":      true,
+			"ports":    []interface{}{int64(5432), int64(5433)},
+		},
+		"status": map[string]interface{}{"ready": false},
+	}
+
+	Context("When evaluating expressions of produced keys", func() {
+		It("Should format scalar values", func() {
+			for expression, value := range map[string]string{
+				`self.spec.host`:       "postgres.testing",
+				`self.spec.port`:       "5432",
+				`uint(self.spec.port)`: "5432",
+				`self.spec.replicas`:   "1.5",
+				`self.spec.ssl`:        "true",
+				`"postgres://" + self.spec.host + ":" + string(self.spec.port)`: "postgres://postgres.testing:5432",
+				`has(self.spec.user) ? self.spec.user : "admin"`:                "admin",
+				`self.spec.host.upperAscii()`:                                   "POSTGRES.TESTING",
+			} {
+				Expect(EvalExpression(expression, object)).To(Equal(value), expression)
+			}
+		})
+
+		It("Should deny expressions which do not evaluate to a scalar value", func() {
+			_, err := CompileExpression(`[1, 2]`)
+			Expect(err).To(MatchError(ContainSubstring("a scalar value")))
+			_, err = CompileExpression(`self.spec.host +`)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should fail values of unsupported types", func() {
+			_, err := EvalExpression(`self.spec.ports`, object)
+			Expect(err).To(MatchError(ContainSubstring("unsupported expression result type")))
+			_, err = EvalExpression(`self.spec.user`, object)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When evaluating ready expressions", func() {
+		It("Should evaluate conditions", func() {
+			Expect(EvalReadyExpression(`self.status.ready`, object)).To(BeFalse())
+			Expect(EvalReadyExpression(`self.spec.ssl && self.spec.port > 0`, object)).To(BeTrue())
+		})
+
+		It("Should deny expressions which do not evaluate to a bool", func() {
+			_, err := CompileReadyExpression(`self.spec.host + "."`)
+			Expect(err).To(MatchError(ContainSubstring("a bool")))
+			_, err = EvalReadyExpression(`self.spec.host`, object)
+			Expect(err).To(MatchError(ContainSubstring("unsupported expression result type")))
+		})
+	})
+
+	Context("When caching compiled expressions", func() {
+		It("Should keep a bounded number of programs", func() {
+			for i := 0; i < ExpressionCacheSize+10; i++ {
+				_, err := CompileExpression(fmt.Sprintf(`self.spec.port + %d`, i))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(expressionCache.Len()).To(Equal(ExpressionCacheSize))
+			Expect(EvalExpression(`self.spec.port + 0`, object)).To(Equal("5432"))
+		})
+	})
+})
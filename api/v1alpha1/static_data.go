@@ -0,0 +1,112 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// LiteralValue returns a value of the key when its only source is a literal, a fieldPath without JSONPath
+// expressions or a quoted string expression evaluated against self, the resource producing the key.
+func (p *ProducesSpec) LiteralValue(self corev1.ObjectReference) (string, bool) {
+	literal := ProducesSourceSpec{ObjectReference: self}
+	literal.FieldPath = p.FieldPath
+	literal.Expression = p.Expression
+	if len(p.Fallbacks) > 0 || len(self.Name) == 0 || !equality.Semantic.DeepEqual(p.ProducesSourceSpec, literal) {
+		return "", false
+	}
+	switch {
+	case len(p.FieldPath) > 0 && len(p.Expression) == 0 && !strings.Contains(p.FieldPath, "{"):
+		return p.FieldPath, true
+	case len(p.FieldPath) == 0 && len(p.Expression) > 0:
+		value, err := strconv.Unquote(p.Expression)
+		if err == nil && strconv.Quote(value) == p.Expression {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// LiteralKeys returns keys produced from literals of the Static itself, which are produced directly
+// without looking the Static up, and are better kept in Data and SensitiveData.
+func (s *StaticSpec) LiteralKeys(name string) []string {
+	self := corev1.ObjectReference{APIVersion: GroupVersion.String(), Kind: "Static", Name: name}
+	var keys []string
+	for _, p := range s.Produces {
+		if _, ok := p.LiteralValue(self); ok {
+			keys = append(keys, p.Key)
+		}
+	}
+	return keys
+}
+
+// Validate validates the Tensegrity spec and keys produced from Data, SensitiveData and SealedData.
+func (s *StaticSpec) Validate() (allErrs field.ErrorList) {
+	allErrs = append(allErrs, s.TensegritySpec.Validate()...)
+	allErrs = append(allErrs, s.ValidateData()...)
+	return allErrs
+}
+
+// ValidateData validates keys of Data, SensitiveData and SealedData, which must be valid ConfigMap keys
+// and must not be produced twice, and values of SealedData, which must be sealed.
+func (s *StaticSpec) ValidateData() (errs field.ErrorList) {
+	seenKeys := make(map[string]struct{}, len(s.Data)+len(s.SensitiveData)+len(s.SealedData))
+	for _, data := range []struct {
+		name   string
+		values map[string]string
+	}{{"data", s.Data}, {"sensitiveData", s.SensitiveData}, {"sealedData", s.SealedData}} {
+		for _, key := range sortedKeys(data.values) {
+			path := field.NewPath("spec").Child(data.name).Key(key)
+			for _, msg := range validation.IsConfigMapKey(key) {
+				errs = append(errs, field.Invalid(path, key, msg))
+			}
+			if _, ok := seenKeys[key]; ok {
+				errs = append(errs, field.Duplicate(path, key))
+			}
+			seenKeys[key] = struct{}{}
+		}
+	}
+	for _, key := range sortedKeys(s.SealedData) {
+		if !strings.HasPrefix(s.SealedData[key], SealedValuePrefix) {
+			errs = append(errs, field.Invalid(field.NewPath("spec").Child("sealedData").Key(key),
+				"<sealed>", "value must be sealed with tensegrity-seal"))
+		}
+	}
+	for i, p := range s.Produces {
+		if _, ok := seenKeys[p.Key]; ok {
+			errs = append(errs, field.Duplicate(field.NewPath("spec").Child("produces").Index(i).Child("key"), p.Key))
+		}
+	}
+	return errs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -25,6 +25,17 @@ import (
 type StaticSpec struct {
 	// TensegritySpec defines which keys a workload consumes and/or produces, and its delegates.
 	TensegritySpec `json:",inline"`
+	// Data is a map of keys produced with literal values into the produced ConfigMap.
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+	// SensitiveData is a map of keys produced with literal values into the produced Secret,
+	// values are plain strings which are encoded by Tensegrity controller.
+	// +optional
+	SensitiveData map[string]string `json:"sensitiveData,omitempty"`
+	// SealedData is a map of keys produced into the produced Secret with values sealed by tensegrity-seal
+	// with a public key of Tensegrity controller, they are opened only by the controller.
+	// +optional
+	SealedData map[string]string `json:"sealedData,omitempty"`
 }
 
 // StaticStatus defines the observed state of Static
@@ -38,6 +49,7 @@ type StaticStatus struct {
 // +kubebuilder:printcolumn:name="Produced",type=string,JSONPath=`.status.produced`
 // +kubebuilder:printcolumn:name="Produced Config Map",type=string,JSONPath=`.status.producedConfigMapName`
 // +kubebuilder:printcolumn:name="Produced Secret",type=string,JSONPath=`.status.producedSecretName`
+// +kubebuilder:printcolumn:name="Consumers",type=integer,JSONPath=`.status.consumerCount`,priority=1
 // +kubebuilder:printcolumn:name="Consumed",type=string,JSONPath=`.status.consumed`
 // +kubebuilder:printcolumn:name="Consumed Config Map",type=string,JSONPath=`.status.consumedConfigMapName`
 // +kubebuilder:printcolumn:name="Consumed Secret",type=string,JSONPath=`.status.consumedSecretName`
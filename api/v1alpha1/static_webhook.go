@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,6 +32,8 @@ import (
 func (r *Static) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&Static{}).
+		WithValidator(&Static{}).
 		Complete()
 }
 
@@ -39,11 +42,17 @@ func (r *Static) SetupWebhookWithManager(mgr ctrl.Manager) error {
 var _ webhook.CustomDefaulter = &Static{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
-func (r *Static) Default(_ context.Context, _ runtime.Object) error {
-	r.Spec.TensegritySpec.SetDefaultProducesName(r.GetName())
-	r.Spec.TensegritySpec.SetDefaultNamespaceDelegate(r.GetNamespace())
-	r.Spec.TensegritySpec.SetDefaultProducesConfigMapName(r.GetName() + DefaultProducesConfigMapNamePrefix)
-	r.Spec.TensegritySpec.SetDefaultProducesSecretName(r.GetName() + DefaultProducesSecretNamePrefix)
+func (r *Static) Default(_ context.Context, obj runtime.Object) error {
+	static, ok := obj.(*Static)
+	if !ok {
+		return fmt.Errorf("expected a Static but got a %T", obj)
+	}
+	static.Spec.TensegritySpec.SetDefaultProducesName(static.GetName())
+	static.Spec.TensegritySpec.SetDefaultNamespaceDelegate(static.GetNamespace())
+	static.Spec.TensegritySpec.SetDefaultConsumesConfigMapName(static.GetName() + DefaultConsumesConfigMapNamePrefix)
+	static.Spec.TensegritySpec.SetDefaultConsumesSecretName(static.GetName() + DefaultConsumesSecretNamePrefix)
+	static.Spec.TensegritySpec.SetDefaultProducesConfigMapName(static.GetName() + DefaultProducesConfigMapNamePrefix)
+	static.Spec.TensegritySpec.SetDefaultProducesSecretName(static.GetName() + DefaultProducesSecretNamePrefix)
 	return nil
 }
 
@@ -52,25 +61,41 @@ func (r *Static) Default(_ context.Context, _ runtime.Object) error {
 var _ webhook.CustomValidator = &Static{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *Static) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *Static) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return validateStatic(obj, true)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *Static) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
-	}
-	return nil, nil
+func (r *Static) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return validateStatic(newObj, true)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *Static) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
-	if errs := r.Spec.TensegritySpec.Validate(); len(errs) > 0 {
-		return nil, apierrors.NewInvalid(r.GetObjectKind().GroupVersionKind().GroupKind(), r.GetName(), errs)
+func (r *Static) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return validateStatic(obj, false)
+}
+
+// validateStatic validates the spec of a Static being admitted, and warns about its literal keys when asked to.
+func validateStatic(obj runtime.Object, warn bool) (admission.Warnings, error) {
+	static, ok := obj.(*Static)
+	if !ok {
+		return nil, fmt.Errorf("expected a Static but got a %T", obj)
+	}
+	if errs := static.Spec.Validate(); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(static.GetObjectKind().GroupVersionKind().GroupKind(), static.GetName(), errs)
+	}
+	if !warn {
+		return nil, nil
+	}
+	return static.warnLiteralKeys(), nil
+}
+
+// warnLiteralKeys warns about keys produced from literals of the Static itself, which are left as written
+// so the resource does not drift from its manifest, but are better kept in data and sensitiveData.
+func (r *Static) warnLiteralKeys() (warnings admission.Warnings) {
+	for _, key := range r.Spec.LiteralKeys(r.GetName()) {
+		warnings = append(warnings, fmt.Sprintf(
+			"spec.produces: key %q is a literal of the Static itself, move it into data or sensitiveData", key))
 	}
-	return nil, nil
+	return warnings
 }
@@ -18,31 +18,206 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 var _ = Describe("Static Webhook", func() {
+	self := corev1.ObjectReference{APIVersion: GroupVersion.String(), Kind: "Static", Name: "postgres"}
+
+	newStatic := func() *Static {
+		return &Static{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec: StaticSpec{
+				TensegritySpec: TensegritySpec{
+					Produces: []ProducesSpec{
+						{Key: "host", ProducesSourceSpec: ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: self.APIVersion, Kind: self.Kind, FieldPath: "postgres.testing"}}},
+						{Key: "password", Sensitive: true, Encoded: true, ProducesSourceSpec: ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: self.APIVersion, Kind: self.Kind, FieldPath: "UEAzM1cwUkQ="}}},
+						{Key: "user", Sensitive: true, ProducesSourceSpec: ProducesSourceSpec{
+							ObjectReference: corev1.ObjectReference{
+								APIVersion: "v1", Kind: "ConfigMap", Name: "postgres", FieldPath: "{ .data.USER }"}}},
+					},
+				},
+			},
+		}
+	}
 
 	Context("When creating Static under Defaulting Webhook", func() {
-		It("Should fill in the default value if a required field is empty", func() {
-
-			// TODO(user): Add your logic here
+		It("Should keep literal keys in produces as written", func() {
+			static := newStatic()
+			Expect(new(Static).Default(context.Background(), static)).To(Succeed())
+			Expect(static.Spec.Data).To(BeEmpty())
+			Expect(static.Spec.SensitiveData).To(BeEmpty())
+			Expect(static.Spec.Produces).To(HaveLen(3))
+			Expect(static.Spec.Produces[0].FieldPath).To(Equal("postgres.testing"))
+			Expect(static.Spec.Produces[1].FieldPath).To(Equal("UEAzM1cwUkQ="))
+			Expect(static.Spec.Produces[1].Encoded).To(BeTrue())
+		})
 
+		It("Should warn about literal keys", func() {
+			static := newStatic()
+			Expect(new(Static).Default(context.Background(), static)).To(Succeed())
+			warnings, err := new(Static).ValidateCreate(context.Background(), static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(2))
+			Expect(warnings[0]).To(ContainSubstring(`"host"`))
+			Expect(warnings[1]).To(ContainSubstring(`"password"`))
+			Expect(warnings[1]).NotTo(ContainSubstring("UEAzM1cwUkQ="))
 		})
 	})
 
 	Context("When creating Static under Validating Webhook", func() {
-		It("Should deny if a required field is empty", func() {
+		It("Should deny keys produced twice", func() {
+			static := newStatic()
+			static.Spec.Data = map[string]string{"user": "postgres"}
+			static.Spec.SensitiveData = map[string]string{"user": "postgres"}
+			Expect(new(Static).Default(context.Background(), static)).To(Succeed())
+			_, err := new(Static).ValidateCreate(context.Background(), static)
+			Expect(err).To(HaveOccurred())
+			Expect(static.Spec.ValidateData()).To(HaveLen(2))
+		})
 
-			// TODO(user): Add your logic here
+		It("Should deny invalid data keys", func() {
+			static := newStatic()
+			static.Spec.Data = map[string]string{"not a key": "value"}
+			Expect(static.Spec.ValidateData()).To(HaveLen(1))
+		})
 
+		It("Should deny values of sealed data which are not sealed", func() {
+			static := newStatic()
+			static.Spec.SealedData = map[string]string{
+				"token":  "t0k3n",
+				"secret": SealedValuePrefix + "0123456789abcdef:c2VjcmV0",
+			}
+			errs := static.Spec.ValidateData()
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.sealedData[token]"))
+			Expect(errs[0].Error()).NotTo(ContainSubstring("t0k3n"))
 		})
 
-		It("Should admit if all required fields are provided", func() {
+		It("Should admit data", func() {
+			static := newStatic()
+			Expect(new(Static).Default(context.Background(), static)).To(Succeed())
+			_, err := new(Static).ValidateCreate(context.Background(), static)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 
-			// TODO(user): Add your logic here
+	Context("When admitting Static through the API server", func() {
+		newAdmittedStatic := func(name string) *Static {
+			static := newStatic()
+			static.Name = name
+			return static
+		}
+
+		It("Should default the Static being created", func() {
+			static := newAdmittedStatic("postgres-admitted")
+			Expect(k8sClient.Create(ctx, static)).To(Succeed())
+			Expect(static.Spec.ConsumesConfigMapName).To(Equal("postgres-admitted-consumed"))
+			Expect(static.Spec.ProducesSecretName).To(Equal("postgres-admitted-produced"))
+			Expect(static.Spec.Delegates).To(HaveLen(1))
+			Expect(k8sClient.Delete(ctx, static)).To(Succeed())
+		})
 
+		It("Should deny the Static being created with keys produced twice", func() {
+			static := newAdmittedStatic("postgres-denied")
+			static.Spec.Data = map[string]string{"user": "postgres"}
+			Expect(k8sClient.Create(ctx, static)).To(MatchError(ContainSubstring("spec.produces[2].key")))
 		})
 	})
 
+	Context("When validating imports of consumed keys", func() {
+		consumes := func(name string, maps map[string]string, prefix *string) ConsumesSpec {
+			c := ConsumesSpec{
+				ObjectReference: corev1.ObjectReference{APIVersion: self.APIVersion, Kind: self.Kind, Name: name},
+				Maps:            maps,
+			}
+			if prefix != nil {
+				c.Import = &ImportSpec{Prefix: *prefix}
+			}
+			return c
+		}
+		prefix := func(prefix string) *string { return &prefix }
+
+		It("Should admit an empty prefix when no other objects are consumed", func() {
+			spec := TensegritySpec{Consumes: []ConsumesSpec{
+				consumes("postgres", map[string]string{"DATABASE_URL": "url"}, prefix("")),
+			}}
+			Expect(spec.Validate()).To(BeEmpty())
+		})
+
+		It("Should require a prefix when other objects are consumed", func() {
+			spec := TensegritySpec{Consumes: []ConsumesSpec{
+				consumes("postgres", nil, prefix("")),
+				consumes("redis", map[string]string{"REDIS_HOST": "host", "REDIS_PORT": "port"}, nil),
+			}}
+			errs := spec.Validate()
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.consumes[0].import.prefix"))
+			Expect(errs[0].Type).To(Equal(field.ErrorTypeRequired))
+		})
+
+		It("Should deny envs of other objects which may be imported", func() {
+			spec := TensegritySpec{Consumes: []ConsumesSpec{
+				consumes("postgres", nil, prefix("DB_")),
+				consumes("redis", map[string]string{"DB_CACHE": "host"}, nil),
+			}}
+			errs := spec.Validate()
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Detail).To(ContainSubstring("DB_CACHE"))
+		})
+
+		It("Should admit envs of other objects which are never imported", func() {
+			spec := TensegritySpec{Consumes: []ConsumesSpec{
+				consumes("postgres", nil, prefix("DB_")),
+				consumes("redis", map[string]string{"DB_": "host", "DB_cache": "port", "DATABASE": "name"}, nil),
+			}}
+			Expect(spec.Validate()).To(BeEmpty())
+		})
+
+		It("Should deny template envs which may be imported", func() {
+			spec := TensegritySpec{
+				Consumes:  []ConsumesSpec{consumes("postgres", nil, prefix("DB_"))},
+				Templates: []TemplateSpec{{Env: "DB_URL", Template: "{{ .DB_HOST }}"}},
+			}
+			errs := spec.Validate()
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.templates[0].env"))
+		})
+
+		It("Should admit template envs which are never imported", func() {
+			spec := TensegritySpec{
+				Consumes: []ConsumesSpec{consumes("postgres", nil, prefix(""))},
+				Templates: []TemplateSpec{
+					{Env: "database_url", Template: "{{ .HOST }}"},
+					{Env: "DB-URL", Template: "{{ .HOST }}"},
+				},
+			}
+			Expect(spec.Validate()).To(BeEmpty())
+
+			spec.Consumes[0].Import.Prefix = "DB_"
+			spec.Templates = []TemplateSpec{{Env: "DATABASE_URL", Template: "{{ .DB_HOST }}"}}
+			Expect(spec.Validate()).To(BeEmpty())
+		})
+
+		It("Should deny overlapping prefixes", func() {
+			spec := TensegritySpec{Consumes: []ConsumesSpec{
+				consumes("postgres", nil, prefix("DB_")),
+				consumes("replica", nil, prefix("DB_REPLICA_")),
+			}}
+			errs := spec.Validate()
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("spec.consumes[1].import.prefix"))
+		})
+	})
 })
@@ -0,0 +1,116 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateFuncs are functions available in TemplateSpec.
+var TemplateFuncs = template.FuncMap{
+	"urlencode": url.QueryEscape,
+	"default": func(value, input string) string {
+		if len(input) == 0 {
+			return value
+		}
+		return input
+	},
+	"join": func(sep string, elems ...string) string {
+		return strings.Join(elems, sep)
+	},
+	"base64": func(input string) string {
+		return base64.StdEncoding.EncodeToString([]byte(input))
+	},
+}
+
+// Parse parses the template, missing envs are rendered as empty strings, so they can be defaulted.
+func (s *TemplateSpec) Parse() (*template.Template, error) {
+	return template.New(s.Env).Option("missingkey=zero").Funcs(TemplateFuncs).Parse(s.Template)
+}
+
+// TemplateEnvs returns envs used by a parsed template, all is true when the template uses
+// the whole data, e.g. with index function, so any env may be used.
+func TemplateEnvs(t *template.Template) (envs []string, all bool) {
+	seen := make(map[string]struct{})
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			// dot is rebound inside of with, so fields there can't be attributed to envs
+			all = true
+		case *parse.TemplateNode:
+			all = true
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			seen[n.Ident[0]] = struct{}{}
+		case *parse.VariableNode:
+			if n.Ident[0] != "$" {
+				return
+			}
+			if len(n.Ident) > 1 {
+				seen[n.Ident[1]] = struct{}{}
+			} else {
+				all = true
+			}
+		case *parse.DotNode:
+			all = true
+		}
+	}
+	if t.Tree != nil {
+		walk(t.Tree.Root)
+	}
+	for env := range seen {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	return envs, all
+}
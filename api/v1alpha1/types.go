@@ -22,6 +22,8 @@ package v1alpha1
 
 import (
 	"sort"
+	"strings"
+	"unicode"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,13 +34,138 @@ type ConsumedStatus string
 const (
 	ConsumedSuccess ConsumedStatus = "Success"
 	ConsumedFailure ConsumedStatus = "Failure"
+	// ConsumedDefaulted means an optional key is not resolved and its env has a default value.
+	ConsumedDefaulted ConsumedStatus = "Defaulted"
+	// ConsumedOmitted means an optional key is not resolved and its env is left out.
+	ConsumedOmitted ConsumedStatus = "Omitted"
 )
 
+// ConsumedEnvSpec defines settings of an env consumed by ConsumesSpec.
+type ConsumedEnvSpec struct {
+	// Optional indicates that the env is left out when its key is not resolved.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+	// Default is a value of the env when its key is not resolved, it implies the env is optional.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// IsOptional returns true if the env is not required to be resolved.
+func (s ConsumedEnvSpec) IsOptional() bool {
+	return s.Optional || s.Default != nil
+}
+
+// ResolutionPolicy defines how consumed keys of an object are resolved through delegates.
+// +kubebuilder:validation:Enum=Reference;Key
+type ResolutionPolicy string
+
+const (
+	// ReferenceResolution takes all keys of a consumed object from the first delegate having all of them.
+	ReferenceResolution ResolutionPolicy = "Reference"
+	// KeyResolution resolves each key on its own from the first delegate having it.
+	KeyResolution ResolutionPolicy = "Key"
+)
+
+// ConsumesFailurePolicy defines what happens with consumed keys when some of them are not resolved.
+// +kubebuilder:validation:Enum=Clear;RetainLastKnownGood
+type ConsumesFailurePolicy string
+
+const (
+	// ClearOnFailure stops reconciling consumed keys until all of them are resolved.
+	ClearOnFailure ConsumesFailurePolicy = "Clear"
+	// RetainLastKnownGood keeps last known good values of not resolved keys in the consumed ConfigMap and Secret,
+	// and reports them by Degraded condition.
+	RetainLastKnownGood ConsumesFailurePolicy = "RetainLastKnownGood"
+)
+
+// ImportCase defines a rule to turn produced key names into env names.
+// +kubebuilder:validation:Enum=UpperSnake;Preserve
+type ImportCase string
+
+const (
+	// UpperSnakeCase turns key names into upper snake case, e.g. readReplica.host -> READ_REPLICA_HOST.
+	UpperSnakeCase ImportCase = "UpperSnake"
+	// PreserveCase keeps key names as is.
+	PreserveCase ImportCase = "Preserve"
+)
+
+// ImportSpec imports all keys produced by a consumed object as envs.
+type ImportSpec struct {
+	// Prefix is prepended to env names, e.g. DB_.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Case is a rule to turn key names into env names, defaults to UpperSnake.
+	// +optional
+	Case ImportCase `json:"case,omitempty"`
+}
+
+// EnvName returns an env name of a produced key name.
+func (s *ImportSpec) EnvName(key string) string {
+	if s.Case == PreserveCase {
+		return s.Prefix + key
+	}
+	var b strings.Builder
+	b.WriteString(s.Prefix)
+	runes := []rune(key)
+	for i, c := range runes {
+		switch {
+		case unicode.IsUpper(c):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(c)
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			b.WriteRune(unicode.ToUpper(c))
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// mayImport reports whether env may be a name of an imported key, UpperSnake case never produces
+// lowercase letters and characters other than letters, digits and underscores.
+func (s *ImportSpec) mayImport(env string) bool {
+	if !strings.HasPrefix(env, s.Prefix) || len(env) == len(s.Prefix) {
+		return false
+	}
+	if s.Case == PreserveCase {
+		return true
+	}
+	for _, c := range env[len(s.Prefix):] {
+		if c != '_' && !unicode.IsDigit(c) && (!unicode.IsLetter(c) || unicode.IsLower(c)) {
+			return false
+		}
+	}
+	return true
+}
+
 type ConsumesSpec struct {
 	// ObjectReference to an object is being consumed.
 	corev1.ObjectReference `json:",inline"`
 	// Maps defines mappings between consumed object keys and ConfigMap/Secret keys.
 	Maps map[string]string `json:"maps,omitempty"`
+	// Import imports all keys produced by the object as envs, explicit Maps take precedence over imported ones.
+	// +optional
+	Import *ImportSpec `json:"import,omitempty"`
+	// Envs defines optional and default settings of envs from Maps.
+	// +optional
+	Envs map[string]ConsumedEnvSpec `json:"envs,omitempty"`
+	// Resolution defines how keys are resolved through delegates, defaults to Reference.
+	// +optional
+	Resolution ResolutionPolicy `json:"resolution,omitempty"`
+}
+
+// DelegateSpec is a reference to a Kubernetes resource used to resolve consumed keys.
+type DelegateSpec struct {
+	// ObjectReference to a delegate resource, kind must be Namespace, NamespaceSelector or Cluster.
+	// Cluster delegate refers to a Secret with a kubeconfig by name in a resource namespace,
+	// and resolves consumed keys from a remote cluster namespace.
+	corev1.ObjectReference `json:",inline"`
+	// Selector selects namespaces by labels when kind is NamespaceSelector, selected namespaces
+	// are resolved in order of PriorityLabel label or annotation, higher first, and then by name.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 type ConsumedKeyStatus struct {
@@ -56,6 +183,34 @@ type ConsumedKeyStatus struct {
 	Env string `json:"env"`
 }
 
+// TemplateSpec builds an env from consumed envs.
+type TemplateSpec struct {
+	// Env is a name of an env is being built.
+	Env string `json:"env"`
+	// Template is a Go template over consumed envs, e.g. postgres://{{ urlencode .DB_USER }}@{{ .DB_HOST }},
+	// functions urlencode, default, join and base64 are available.
+	// A result is sensitive and goes into the consumed Secret when any of used envs is sensitive.
+	Template string `json:"template"`
+}
+
+// AggregationMode defines how values of objects selected by ProducesSpec selector are combined into a key value.
+// +kubebuilder:validation:Enum=Join;JSON;First;Count
+type AggregationMode string
+
+const (
+	// JoinAggregation joins values with a separator.
+	JoinAggregation AggregationMode = "Join"
+	// JSONAggregation encodes values as a JSON array.
+	JSONAggregation AggregationMode = "JSON"
+	// FirstAggregation takes a value of the first object.
+	FirstAggregation AggregationMode = "First"
+	// CountAggregation counts selected objects, a value source is not used.
+	CountAggregation AggregationMode = "Count"
+)
+
+// DefaultAggregationSeparator is a separator of values joined by JoinAggregation.
+const DefaultAggregationSeparator = ","
+
 type ProducedStatus string
 
 const (
@@ -63,11 +218,53 @@ const (
 	ProducedFailure ProducedStatus = "Failure"
 )
 
+// ReadySpec defines when a source object is ready to produce a key,
+// by a status condition or by a CEL expression.
+type ReadySpec struct {
+	// ConditionType is a type of a status condition of a ready source object.
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+	// Status of the condition of a ready source object, defaults to True.
+	// +optional
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+	// Expression is a CEL expression evaluated against the source object available as `self`,
+	// the object is ready when the expression is true.
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// ProducesSourceSpec is a source of a produced key value.
+type ProducesSourceSpec struct {
+	// ObjectReference is a reference to a Kubernetes resource as a source of value of the key is being produced.
+	corev1.ObjectReference `json:",inline"`
+	// Expression is a CEL expression evaluated against the referenced resource available as `self`,
+	// an alternative to FieldPath for values that need conditionals, defaults or string functions.
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// Selector selects resources by labels in place of Name, values of selected resources
+	// are ordered by name and combined according to Aggregation.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Aggregation defines how values of selected resources are combined, defaults to Join.
+	// +optional
+	Aggregation AggregationMode `json:"aggregation,omitempty"`
+	// Separator of values joined by Join aggregation, defaults to a comma.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+	// ReadyWhen holds the key back until the source object is ready,
+	// selected objects which are not ready are left out.
+	// +optional
+	ReadyWhen *ReadySpec `json:"readyWhen,omitempty"`
+}
+
 type ProducesSpec struct {
 	// Key is a name of a key is being produced.
 	Key string `json:"key"`
-	// ObjectReference is a reference to a Kubernetes resource as a source of value of the key is being produced.
-	corev1.ObjectReference `json:",inline"`
+	// ProducesSourceSpec is a source of value of the key is being produced.
+	ProducesSourceSpec `json:",inline"`
+	// Fallbacks are sources tried in order when the source above does not exist or resolves to an empty value.
+	// +optional
+	Fallbacks []ProducesSourceSpec `json:"fallbacks,omitempty"`
 	// Sensitive indicates that the produced key value must be hidden and consumed as a Secret.
 	// +optional
 	Sensitive bool `json:"sensitive,omitempty"`
@@ -76,9 +273,29 @@ type ProducesSpec struct {
 	Encoded bool `json:"encoded,omitempty"`
 }
 
+// ProducedSourceStatus is a source of a produced key failed to resolve a value.
+type ProducedSourceStatus struct {
+	// ObjectReference to a Kubernetes resource a value was resolved from.
+	corev1.ObjectReference `json:",inline"`
+	// Selector of resources a value was aggregated from.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// Reason of a failure.
+	Reason string `json:"reason"`
+}
+
 type ProducedKeyStatus struct {
 	// ObjectReference to a Kubernetes resource a key produced from.
 	corev1.ObjectReference `json:",inline"`
+	// Selector of resources a key is aggregated from.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// Fallback is an index of a fallback source a key is produced from.
+	// +optional
+	Fallback *int32 `json:"fallback,omitempty"`
+	// FailedSources are sources tried before the one a key is produced from, with reasons they failed.
+	// +optional
+	FailedSources []ProducedSourceStatus `json:"failedSources,omitempty"`
 	// Status of a key.
 	Status ProducedStatus `json:"status"`
 	// Reason of a status.
@@ -93,6 +310,16 @@ type ProducedKeyStatus struct {
 	Value *string `json:"value,omitempty"`
 }
 
+// ConsumerStatus is a resource consuming produced keys.
+type ConsumerStatus struct {
+	// ObjectReference to a consuming Tensegrity resource.
+	corev1.ObjectReference `json:",inline"`
+	// Delegate is a ObjectReference to a resource produced keys are consumed through.
+	Delegate corev1.ObjectReference `json:"delegate"`
+	// Keys are names of consumed keys.
+	Keys []string `json:"keys"`
+}
+
 // +kubebuilder:skipversion
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -107,13 +334,16 @@ type Tensegrity struct {
 
 // TensegritySpec is Tensegrity controller specs.
 type TensegritySpec struct {
-	// Delegates is a list of ObjectReference to a Kubernetes resource used to resolve consumed keys,
+	// Delegates is a list of DelegateSpec to a Kubernetes resource used to resolve consumed keys,
 	// if empty defaults to a resource namespace.
 	// +optional
-	Delegates []corev1.ObjectReference `json:"delegates,omitempty"`
+	Delegates []DelegateSpec `json:"delegates,omitempty"`
 	// Consumes is a map of other workloads and ConsumeSpec.
 	// +optional
 	Consumes []ConsumesSpec `json:"consumes,omitempty"`
+	// Templates builds envs from consumed envs.
+	// +optional
+	Templates []TemplateSpec `json:"templates,omitempty"`
 	// ConsumesSecretName is name of a Secret is being generated by Tensegrity controller for consumed keys,
 	// defaults to <workload-name>-consumed.
 	// +optional
@@ -122,6 +352,10 @@ type TensegritySpec struct {
 	// defaults to <workload-name>-consumed.
 	// +optional
 	ConsumesConfigMapName string `json:"consumesConfigMapName,omitempty"`
+	// ConsumesFailurePolicy defines what happens with consumed keys when some of them are not resolved,
+	// defaults to Clear.
+	// +optional
+	ConsumesFailurePolicy ConsumesFailurePolicy `json:"consumesFailurePolicy,omitempty"`
 	// Produces is a map of keys and value sources to get from.
 	// +optional
 	Produces []ProducesSpec `json:"produces,omitempty"`
@@ -157,6 +391,14 @@ type TensegrityStatus struct {
 	// ProducedConfigMapName is a name of a Secret with produced keys and respective values
 	// programmatically generated for a workload by Tensegrity controller.
 	ProducedConfigMapName string `json:"producedConfigMapName,omitempty"`
+	// Consumers are resources of the same cluster consuming produced keys, ordered by namespace, kind and name.
+	// Resources consuming through Cluster delegates run in other clusters, so they are not listed.
+	// At most MaxConsumers are listed.
+	// +optional
+	Consumers []ConsumerStatus `json:"consumers,omitempty"`
+	// ConsumerCount is a number of resources consuming produced keys, including ones not listed by Consumers.
+	// +optional
+	ConsumerCount int32 `json:"consumerCount,omitempty"`
 	// Conditions a list of conditions a tensegrity resource can have.
 	// +optional
 	Conditions []TensegrityCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
@@ -171,6 +413,7 @@ func (status *TensegrityStatus) ClearConsumes() {
 	status.ConsumedKeys = nil
 	status.ConsumedSecretName = ""
 	status.ConsumedConfigMapName = ""
+	RemoveTensegrityCondition(status, TensegrityConsumed)
 }
 
 func (status *TensegrityStatus) SortConsumes() {
@@ -184,6 +427,9 @@ func (status *TensegrityStatus) ClearProduces() {
 	status.ProducedKeys = nil
 	status.ProducedSecretName = ""
 	status.ProducedConfigMapName = ""
+	status.Consumers = nil
+	status.ConsumerCount = 0
+	RemoveTensegrityCondition(status, TensegrityProduced)
 }
 
 func (status *TensegrityStatus) SortProduces() {
@@ -18,7 +18,11 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/util/jsonpath"
 )
@@ -37,6 +41,13 @@ func (s *TensegritySpec) Validate() (allErrs field.ErrorList) {
 }
 
 func (s *TensegritySpec) validateConsumes() (errs field.ErrorList) {
+	switch s.ConsumesFailurePolicy {
+	case "", ClearOnFailure, RetainLastKnownGood:
+	default:
+		errs = append(errs, field.NotSupported(
+			field.NewPath("spec").Child("consumesFailurePolicy"),
+			s.ConsumesFailurePolicy, []ConsumesFailurePolicy{ClearOnFailure, RetainLastKnownGood}))
+	}
 	seenEnvs := make(map[string]struct{})
 	seenRefs := make(map[corev1.ObjectReference]struct{})
 	for i, c := range s.Consumes {
@@ -52,16 +63,39 @@ func (s *TensegritySpec) validateConsumes() (errs field.ErrorList) {
 			errs = append(errs, field.Required(
 				field.NewPath("spec").Child("consumes").Index(i).Child("name"), "valid resource name"))
 		}
-		if len(c.Maps) == 0 {
+		if len(c.Maps) == 0 && c.Import == nil {
 			errs = append(errs, field.Required(
 				field.NewPath("spec").Child("consumes").Index(i).Child("maps"),
 				"valid environment variables to keys mapping"))
 		}
+		if c.Import != nil {
+			switch c.Import.Case {
+			case "", UpperSnakeCase, PreserveCase:
+			default:
+				errs = append(errs, field.NotSupported(
+					field.NewPath("spec").Child("consumes").Index(i).Child("import", "case"),
+					c.Import.Case, []ImportCase{UpperSnakeCase, PreserveCase}))
+			}
+		}
+		switch c.Resolution {
+		case "", ReferenceResolution, KeyResolution:
+		default:
+			errs = append(errs, field.NotSupported(
+				field.NewPath("spec").Child("consumes").Index(i).Child("resolution"),
+				c.Resolution, []ResolutionPolicy{ReferenceResolution, KeyResolution}))
+		}
 		if _, ok := seenRefs[c.ObjectReference]; ok {
 			errs = append(errs, field.Duplicate(
 				field.NewPath("spec").Child("consumes").Index(i), c.ObjectReference))
 		}
 		seenRefs[c.ObjectReference] = struct{}{}
+		for env := range c.Envs {
+			if _, ok := c.Maps[env]; !ok {
+				errs = append(errs, field.Invalid(
+					field.NewPath("spec").Child("consumes").Index(i).Child("envs").Key(env),
+					env, "env must be defined in maps"))
+			}
+		}
 		for env := range c.Maps {
 			if _, ok := seenEnvs[env]; ok {
 				errs = append(errs, field.Duplicate(
@@ -70,6 +104,95 @@ func (s *TensegritySpec) validateConsumes() (errs field.ErrorList) {
 			seenEnvs[env] = struct{}{}
 		}
 	}
+	errs = append(errs, s.validateImports()...)
+	return append(errs, s.validateTemplates(seenEnvs)...)
+}
+
+func (s *TensegritySpec) validateTemplates(consumedEnvs map[string]struct{}) (errs field.ErrorList) {
+	imports := false
+	for _, c := range s.Consumes {
+		imports = imports || c.Import != nil
+	}
+	seenEnvs := make(map[string]struct{}, len(s.Templates))
+	for i, t := range s.Templates {
+		if len(t.Env) == 0 {
+			errs = append(errs, field.Required(
+				field.NewPath("spec").Child("templates").Index(i).Child("env"), "valid environment variable name"))
+		}
+		if _, ok := consumedEnvs[t.Env]; ok {
+			errs = append(errs, field.Duplicate(
+				field.NewPath("spec").Child("templates").Index(i).Child("env"), t.Env))
+		} else if _, ok := seenEnvs[t.Env]; ok {
+			errs = append(errs, field.Duplicate(
+				field.NewPath("spec").Child("templates").Index(i).Child("env"), t.Env))
+		}
+		seenEnvs[t.Env] = struct{}{}
+		for j, c := range s.Consumes {
+			if c.Import != nil && c.Import.mayImport(t.Env) {
+				errs = append(errs, field.Invalid(
+					field.NewPath("spec").Child("templates").Index(i).Child("env"),
+					t.Env, fmt.Sprintf("env may collide with envs imported by spec.consumes[%d]", j)))
+			}
+		}
+
+		tmpl, err := t.Parse()
+		if err != nil {
+			errs = append(errs, field.Invalid(
+				field.NewPath("spec").Child("templates").Index(i).Child("template"), t.Template, err.Error()))
+			continue
+		}
+		if imports {
+			// imported envs are known only when consumed
+			continue
+		}
+		envs, _ := TemplateEnvs(tmpl)
+		for _, env := range envs {
+			if _, ok := consumedEnvs[env]; !ok {
+				errs = append(errs, field.Invalid(
+					field.NewPath("spec").Child("templates").Index(i).Child("template"),
+					t.Template, fmt.Sprintf("env %s is not consumed", env)))
+			}
+		}
+	}
+	return errs
+}
+
+// validateImports rejects imports which may collide with envs of other consumed objects,
+// an import prefix must not overlap with prefixes of other imports and explicit envs of other objects.
+// An empty prefix imports keys as they are, so it is allowed only when no other objects are consumed.
+func (s *TensegritySpec) validateImports() (errs field.ErrorList) {
+	for i, c := range s.Consumes {
+		if c.Import == nil {
+			continue
+		}
+		if len(c.Import.Prefix) == 0 {
+			if len(s.Consumes) > 1 {
+				errs = append(errs, field.Required(
+					field.NewPath("spec").Child("consumes").Index(i).Child("import", "prefix"),
+					"import prefix when other objects are consumed"))
+			}
+			continue
+		}
+		for j, other := range s.Consumes {
+			if i == j {
+				continue
+			}
+			if other.Import != nil && len(other.Import.Prefix) > 0 && j < i &&
+				(strings.HasPrefix(c.Import.Prefix, other.Import.Prefix) ||
+					strings.HasPrefix(other.Import.Prefix, c.Import.Prefix)) {
+				errs = append(errs, field.Invalid(
+					field.NewPath("spec").Child("consumes").Index(i).Child("import", "prefix"),
+					c.Import.Prefix, fmt.Sprintf("import prefix overlaps with spec.consumes[%d].import.prefix", j)))
+			}
+			for env := range other.Maps {
+				if c.Import.mayImport(env) {
+					errs = append(errs, field.Invalid(
+						field.NewPath("spec").Child("consumes").Index(i).Child("import", "prefix"),
+						c.Import.Prefix, fmt.Sprintf("import prefix overlaps with env %s of spec.consumes[%d]", env, j)))
+				}
+			}
+		}
+	}
 	return errs
 }
 
@@ -85,61 +208,171 @@ func (s *TensegritySpec) validateProduces() (errs field.ErrorList) {
 				field.NewPath("spec").Child("produces").Index(i).Child("key"), p.Key))
 		}
 		seenKeys[p.Key] = struct{}{}
-		if len(p.APIVersion) == 0 {
-			errs = append(errs, field.Required(
-				field.NewPath("spec").Child("produces").Index(i).Child("apiVersion"), "valid resource api version"))
-		}
-		if len(p.Kind) == 0 {
-			errs = append(errs, field.Required(
-				field.NewPath("spec").Child("produces").Index(i).Child("kind"), "valid resource kind"))
-		}
-		if len(p.Name) == 0 {
-			errs = append(errs, field.Required(
-				field.NewPath("spec").Child("produces").Index(i).Child("name"), "valid resource name"))
-		}
-		if len(p.FieldPath) == 0 {
-			errs = append(errs, field.Required(
-				field.NewPath("spec").Child("produces").Index(i).Child("fieldPath"), "valid resource JSONPath"))
-		}
 		if !p.Sensitive && p.Encoded {
 			errs = append(errs, field.Invalid(
 				field.NewPath("spec").Child("produces").Index(i).Child("encoded"),
 				p.FieldPath, "encoded field is allowed only when key is sensitive"))
 		}
-		jp := jsonpath.New(p.Key)
-		jp.AllowMissingKeys(false)
-		if err := jp.Parse(p.FieldPath); err != nil {
-			errs = append(errs, field.Invalid(
-				field.NewPath("spec").Child("produces").Index(i).Child("fieldPath"),
-				p.FieldPath, "valid resource JSONPath"))
+		errs = append(errs, p.validateSource(field.NewPath("spec").Child("produces").Index(i), p.Key)...)
+		for j, fallback := range p.Fallbacks {
+			errs = append(errs, fallback.validateSource(
+				field.NewPath("spec").Child("produces").Index(i).Child("fallbacks").Index(j), p.Key)...)
 		}
 	}
 	return errs
 }
 
+func (p *ProducesSourceSpec) validateSource(path *field.Path, key string) (errs field.ErrorList) {
+	if len(p.APIVersion) == 0 {
+		errs = append(errs, field.Required(path.Child("apiVersion"), "valid resource api version"))
+	}
+	if len(p.Kind) == 0 {
+		errs = append(errs, field.Required(path.Child("kind"), "valid resource kind"))
+	}
+	switch {
+	case len(p.Name) == 0 && p.Selector == nil:
+		errs = append(errs, field.Required(path.Child("name"), "valid resource name"))
+	case len(p.Name) > 0 && p.Selector != nil:
+		errs = append(errs, field.Forbidden(path.Child("selector"), "selector is not allowed when name is set"))
+	}
+	errs = append(errs, p.validateSelector(path)...)
+	errs = append(errs, p.validateReadyWhen(path.Child("readyWhen"))...)
+	switch {
+	case p.Selector != nil && p.Aggregation == CountAggregation:
+		if len(p.FieldPath) > 0 || len(p.Expression) > 0 {
+			errs = append(errs, field.Forbidden(path.Child("aggregation"),
+				"fieldPath and expression are not allowed when aggregation is Count"))
+		}
+		return errs
+	case len(p.FieldPath) == 0 && len(p.Expression) == 0:
+		errs = append(errs, field.Required(path.Child("fieldPath"), "valid resource JSONPath or expression"))
+	case len(p.FieldPath) > 0 && len(p.Expression) > 0:
+		errs = append(errs, field.Forbidden(path.Child("expression"), "expression is not allowed when fieldPath is set"))
+	}
+	if len(p.Expression) > 0 {
+		if _, err := CompileExpression(p.Expression); err != nil {
+			errs = append(errs, field.Invalid(path.Child("expression"), p.Expression, err.Error()))
+		}
+		return errs
+	}
+	jp := jsonpath.New(key)
+	jp.AllowMissingKeys(false)
+	if err := jp.Parse(p.FieldPath); err != nil {
+		errs = append(errs, field.Invalid(path.Child("fieldPath"), p.FieldPath, "valid resource JSONPath"))
+	}
+	return errs
+}
+
+func (p *ProducesSourceSpec) validateReadyWhen(path *field.Path) (errs field.ErrorList) {
+	if p.ReadyWhen == nil {
+		return nil
+	}
+	switch {
+	case len(p.ReadyWhen.ConditionType) == 0 && len(p.ReadyWhen.Expression) == 0:
+		errs = append(errs, field.Required(path.Child("conditionType"), "valid condition type or expression"))
+	case len(p.ReadyWhen.ConditionType) > 0 && len(p.ReadyWhen.Expression) > 0:
+		errs = append(errs, field.Forbidden(path.Child("expression"),
+			"expression is not allowed when conditionType is set"))
+	}
+	switch p.ReadyWhen.Status {
+	case "", metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("status"), p.ReadyWhen.Status,
+			[]metav1.ConditionStatus{metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown}))
+	}
+	if len(p.ReadyWhen.Expression) > 0 {
+		if len(p.ReadyWhen.Status) > 0 {
+			errs = append(errs, field.Forbidden(path.Child("status"),
+				"status is allowed only when conditionType is set"))
+		}
+		if _, err := CompileReadyExpression(p.ReadyWhen.Expression); err != nil {
+			errs = append(errs, field.Invalid(path.Child("expression"), p.ReadyWhen.Expression, err.Error()))
+		}
+	}
+	return errs
+}
+
+func (p *ProducesSourceSpec) validateSelector(path *field.Path) (errs field.ErrorList) {
+	if p.Selector == nil {
+		if len(p.Aggregation) > 0 {
+			errs = append(errs, field.Forbidden(path.Child("aggregation"),
+				"aggregation is allowed only when selector is set"))
+		}
+		if p.Separator != nil {
+			errs = append(errs, field.Forbidden(path.Child("separator"),
+				"separator is allowed only when selector is set"))
+		}
+		return errs
+	}
+	if _, err := metav1.LabelSelectorAsSelector(p.Selector); err != nil {
+		errs = append(errs, field.Invalid(path.Child("selector"), p.Selector, err.Error()))
+	}
+	switch p.Aggregation {
+	case "", JoinAggregation:
+	case JSONAggregation, FirstAggregation, CountAggregation:
+		if p.Separator != nil {
+			errs = append(errs, field.Forbidden(path.Child("separator"),
+				"separator is allowed only when aggregation is Join"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("aggregation"), p.Aggregation,
+			[]AggregationMode{JoinAggregation, JSONAggregation, FirstAggregation, CountAggregation}))
+	}
+	return errs
+}
+
 func (s *TensegritySpec) validateDelegates() (errs field.ErrorList) {
-	seenDelegates := make(map[corev1.ObjectReference]struct{}, len(s.Delegates))
+	seenDelegates := make(map[string]struct{}, len(s.Delegates))
 	for i, d := range s.Delegates {
 		if len(d.Kind) == 0 {
 			errs = append(errs, field.Required(
 				field.NewPath("spec").Child("delegates").Index(i).Child("kind"), "valid resource kind"))
 		}
-		if len(d.Name) == 0 {
-			errs = append(errs, field.Required(
-				field.NewPath("spec").Child("delegates").Index(i).Child("name"), "valid resource name"))
-		}
 		switch d.Kind {
 		case "Namespace":
+			if len(d.Name) == 0 {
+				errs = append(errs, field.Required(
+					field.NewPath("spec").Child("delegates").Index(i).Child("name"), "valid resource name"))
+			}
+			if d.Selector != nil {
+				errs = append(errs, field.Forbidden(
+					field.NewPath("spec").Child("delegates").Index(i).Child("selector"),
+					"selector is allowed only when kind is NamespaceSelector"))
+			}
+		case "NamespaceSelector":
+			if d.Selector == nil {
+				errs = append(errs, field.Required(
+					field.NewPath("spec").Child("delegates").Index(i).Child("selector"), "valid label selector"))
+			} else if _, err := metav1.LabelSelectorAsSelector(d.Selector); err != nil {
+				errs = append(errs, field.Invalid(
+					field.NewPath("spec").Child("delegates").Index(i).Child("selector"),
+					d.Selector, err.Error()))
+			}
+		case "Cluster":
+			if len(d.Name) == 0 {
+				errs = append(errs, field.Required(
+					field.NewPath("spec").Child("delegates").Index(i).Child("name"), "valid kubeconfig secret name"))
+			}
+			if len(d.Namespace) == 0 {
+				errs = append(errs, field.Required(
+					field.NewPath("spec").Child("delegates").Index(i).Child("namespace"), "valid remote namespace"))
+			}
+			if d.Selector != nil {
+				errs = append(errs, field.Forbidden(
+					field.NewPath("spec").Child("delegates").Index(i).Child("selector"),
+					"selector is allowed only when kind is NamespaceSelector"))
+			}
 		default:
 			errs = append(errs, field.Invalid(
 				field.NewPath("spec").Child("delegates").Index(i).Child("kind"),
-				d.Kind, "kind must be on of these values: Namespace"))
+				d.Kind, "kind must be on of these values: Namespace, NamespaceSelector, Cluster"))
 		}
-		if _, ok := seenDelegates[d]; ok {
+		key := d.Kind + "/" + d.Namespace + "/" + d.Name + "/" + metav1.FormatLabelSelector(d.Selector)
+		if _, ok := seenDelegates[key]; ok {
 			errs = append(errs, field.Duplicate(
 				field.NewPath("spec").Child("delegates").Index(i), d))
 		}
-		seenDelegates[d] = struct{}{}
+		seenDelegates[key] = struct{}{}
 	}
 	return
 }
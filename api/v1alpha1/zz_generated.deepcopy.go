@@ -22,17 +22,38 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumedEnvSpec) DeepCopyInto(out *ConsumedEnvSpec) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumedEnvSpec.
+func (in *ConsumedEnvSpec) DeepCopy() *ConsumedEnvSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumedEnvSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConsumedKeyStatus) DeepCopyInto(out *ConsumedKeyStatus) {
 	*out = *in
 	out.ObjectReference = in.ObjectReference
 	if in.Delegate != nil {
 		in, out := &in.Delegate, &out.Delegate
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.Reason != nil {
@@ -52,6 +73,28 @@ func (in *ConsumedKeyStatus) DeepCopy() *ConsumedKeyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerStatus) DeepCopyInto(out *ConsumerStatus) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	out.Delegate = in.Delegate
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerStatus.
+func (in *ConsumerStatus) DeepCopy() *ConsumerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConsumesSpec) DeepCopyInto(out *ConsumesSpec) {
 	*out = *in
@@ -63,6 +106,18 @@ func (in *ConsumesSpec) DeepCopyInto(out *ConsumesSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ImportSpec)
+		**out = **in
+	}
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make(map[string]ConsumedEnvSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumesSpec.
@@ -75,10 +130,56 @@ func (in *ConsumesSpec) DeepCopy() *ConsumesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DelegateSpec) DeepCopyInto(out *DelegateSpec) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DelegateSpec.
+func (in *DelegateSpec) DeepCopy() *DelegateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DelegateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportSpec) DeepCopyInto(out *ImportSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportSpec.
+func (in *ImportSpec) DeepCopy() *ImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProducedKeyStatus) DeepCopyInto(out *ProducedKeyStatus) {
 	*out = *in
 	out.ObjectReference = in.ObjectReference
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedSources != nil {
+		in, out := &in.FailedSources, &out.FailedSources
+		*out = make([]ProducedSourceStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.Reason != nil {
 		in, out := &in.Reason, &out.Reason
 		*out = new(string)
@@ -102,11 +203,65 @@ func (in *ProducedKeyStatus) DeepCopy() *ProducedKeyStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProducesSpec) DeepCopyInto(out *ProducesSpec) {
+func (in *ProducedSourceStatus) DeepCopyInto(out *ProducedSourceStatus) {
 	*out = *in
 	out.ObjectReference = in.ObjectReference
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducedSourceStatus.
+func (in *ProducedSourceStatus) DeepCopy() *ProducedSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducedSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducesSourceSpec) DeepCopyInto(out *ProducesSourceSpec) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Separator != nil {
+		in, out := &in.Separator, &out.Separator
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReadyWhen != nil {
+		in, out := &in.ReadyWhen, &out.ReadyWhen
+		*out = new(ReadySpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducesSourceSpec.
+func (in *ProducesSourceSpec) DeepCopy() *ProducesSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducesSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducesSpec) DeepCopyInto(out *ProducesSpec) {
+	*out = *in
+	in.ProducesSourceSpec.DeepCopyInto(&out.ProducesSourceSpec)
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]ProducesSourceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducesSpec.
 func (in *ProducesSpec) DeepCopy() *ProducesSpec {
 	if in == nil {
@@ -117,6 +272,21 @@ func (in *ProducesSpec) DeepCopy() *ProducesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadySpec) DeepCopyInto(out *ReadySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadySpec.
+func (in *ReadySpec) DeepCopy() *ReadySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Static) DeepCopyInto(out *Static) {
 	*out = *in
@@ -180,6 +350,27 @@ func (in *StaticList) DeepCopyObject() runtime.Object {
 func (in *StaticSpec) DeepCopyInto(out *StaticSpec) {
 	*out = *in
 	in.TensegritySpec.DeepCopyInto(&out.TensegritySpec)
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SensitiveData != nil {
+		in, out := &in.SensitiveData, &out.SensitiveData
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SealedData != nil {
+		in, out := &in.SealedData, &out.SealedData
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticSpec.
@@ -208,6 +399,21 @@ func (in *StaticStatus) DeepCopy() *StaticStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSpec) DeepCopyInto(out *TemplateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSpec.
+func (in *TemplateSpec) DeepCopy() *TemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Tensegrity) DeepCopyInto(out *Tensegrity) {
 	*out = *in
@@ -257,8 +463,10 @@ func (in *TensegritySpec) DeepCopyInto(out *TensegritySpec) {
 	*out = *in
 	if in.Delegates != nil {
 		in, out := &in.Delegates, &out.Delegates
-		*out = make([]v1.ObjectReference, len(*in))
-		copy(*out, *in)
+		*out = make([]DelegateSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Consumes != nil {
 		in, out := &in.Consumes, &out.Consumes
@@ -267,10 +475,17 @@ func (in *TensegritySpec) DeepCopyInto(out *TensegritySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]TemplateSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.Produces != nil {
 		in, out := &in.Produces, &out.Produces
 		*out = make([]ProducesSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -311,6 +526,13 @@ func (in *TensegrityStatus) DeepCopyInto(out *TensegrityStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]ConsumerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]TensegrityCondition, len(*in))
@@ -0,0 +1,299 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// ConversionAnnotation holds a v1alpha1 spec in JSON on a v1beta1 resource when v1beta1 can't represent it,
+// e.g. unused fields of object references or envs settings without maps, so the resource converts back to v1alpha1
+// without loss. It is ignored once the v1beta1 spec is changed.
+const ConversionAnnotation = "tensegrity.fastforge.io/v1alpha1-spec"
+
+// ConvertSpecFromHub converts a v1alpha1 spec into a v1beta1 spec of a resource with meta, self refers to
+// the resource in v1alpha1, so sources referring to the resource by a literal fieldPath become values.
+func ConvertSpecFromHub(src *v1alpha1.TensegritySpec, dst *TensegritySpec, self corev1.ObjectReference,
+	meta *metav1.ObjectMeta) error {
+
+	convertSpecFromHub(src, dst, self)
+	delete(meta.Annotations, ConversionAnnotation)
+
+	restored := new(v1alpha1.TensegritySpec)
+	convertSpecToHub(dst, restored, self)
+	equal, err := jsonEqual(src, restored)
+	if err != nil || equal {
+		return err
+	}
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	metav1.SetMetaDataAnnotation(meta, ConversionAnnotation, string(data))
+	return nil
+}
+
+// ConvertSpecToHub converts a v1beta1 spec of a resource with meta into a v1alpha1 spec, self refers to
+// the resource in v1alpha1. A v1alpha1 spec kept by ConversionAnnotation is restored and the annotation
+// is removed, unless the v1beta1 spec is changed since.
+func ConvertSpecToHub(src *TensegritySpec, dst *v1alpha1.TensegritySpec, self corev1.ObjectReference,
+	meta *metav1.ObjectMeta) error {
+
+	convertSpecToHub(src, dst, self)
+	data, ok := meta.Annotations[ConversionAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(meta.Annotations, ConversionAnnotation)
+
+	kept := new(v1alpha1.TensegritySpec)
+	if err := json.Unmarshal([]byte(data), kept); err != nil {
+		// the annotation is not written by conversion, so there is nothing to restore
+		return nil
+	}
+	current := new(TensegritySpec)
+	convertSpecFromHub(kept, current, self)
+	if equal, err := jsonEqual(src, current); err != nil || !equal {
+		return err
+	}
+	*dst = *kept
+	return nil
+}
+
+// ConvertStatusFromHub converts a v1alpha1 status into a v1beta1 status, they have the same shape.
+func ConvertStatusFromHub(src *v1alpha1.TensegrityStatus, dst *TensegrityStatus) error {
+	return convertJSON(src, dst)
+}
+
+// ConvertStatusToHub converts a v1beta1 status into a v1alpha1 status, they have the same shape.
+func ConvertStatusToHub(src *TensegrityStatus, dst *v1alpha1.TensegrityStatus) error {
+	return convertJSON(src, dst)
+}
+
+func convertSpecFromHub(src *v1alpha1.TensegritySpec, dst *TensegritySpec, self corev1.ObjectReference) {
+	*dst = TensegritySpec{
+		ConsumesSecretName:    src.ConsumesSecretName,
+		ConsumesConfigMapName: src.ConsumesConfigMapName,
+		ConsumesFailurePolicy: ConsumesFailurePolicy(src.ConsumesFailurePolicy),
+		ProducesSecretName:    src.ProducesSecretName,
+		ProducesConfigMapName: src.ProducesConfigMapName,
+	}
+	for _, d := range src.Delegates {
+		dst.Delegates = append(dst.Delegates, DelegateSpec{
+			Kind:      DelegateKind(d.Kind),
+			Name:      d.Name,
+			Namespace: d.Namespace,
+			Selector:  d.Selector.DeepCopy(),
+		})
+	}
+	for _, c := range src.Consumes {
+		consumes := ConsumesSpec{
+			ResourceReference: ResourceReference{APIVersion: c.APIVersion, Kind: c.Kind, Name: c.Name},
+			Resolution:        ResolutionPolicy(c.Resolution),
+		}
+		if c.Import != nil {
+			consumes.Import = &ImportSpec{Prefix: c.Import.Prefix, Case: ImportCase(c.Import.Case)}
+		}
+		if c.Maps != nil {
+			consumes.Envs = make(map[string]ConsumedEnvSpec, len(c.Maps))
+		}
+		for env, key := range c.Maps {
+			settings := c.Envs[env]
+			consumes.Envs[env] = ConsumedEnvSpec{
+				Key:      key,
+				Optional: settings.Optional,
+				Default:  copyString(settings.Default),
+			}
+		}
+		dst.Consumes = append(dst.Consumes, consumes)
+	}
+	for _, t := range src.Templates {
+		dst.Templates = append(dst.Templates, TemplateSpec{Env: t.Env, Template: t.Template})
+	}
+	for _, p := range src.Produces {
+		produces := ProducesSpec{
+			Key:       p.Key,
+			Sensitive: p.Sensitive,
+			Encoded:   p.Encoded,
+		}
+		if value, ok := literalValue(&p, self); ok {
+			produces.Value = value
+		} else {
+			produces.ProducesSourceSpec = convertSourceFromHub(&p.ProducesSourceSpec)
+		}
+		for _, fallback := range p.Fallbacks {
+			produces.Fallbacks = append(produces.Fallbacks, convertSourceFromHub(&fallback))
+		}
+		dst.Produces = append(dst.Produces, produces)
+	}
+}
+
+func convertSpecToHub(src *TensegritySpec, dst *v1alpha1.TensegritySpec, self corev1.ObjectReference) {
+	*dst = v1alpha1.TensegritySpec{
+		ConsumesSecretName:    src.ConsumesSecretName,
+		ConsumesConfigMapName: src.ConsumesConfigMapName,
+		ConsumesFailurePolicy: v1alpha1.ConsumesFailurePolicy(src.ConsumesFailurePolicy),
+		ProducesSecretName:    src.ProducesSecretName,
+		ProducesConfigMapName: src.ProducesConfigMapName,
+	}
+	for _, d := range src.Delegates {
+		dst.Delegates = append(dst.Delegates, v1alpha1.DelegateSpec{
+			ObjectReference: corev1.ObjectReference{Kind: string(d.Kind), Name: d.Name, Namespace: d.Namespace},
+			Selector:        d.Selector.DeepCopy(),
+		})
+	}
+	for _, c := range src.Consumes {
+		consumes := v1alpha1.ConsumesSpec{
+			ObjectReference: corev1.ObjectReference{APIVersion: c.APIVersion, Kind: c.Kind, Name: c.Name},
+			Resolution:      v1alpha1.ResolutionPolicy(c.Resolution),
+		}
+		if c.Import != nil {
+			consumes.Import = &v1alpha1.ImportSpec{Prefix: c.Import.Prefix, Case: v1alpha1.ImportCase(c.Import.Case)}
+		}
+		if c.Envs != nil {
+			consumes.Maps = make(map[string]string, len(c.Envs))
+		}
+		for env, settings := range c.Envs {
+			consumes.Maps[env] = settings.Key
+			if settings.Optional || settings.Default != nil {
+				if consumes.Envs == nil {
+					consumes.Envs = make(map[string]v1alpha1.ConsumedEnvSpec)
+				}
+				consumes.Envs[env] = v1alpha1.ConsumedEnvSpec{
+					Optional: settings.Optional,
+					Default:  copyString(settings.Default),
+				}
+			}
+		}
+		dst.Consumes = append(dst.Consumes, consumes)
+	}
+	for _, t := range src.Templates {
+		dst.Templates = append(dst.Templates, v1alpha1.TemplateSpec{Env: t.Env, Template: t.Template})
+	}
+	for _, p := range src.Produces {
+		produces := v1alpha1.ProducesSpec{
+			Key:                p.Key,
+			ProducesSourceSpec: convertSourceToHub(&p.ProducesSourceSpec),
+			Sensitive:          p.Sensitive,
+			Encoded:            p.Encoded,
+		}
+		if len(p.Value) > 0 {
+			produces.APIVersion = self.APIVersion
+			produces.Kind = self.Kind
+			produces.Name = self.Name
+			if strings.Contains(p.Value, "{") {
+				produces.Expression = strconv.Quote(p.Value)
+			} else {
+				produces.FieldPath = p.Value
+			}
+		}
+		for _, fallback := range p.Fallbacks {
+			produces.Fallbacks = append(produces.Fallbacks, convertSourceToHub(&fallback))
+		}
+		dst.Produces = append(dst.Produces, produces)
+	}
+}
+
+func convertSourceFromHub(src *v1alpha1.ProducesSourceSpec) ProducesSourceSpec {
+	dst := ProducesSourceSpec{
+		ResourceReference: ResourceReference{APIVersion: src.APIVersion, Kind: src.Kind, Name: src.Name},
+		FieldPath:         src.FieldPath,
+		Expression:        src.Expression,
+		Selector:          src.Selector.DeepCopy(),
+		Aggregation:       AggregationMode(src.Aggregation),
+		Separator:         copyString(src.Separator),
+	}
+	if src.ReadyWhen != nil {
+		dst.ReadyWhen = &ReadySpec{
+			ConditionType: src.ReadyWhen.ConditionType,
+			Status:        src.ReadyWhen.Status,
+			Expression:    src.ReadyWhen.Expression,
+		}
+	}
+	return dst
+}
+
+func convertSourceToHub(src *ProducesSourceSpec) v1alpha1.ProducesSourceSpec {
+	dst := v1alpha1.ProducesSourceSpec{
+		ObjectReference: corev1.ObjectReference{
+			APIVersion: src.APIVersion,
+			Kind:       src.Kind,
+			Name:       src.Name,
+			FieldPath:  src.FieldPath,
+		},
+		Expression:  src.Expression,
+		Selector:    src.Selector.DeepCopy(),
+		Aggregation: v1alpha1.AggregationMode(src.Aggregation),
+		Separator:   copyString(src.Separator),
+	}
+	if src.ReadyWhen != nil {
+		dst.ReadyWhen = &v1alpha1.ReadySpec{
+			ConditionType: src.ReadyWhen.ConditionType,
+			Status:        src.ReadyWhen.Status,
+			Expression:    src.ReadyWhen.Expression,
+		}
+	}
+	return dst
+}
+
+// literalValue returns a literal value of a v1alpha1 produced key, which refers to the resource itself
+// by a fieldPath without JSONPath expressions, or by an expression of a quoted string containing braces.
+func literalValue(p *v1alpha1.ProducesSpec, self corev1.ObjectReference) (string, bool) {
+	value, ok := p.LiteralValue(self)
+	if ok && len(p.Expression) > 0 && !strings.Contains(value, "{") {
+		return "", false
+	}
+	return value, ok
+}
+
+// jsonEqual compares JSON encodings, so nil and empty maps and slices omitted in JSON are equal.
+func jsonEqual(a, b any) (bool, error) {
+	aData, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bData, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aData, bData), nil
+}
+
+func convertJSON(src, dst any) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
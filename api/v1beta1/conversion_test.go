@@ -0,0 +1,171 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("Static conversion", func() {
+	newHub := func() *v1alpha1.Static {
+		return &v1alpha1.Static{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec: v1alpha1.StaticSpec{TensegritySpec: v1alpha1.TensegritySpec{
+				Delegates: []v1alpha1.DelegateSpec{{
+					ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "staging"},
+				}},
+				Consumes: []v1alpha1.ConsumesSpec{{
+					ObjectReference: corev1.ObjectReference{
+						APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "observability"},
+					Maps: map[string]string{"SENTRY_DSN": "sentryDsn", "LOG_LEVEL": "logLevel"},
+					Envs: map[string]v1alpha1.ConsumedEnvSpec{
+						"SENTRY_DSN": {Optional: true},
+						"LOG_LEVEL":  {Default: ptr.To("info")},
+					},
+					Resolution: v1alpha1.KeyResolution,
+				}},
+				Produces: []v1alpha1.ProducesSpec{
+					{
+						Key: "host",
+						ProducesSourceSpec: v1alpha1.ProducesSourceSpec{ObjectReference: corev1.ObjectReference{
+							APIVersion: "tensegrity.fastforge.io/v1alpha1", Kind: "Static", Name: "postgres",
+							FieldPath: "postgres.testing"}},
+					},
+					{
+						Key: "password",
+						ProducesSourceSpec: v1alpha1.ProducesSourceSpec{ObjectReference: corev1.ObjectReference{
+							APIVersion: "v1", Kind: "Secret", Name: "postgres", FieldPath: "{ .data.PASSWORD }"}},
+						Sensitive: true,
+						Encoded:   true,
+					},
+				},
+			}},
+			Status: v1alpha1.StaticStatus{TensegrityStatus: v1alpha1.TensegrityStatus{
+				Produced: ptr.To(v1alpha1.ProducedSuccess),
+				ProducedKeys: []v1alpha1.ProducedKeyStatus{{
+					ObjectReference: corev1.ObjectReference{Kind: "Static", Name: "postgres"},
+					Status:          v1alpha1.ProducedSuccess,
+					Key:             "host",
+					Value:           ptr.To("postgres.testing"),
+				}},
+				ConsumerCount:      1,
+				ObservedGeneration: 2,
+			}},
+		}
+	}
+
+	It("should convert v1alpha1 into cleaner v1beta1 structs", func() {
+		spoke := new(Static)
+		Expect(spoke.ConvertFrom(newHub())).To(Succeed())
+
+		Expect(spoke.Annotations).NotTo(HaveKey(ConversionAnnotation))
+		Expect(spoke.Spec.Delegates).To(Equal([]DelegateSpec{{Kind: NamespaceDelegate, Name: "staging"}}))
+		Expect(spoke.Spec.Consumes[0].Envs).To(Equal(map[string]ConsumedEnvSpec{
+			"SENTRY_DSN": {Key: "sentryDsn", Optional: true},
+			"LOG_LEVEL":  {Key: "logLevel", Default: ptr.To("info")},
+		}))
+		Expect(spoke.Spec.Produces[0].Value).To(Equal("postgres.testing"))
+		Expect(spoke.Spec.Produces[0].ProducesSourceSpec).To(BeZero())
+		Expect(spoke.Spec.Produces[1].Value).To(BeEmpty())
+		Expect(spoke.Spec.Produces[1].FieldPath).To(Equal("{ .data.PASSWORD }"))
+		Expect(spoke.Status.ProducedKeys[0].Value).To(Equal(ptr.To("postgres.testing")))
+		Expect(spoke.Status.ConsumerCount).To(Equal(int32(1)))
+	})
+
+	It("should round-trip v1alpha1 without loss", func() {
+		hub := newHub()
+		hub.Spec.Data = map[string]string{"port": "5432"}
+		hub.Spec.SensitiveData = map[string]string{"user": "postgres"}
+		hub.Spec.SealedData = map[string]string{"password": "sealed:v1:0123456789abcdef:UEAzM1cwUkQ="}
+		spoke := new(Static)
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		restored := new(v1alpha1.Static)
+		Expect(spoke.ConvertTo(restored)).To(Succeed())
+		Expect(restored).To(Equal(hub))
+	})
+
+	It("should keep v1alpha1 fields v1beta1 can't represent", func() {
+		hub := newHub()
+		hub.Spec.Consumes[0].Namespace = "observability"
+		hub.Spec.Consumes[0].FieldPath = "unused"
+		hub.Spec.Consumes[0].Envs["TRACING"] = v1alpha1.ConsumedEnvSpec{}
+		hub.Spec.Delegates[0].APIVersion = "v1"
+
+		spoke := new(Static)
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Annotations).To(HaveKey(ConversionAnnotation))
+
+		restored := new(v1alpha1.Static)
+		Expect(spoke.ConvertTo(restored)).To(Succeed())
+		Expect(restored.Annotations).NotTo(HaveKey(ConversionAnnotation))
+		Expect(restored.Spec).To(Equal(hub.Spec))
+	})
+
+	It("should ignore kept v1alpha1 fields once v1beta1 spec is changed", func() {
+		hub := newHub()
+		hub.Spec.Consumes[0].Namespace = "observability"
+
+		spoke := new(Static)
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		spoke.Spec.Consumes[0].Name = "tracing"
+
+		restored := new(v1alpha1.Static)
+		Expect(spoke.ConvertTo(restored)).To(Succeed())
+		Expect(restored.Annotations).NotTo(HaveKey(ConversionAnnotation))
+		Expect(restored.Spec.Consumes[0].Name).To(Equal("tracing"))
+		Expect(restored.Spec.Consumes[0].Namespace).To(BeEmpty())
+	})
+
+	It("should round-trip v1beta1 without loss", func() {
+		spoke := &Static{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec: StaticSpec{TensegritySpec: TensegritySpec{
+				Delegates: []DelegateSpec{{Kind: ClusterDelegate, Name: "staging-kubeconfig", Namespace: "staging"}},
+				Consumes: []ConsumesSpec{{
+					ResourceReference: ResourceReference{
+						APIVersion: "tensegrity.fastforge.io/v1beta1", Kind: "Static", Name: "observability"},
+					Envs:   map[string]ConsumedEnvSpec{"LOG_LEVEL": {Key: "logLevel", Default: ptr.To("info")}},
+					Import: &ImportSpec{Prefix: "OBS_"},
+				}},
+				Produces: []ProducesSpec{
+					{Key: "host", Value: "postgres.testing"},
+					{Key: "options", Value: `{"sslmode": "disable"}`},
+					{Key: "ip", ProducesSourceSpec: ProducesSourceSpec{
+						ResourceReference: ResourceReference{APIVersion: "v1", Kind: "Service", Name: "postgres"},
+						Expression:        "self.spec.clusterIP",
+					}},
+				},
+			}},
+		}
+
+		hub := new(v1alpha1.Static)
+		Expect(spoke.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.Produces[1].Expression).To(Equal(`"{\"sslmode\": \"disable\"}"`))
+
+		restored := new(Static)
+		Expect(restored.ConvertFrom(hub)).To(Succeed())
+		Expect(restored).To(Equal(spoke))
+	})
+})
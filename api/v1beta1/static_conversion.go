@@ -0,0 +1,61 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &Static{}
+
+// ConvertTo converts this Static to the Hub version (v1alpha1).
+func (src *Static) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Static)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	if err := ConvertSpecToHub(&src.Spec.TensegritySpec, &dst.Spec.TensegritySpec,
+		staticReference(src.Name), &dst.ObjectMeta); err != nil {
+		return err
+	}
+	dst.Spec.Data = maps.Clone(src.Spec.Data)
+	dst.Spec.SensitiveData = maps.Clone(src.Spec.SensitiveData)
+	dst.Spec.SealedData = maps.Clone(src.Spec.SealedData)
+	return ConvertStatusToHub(&src.Status.TensegrityStatus, &dst.Status.TensegrityStatus)
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version.
+func (dst *Static) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Static)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	if err := ConvertSpecFromHub(&src.Spec.TensegritySpec, &dst.Spec.TensegritySpec,
+		staticReference(src.Name), &dst.ObjectMeta); err != nil {
+		return err
+	}
+	dst.Spec.Data = maps.Clone(src.Spec.Data)
+	dst.Spec.SensitiveData = maps.Clone(src.Spec.SensitiveData)
+	dst.Spec.SealedData = maps.Clone(src.Spec.SealedData)
+	return ConvertStatusFromHub(&src.Status.TensegrityStatus, &dst.Status.TensegrityStatus)
+}
+
+func staticReference(name string) corev1.ObjectReference {
+	return corev1.ObjectReference{APIVersion: v1alpha1.GroupVersion.String(), Kind: "Static", Name: name}
+}
@@ -0,0 +1,411 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ConsumedStatus string
+
+const (
+	ConsumedSuccess ConsumedStatus = "Success"
+	ConsumedFailure ConsumedStatus = "Failure"
+	// ConsumedDefaulted means an optional key is not resolved and its env has a default value.
+	ConsumedDefaulted ConsumedStatus = "Defaulted"
+	// ConsumedOmitted means an optional key is not resolved and its env is left out.
+	ConsumedOmitted ConsumedStatus = "Omitted"
+)
+
+// ResourceReference is a reference to a Kubernetes resource in the namespace of a referring resource.
+type ResourceReference struct {
+	// APIVersion of a referenced resource.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Kind of a referenced resource.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Name of a referenced resource.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ConsumedEnvSpec defines an env consumed by ConsumesSpec.
+type ConsumedEnvSpec struct {
+	// Key is a name of a consumed key.
+	Key string `json:"key"`
+	// Optional indicates that the env is left out when its key is not resolved.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+	// Default is a value of the env when its key is not resolved, it implies the env is optional.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// ResolutionPolicy defines how consumed keys of an object are resolved through delegates.
+// +kubebuilder:validation:Enum=Reference;Key
+type ResolutionPolicy string
+
+const (
+	// ReferenceResolution takes all keys of a consumed object from the first delegate having all of them.
+	ReferenceResolution ResolutionPolicy = "Reference"
+	// KeyResolution resolves each key on its own from the first delegate having it.
+	KeyResolution ResolutionPolicy = "Key"
+)
+
+// ConsumesFailurePolicy defines what happens with consumed keys when some of them are not resolved.
+// +kubebuilder:validation:Enum=Clear;RetainLastKnownGood
+type ConsumesFailurePolicy string
+
+const (
+	// ClearOnFailure stops reconciling consumed keys until all of them are resolved.
+	ClearOnFailure ConsumesFailurePolicy = "Clear"
+	// RetainLastKnownGood keeps last known good values of not resolved keys in the consumed ConfigMap and Secret,
+	// and reports them by Degraded condition.
+	RetainLastKnownGood ConsumesFailurePolicy = "RetainLastKnownGood"
+)
+
+// ImportCase defines a rule to turn produced key names into env names.
+// +kubebuilder:validation:Enum=UpperSnake;Preserve
+type ImportCase string
+
+const (
+	// UpperSnakeCase turns key names into upper snake case, e.g. readReplica.host -> READ_REPLICA_HOST.
+	UpperSnakeCase ImportCase = "UpperSnake"
+	// PreserveCase keeps key names as is.
+	PreserveCase ImportCase = "Preserve"
+)
+
+// ImportSpec imports all keys produced by a consumed object as envs.
+type ImportSpec struct {
+	// Prefix is prepended to env names, e.g. DB_.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Case is a rule to turn key names into env names, defaults to UpperSnake.
+	// +optional
+	Case ImportCase `json:"case,omitempty"`
+}
+
+type ConsumesSpec struct {
+	// ResourceReference to a Tensegrity resource is being consumed.
+	ResourceReference `json:",inline"`
+	// Envs maps env names to consumed keys and their settings.
+	// +optional
+	Envs map[string]ConsumedEnvSpec `json:"envs,omitempty"`
+	// Import imports all keys produced by the object as envs, explicit Envs take precedence over imported ones.
+	// +optional
+	Import *ImportSpec `json:"import,omitempty"`
+	// Resolution defines how keys are resolved through delegates, defaults to Reference.
+	// +optional
+	Resolution ResolutionPolicy `json:"resolution,omitempty"`
+}
+
+// DelegateKind is a kind of a delegate.
+// +kubebuilder:validation:Enum=Namespace;NamespaceSelector;Cluster
+type DelegateKind string
+
+const (
+	// NamespaceDelegate resolves consumed keys from a namespace by name.
+	NamespaceDelegate DelegateKind = "Namespace"
+	// NamespaceSelectorDelegate resolves consumed keys from namespaces selected by labels.
+	NamespaceSelectorDelegate DelegateKind = "NamespaceSelector"
+	// ClusterDelegate resolves consumed keys from a namespace of a remote cluster.
+	ClusterDelegate DelegateKind = "Cluster"
+)
+
+// DelegateSpec is a Kubernetes resource used to resolve consumed keys.
+type DelegateSpec struct {
+	// Kind of a delegate.
+	Kind DelegateKind `json:"kind"`
+	// Name of a Namespace, or of a Secret with a kubeconfig in a resource namespace when kind is Cluster.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace of a remote cluster when kind is Cluster.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Selector selects namespaces by labels when kind is NamespaceSelector, selected namespaces
+	// are resolved in order of PriorityLabel label or annotation, higher first, and then by name.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type ConsumedKeyStatus struct {
+	// ObjectReference to a Tensegrity resource a key consumed from.
+	corev1.ObjectReference `json:",inline"`
+	// Delegate is a ObjectReference to a resource key is consumed from.
+	Delegate *corev1.ObjectReference `json:"delegate,omitempty"`
+	// Status of a key.
+	Status ConsumedStatus `json:"status"`
+	// Reason of a status.
+	Reason *string `json:"reason,omitempty"`
+	// Key is a name of a consumed key.
+	Key string `json:"key"`
+	// Env is a name of a consumed env.
+	Env string `json:"env"`
+}
+
+// TemplateSpec builds an env from consumed envs.
+type TemplateSpec struct {
+	// Env is a name of an env is being built.
+	Env string `json:"env"`
+	// Template is a Go template over consumed envs, e.g. postgres://{{ urlencode .DB_USER }}@{{ .DB_HOST }},
+	// functions urlencode, default, join and base64 are available.
+	// A result is sensitive and goes into the consumed Secret when any of used envs is sensitive.
+	Template string `json:"template"`
+}
+
+// AggregationMode defines how values of objects selected by ProducesSpec selector are combined into a key value.
+// +kubebuilder:validation:Enum=Join;JSON;First;Count
+type AggregationMode string
+
+const (
+	// JoinAggregation joins values with a separator.
+	JoinAggregation AggregationMode = "Join"
+	// JSONAggregation encodes values as a JSON array.
+	JSONAggregation AggregationMode = "JSON"
+	// FirstAggregation takes a value of the first object.
+	FirstAggregation AggregationMode = "First"
+	// CountAggregation counts selected objects, a value source is not used.
+	CountAggregation AggregationMode = "Count"
+)
+
+type ProducedStatus string
+
+const (
+	ProducedSuccess ProducedStatus = "Success"
+	ProducedFailure ProducedStatus = "Failure"
+)
+
+// ReadySpec defines when a source object is ready to produce a key,
+// by a status condition or by a CEL expression.
+type ReadySpec struct {
+	// ConditionType is a type of a status condition of a ready source object.
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+	// Status of the condition of a ready source object, defaults to True.
+	// +optional
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+	// Expression is a CEL expression evaluated against the source object available as `self`,
+	// the object is ready when the expression is true.
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// ProducesSourceSpec is a source of a produced key value.
+type ProducesSourceSpec struct {
+	// ResourceReference to a Kubernetes resource as a source of value of the key is being produced.
+	ResourceReference `json:",inline"`
+	// FieldPath is a JSONPath of a value in the referenced resource.
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
+	// Expression is a CEL expression evaluated against the referenced resource available as `self`,
+	// an alternative to FieldPath for values that need conditionals, defaults or string functions.
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// Selector selects resources by labels in place of Name, values of selected resources
+	// are ordered by name and combined according to Aggregation.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Aggregation defines how values of selected resources are combined, defaults to Join.
+	// +optional
+	Aggregation AggregationMode `json:"aggregation,omitempty"`
+	// Separator of values joined by Join aggregation, defaults to a comma.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+	// ReadyWhen holds the key back until the source object is ready,
+	// selected objects which are not ready are left out.
+	// +optional
+	ReadyWhen *ReadySpec `json:"readyWhen,omitempty"`
+}
+
+type ProducesSpec struct {
+	// Key is a name of a key is being produced.
+	Key string `json:"key"`
+	// Value is a literal value of the key, in place of a source.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// ProducesSourceSpec is a source of value of the key is being produced.
+	ProducesSourceSpec `json:",inline"`
+	// Fallbacks are sources tried in order when the source above does not exist or resolves to an empty value.
+	// +optional
+	Fallbacks []ProducesSourceSpec `json:"fallbacks,omitempty"`
+	// Sensitive indicates that the produced key value must be hidden and consumed as a Secret.
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
+	// Encoded indicates that the produced key value is already encoded and should be consumed as is.
+	// +optional
+	Encoded bool `json:"encoded,omitempty"`
+}
+
+// ProducedSourceStatus is a source of a produced key failed to resolve a value.
+type ProducedSourceStatus struct {
+	// ObjectReference to a Kubernetes resource a value was resolved from.
+	corev1.ObjectReference `json:",inline"`
+	// Selector of resources a value was aggregated from.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// Reason of a failure.
+	Reason string `json:"reason"`
+}
+
+type ProducedKeyStatus struct {
+	// ObjectReference to a Kubernetes resource a key produced from.
+	corev1.ObjectReference `json:",inline"`
+	// Selector of resources a key is aggregated from.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// Fallback is an index of a fallback source a key is produced from.
+	// +optional
+	Fallback *int32 `json:"fallback,omitempty"`
+	// FailedSources are sources tried before the one a key is produced from, with reasons they failed.
+	// +optional
+	FailedSources []ProducedSourceStatus `json:"failedSources,omitempty"`
+	// Status of a key.
+	Status ProducedStatus `json:"status"`
+	// Reason of a status.
+	Reason *string `json:"reason,omitempty"`
+	// Key is a name of a produced key.
+	Key string `json:"key"`
+	// Sensitive indicates that the produced key value must be hidden and represented as a Secret.
+	// +optional
+	Sensitive bool `json:"sensitive,omitempty"`
+	// Value of the key resolved from Kubernetes resource.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// ConsumerStatus is a resource consuming produced keys.
+type ConsumerStatus struct {
+	// ObjectReference to a consuming Tensegrity resource.
+	corev1.ObjectReference `json:",inline"`
+	// Delegate is a ObjectReference to a resource produced keys are consumed through.
+	Delegate corev1.ObjectReference `json:"delegate"`
+	// Keys are names of consumed keys.
+	Keys []string `json:"keys"`
+}
+
+// TensegritySpec is Tensegrity controller specs.
+type TensegritySpec struct {
+	// Delegates is a list of DelegateSpec to a Kubernetes resource used to resolve consumed keys,
+	// if empty defaults to a resource namespace.
+	// +optional
+	Delegates []DelegateSpec `json:"delegates,omitempty"`
+	// Consumes is a list of Tensegrity resources keys are consumed from.
+	// +optional
+	Consumes []ConsumesSpec `json:"consumes,omitempty"`
+	// Templates builds envs from consumed envs.
+	// +optional
+	Templates []TemplateSpec `json:"templates,omitempty"`
+	// ConsumesSecretName is name of a Secret is being generated by Tensegrity controller for consumed keys,
+	// defaults to <workload-name>-consumed.
+	// +optional
+	ConsumesSecretName string `json:"consumesSecretName,omitempty"`
+	// ConsumesConfigMapName is name of a ConfigMap is being generated by Tensegrity controller for consumed keys,
+	// defaults to <workload-name>-consumed.
+	// +optional
+	ConsumesConfigMapName string `json:"consumesConfigMapName,omitempty"`
+	// ConsumesFailurePolicy defines what happens with consumed keys when some of them are not resolved,
+	// defaults to Clear.
+	// +optional
+	ConsumesFailurePolicy ConsumesFailurePolicy `json:"consumesFailurePolicy,omitempty"`
+	// Produces is a list of keys and value sources to get from.
+	// +optional
+	Produces []ProducesSpec `json:"produces,omitempty"`
+	// ProducesSecretName is name of a Secret is being generated by Tensegrity controller for produced keys,
+	// defaults to <workload-name>-produced.
+	// +optional
+	ProducesSecretName string `json:"producesSecretName,omitempty"`
+	// ProducesConfigMapName is name of a ConfigMap is being generated by Tensegrity controller for produced keys,
+	// defaults to <workload-name>-produced.
+	// +optional
+	ProducesConfigMapName string `json:"producesConfigMapName,omitempty"`
+}
+
+// TensegrityStatus is Tensegrity controller status.
+type TensegrityStatus struct {
+	// Consumed indicates whether all keys were consumed.
+	Consumed *ConsumedStatus `json:"consumed,omitempty"`
+	// ConsumedKeys indicates consumed keys and their statuses.
+	ConsumedKeys []ConsumedKeyStatus `json:"consumedKeys,omitempty"`
+	// ConsumedSecretName is a name of a Secret with consumed environment variables and respective sensitive values
+	// programmatically generated for a workload by Tensegrity controller.
+	ConsumedSecretName string `json:"consumedSecretName,omitempty"`
+	// ConsumedConfigMapName is a name of a ConfigMap with resolved environment variables and respective values
+	// programmatically generated for a workload by Tensegrity controller.
+	ConsumedConfigMapName string `json:"consumedConfigMapName,omitempty"`
+	// Produced indicates whether all keys were produced.
+	Produced *ProducedStatus `json:"produced,omitempty"`
+	// ProducedKeys indicates produced keys and their statuses.
+	ProducedKeys []ProducedKeyStatus `json:"producedKeys,omitempty"`
+	// ProducedSecretName is a name of a Secret with produced keys and respective sensitive values
+	// programmatically generated for a workload by Tensegrity controller.
+	ProducedSecretName string `json:"producedSecretName,omitempty"`
+	// ProducedConfigMapName is a name of a Secret with produced keys and respective values
+	// programmatically generated for a workload by Tensegrity controller.
+	ProducedConfigMapName string `json:"producedConfigMapName,omitempty"`
+	// Consumers are resources of the same cluster consuming produced keys, ordered by namespace, kind and name.
+	// Resources consuming through Cluster delegates run in other clusters, so they are not listed.
+	// +optional
+	Consumers []ConsumerStatus `json:"consumers,omitempty"`
+	// ConsumerCount is a number of resources consuming produced keys, including ones not listed by Consumers.
+	// +optional
+	ConsumerCount int32 `json:"consumerCount,omitempty"`
+	// Conditions a list of conditions a tensegrity resource can have.
+	// +optional
+	Conditions []TensegrityCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the 'Generation' of the resource that
+	// was last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// TensegrityConditionType defines the conditions of Tensegrity resource.
+type TensegrityConditionType string
+
+const (
+	// TensegrityConsumed means keys are fully consumed and values are found.
+	TensegrityConsumed TensegrityConditionType = "Consumed"
+	// TensegrityProduced means keys are fully produced and values are found.
+	TensegrityProduced TensegrityConditionType = "Produced"
+	// TensegrityInvalid means spec is not valid.
+	TensegrityInvalid TensegrityConditionType = "Invalid"
+	// TensegrityDegraded means last known good values are retained for keys which are not consumed.
+	TensegrityDegraded TensegrityConditionType = "Degraded"
+	// TensegrityReady means spec is valid, keys are consumed and produced, and a child workload is rolled out.
+	TensegrityReady TensegrityConditionType = "Ready"
+	// TensegrityReconciling means the resource is not ready yet and is expected to become ready, present only when true.
+	TensegrityReconciling TensegrityConditionType = "Reconciling"
+	// TensegrityStalled means the resource can't become ready without a spec change, present only when true.
+	TensegrityStalled TensegrityConditionType = "Stalled"
+)
+
+type TensegrityCondition struct {
+	// Type of Tensegrity resource condition.
+	Type TensegrityConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastUpdateTime is the last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// LastTransitionTime is a time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Reason for the condition's last transition.
+	Reason string `json:"reason"`
+	// Message is a human-readable message indicating details about the transition.
+	Message string `json:"message"`
+}
@@ -0,0 +1,533 @@
+//go:build !ignore_autogenerated
+
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumedEnvSpec) DeepCopyInto(out *ConsumedEnvSpec) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumedEnvSpec.
+func (in *ConsumedEnvSpec) DeepCopy() *ConsumedEnvSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumedEnvSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumedKeyStatus) DeepCopyInto(out *ConsumedKeyStatus) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	if in.Delegate != nil {
+		in, out := &in.Delegate, &out.Delegate
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumedKeyStatus.
+func (in *ConsumedKeyStatus) DeepCopy() *ConsumedKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumedKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerStatus) DeepCopyInto(out *ConsumerStatus) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	out.Delegate = in.Delegate
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerStatus.
+func (in *ConsumerStatus) DeepCopy() *ConsumerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumesSpec) DeepCopyInto(out *ConsumesSpec) {
+	*out = *in
+	out.ResourceReference = in.ResourceReference
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make(map[string]ConsumedEnvSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(ImportSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumesSpec.
+func (in *ConsumesSpec) DeepCopy() *ConsumesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DelegateSpec) DeepCopyInto(out *DelegateSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DelegateSpec.
+func (in *DelegateSpec) DeepCopy() *DelegateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DelegateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportSpec) DeepCopyInto(out *ImportSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportSpec.
+func (in *ImportSpec) DeepCopy() *ImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducedKeyStatus) DeepCopyInto(out *ProducedKeyStatus) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedSources != nil {
+		in, out := &in.FailedSources, &out.FailedSources
+		*out = make([]ProducedSourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducedKeyStatus.
+func (in *ProducedKeyStatus) DeepCopy() *ProducedKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducedKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducedSourceStatus) DeepCopyInto(out *ProducedSourceStatus) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducedSourceStatus.
+func (in *ProducedSourceStatus) DeepCopy() *ProducedSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducedSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducesSourceSpec) DeepCopyInto(out *ProducesSourceSpec) {
+	*out = *in
+	out.ResourceReference = in.ResourceReference
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Separator != nil {
+		in, out := &in.Separator, &out.Separator
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReadyWhen != nil {
+		in, out := &in.ReadyWhen, &out.ReadyWhen
+		*out = new(ReadySpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducesSourceSpec.
+func (in *ProducesSourceSpec) DeepCopy() *ProducesSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducesSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducesSpec) DeepCopyInto(out *ProducesSpec) {
+	*out = *in
+	in.ProducesSourceSpec.DeepCopyInto(&out.ProducesSourceSpec)
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]ProducesSourceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducesSpec.
+func (in *ProducesSpec) DeepCopy() *ProducesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadySpec) DeepCopyInto(out *ReadySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadySpec.
+func (in *ReadySpec) DeepCopy() *ReadySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceReference.
+func (in *ResourceReference) DeepCopy() *ResourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Static) DeepCopyInto(out *Static) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Static.
+func (in *Static) DeepCopy() *Static {
+	if in == nil {
+		return nil
+	}
+	out := new(Static)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Static) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticList) DeepCopyInto(out *StaticList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Static, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticList.
+func (in *StaticList) DeepCopy() *StaticList {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StaticList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticSpec) DeepCopyInto(out *StaticSpec) {
+	*out = *in
+	in.TensegritySpec.DeepCopyInto(&out.TensegritySpec)
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SensitiveData != nil {
+		in, out := &in.SensitiveData, &out.SensitiveData
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SealedData != nil {
+		in, out := &in.SealedData, &out.SealedData
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticSpec.
+func (in *StaticSpec) DeepCopy() *StaticSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticStatus) DeepCopyInto(out *StaticStatus) {
+	*out = *in
+	in.TensegrityStatus.DeepCopyInto(&out.TensegrityStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticStatus.
+func (in *StaticStatus) DeepCopy() *StaticStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSpec) DeepCopyInto(out *TemplateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSpec.
+func (in *TemplateSpec) DeepCopy() *TemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TensegrityCondition) DeepCopyInto(out *TensegrityCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TensegrityCondition.
+func (in *TensegrityCondition) DeepCopy() *TensegrityCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(TensegrityCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TensegritySpec) DeepCopyInto(out *TensegritySpec) {
+	*out = *in
+	if in.Delegates != nil {
+		in, out := &in.Delegates, &out.Delegates
+		*out = make([]DelegateSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Consumes != nil {
+		in, out := &in.Consumes, &out.Consumes
+		*out = make([]ConsumesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]TemplateSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Produces != nil {
+		in, out := &in.Produces, &out.Produces
+		*out = make([]ProducesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TensegritySpec.
+func (in *TensegritySpec) DeepCopy() *TensegritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TensegritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TensegrityStatus) DeepCopyInto(out *TensegrityStatus) {
+	*out = *in
+	if in.Consumed != nil {
+		in, out := &in.Consumed, &out.Consumed
+		*out = new(ConsumedStatus)
+		**out = **in
+	}
+	if in.ConsumedKeys != nil {
+		in, out := &in.ConsumedKeys, &out.ConsumedKeys
+		*out = make([]ConsumedKeyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Produced != nil {
+		in, out := &in.Produced, &out.Produced
+		*out = new(ProducedStatus)
+		**out = **in
+	}
+	if in.ProducedKeys != nil {
+		in, out := &in.ProducedKeys, &out.ProducedKeys
+		*out = make([]ProducedKeyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]ConsumerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TensegrityCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TensegrityStatus.
+func (in *TensegrityStatus) DeepCopy() *TensegrityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TensegrityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
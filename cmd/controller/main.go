@@ -22,23 +22,44 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"reconciler.io/runtime/reconcilers"
 
 	apik8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apik8sv1beta1 "github.com/fastforgeinc/tensegrity/api/k8s/v1beta1"
 	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	apiv1beta1 "github.com/fastforgeinc/tensegrity/api/v1beta1"
+	controllerappsv1 "github.com/fastforgeinc/tensegrity/internal/controller/apps/v1"
 	controllerk8sv1alpha1 "github.com/fastforgeinc/tensegrity/internal/controller/k8s/v1alpha1"
 	controllerv1alpha1 "github.com/fastforgeinc/tensegrity/internal/controller/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/internal/sealing"
+	"github.com/fastforgeinc/tensegrity/internal/webhook/certs"
+	webhookcorev1 "github.com/fastforgeinc/tensegrity/internal/webhook/core/v1"
+	"github.com/fastforgeinc/tensegrity/internal/webhook/migration"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	//+kubebuilder:scaffold:imports
@@ -48,12 +69,25 @@ var (
 	scheme     = runtime.NewScheme()
 	setupLog   = ctrl.Log.WithName("setup")
 	syncPeriod = 1 * time.Hour
+
+	// customResourceDefinitions are CRDs served by the conversion webhook.
+	customResourceDefinitions = []string{
+		"deployments.k8s.tensegrity.fastforge.io",
+		"statefulsets.k8s.tensegrity.fastforge.io",
+		"daemonsets.k8s.tensegrity.fastforge.io",
+		"jobs.k8s.tensegrity.fastforge.io",
+		"cronjobs.k8s.tensegrity.fastforge.io",
+		"statics.tensegrity.fastforge.io",
+	}
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(apiv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(apik8sv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(apiv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apik8sv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -64,10 +98,48 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var enableWebhooks bool
+	var enableAnnotations bool
+	var showEventValues bool
+	var metricsSensitiveKeys bool
+	var sourceKinds string
+	var otlpEndpoint string
 	var certDir string
+	var manageWebhookCerts bool
+	var webhookCertSecret string
+	var webhookService string
+	var mutatingWebhookConfiguration string
+	var validatingWebhookConfiguration string
+	var migrateStorageVersions bool
+	var enableSealing bool
+	var sealingKeysSecret string
+	var sealingPublicKeyConfigMap string
+	var sealingKeyRotation time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&certDir, "cert-dir", "", "The directory that contains the server key and certificate.")
+	flag.BoolVar(&manageWebhookCerts, "manage-webhook-certs", false,
+		"If set, webhook serving certificates are generated, rotated and injected into webhook configurations "+
+			"by the controller instead of being read from --cert-dir")
+	flag.StringVar(&webhookCertSecret, "webhook-cert-secret", "tensegrity-webhook-server-cert",
+		"The Secret in the controller namespace managed webhook certificates are kept in.")
+	flag.StringVar(&webhookService, "webhook-service", "tensegrity-webhook-service",
+		"The Service in the controller namespace managed webhook certificates are issued for.")
+	flag.StringVar(&mutatingWebhookConfiguration, "mutating-webhook-configuration",
+		"tensegrity-mutating-webhook-configuration",
+		"The MutatingWebhookConfiguration the CA of managed webhook certificates is injected into.")
+	flag.StringVar(&validatingWebhookConfiguration, "validating-webhook-configuration",
+		"tensegrity-validating-webhook-configuration",
+		"The ValidatingWebhookConfiguration the CA of managed webhook certificates is injected into.")
+	flag.BoolVar(&enableSealing, "enable-sealing", true,
+		"If set, Statics can produce keys from values sealed with a public key of the controller.")
+	flag.StringVar(&sealingKeysSecret, "sealing-keys-secret", "tensegrity-sealing-keys",
+		"The Secret private keys of sealed values are kept in, in the controller namespace.")
+	flag.StringVar(&sealingPublicKeyConfigMap, "sealing-public-key-config-map", "tensegrity-sealing-public-key",
+		"The ConfigMap the current public key of sealed values is published in, in the controller namespace.")
+	flag.DurationVar(&sealingKeyRotation, "sealing-key-rotation", sealing.DefaultRotationPeriod,
+		"An age of the current sealing key a new key is added at, previous keys are kept. Zero never adds keys.")
+	flag.BoolVar(&migrateStorageVersions, "migrate-storage-versions", true,
+		"If set along with webhooks, resources stored in previous API versions are rewritten in the storage version.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -76,6 +148,18 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "If set, webhook validation will be enabled")
+	flag.BoolVar(&enableAnnotations, "enable-annotations", false,
+		"If set, native apps/v1 workloads annotated with "+apiv1alpha1.SpecAnnotation+" consume keys")
+	flag.BoolVar(&showEventValues, "events-show-values", false,
+		"If set, events of changed keys show values which are not sensitive")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The OTLP/HTTP endpoint URL reconcile traces are exported to, e.g. http://otel-collector:4318. "+
+			"Tracing is disabled if empty.")
+	flag.BoolVar(&metricsSensitiveKeys, "metrics-sensitive-keys", false,
+		"If set, metrics of failed keys are labeled with names of sensitive keys")
+	flag.StringVar(&sourceKinds, "source-kinds", formatSourceKinds(controllerv1alpha1.DefaultSourceKinds),
+		"Comma separated kinds with groups, e.g. Deployment.apps, of source objects selectors and readyWhen "+
+			"may refer to, objects of these kinds are watched in all namespaces. Any kind is allowed with *.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -107,6 +191,7 @@ func main() {
 		LeaderElectionReleaseOnCancel: true,
 	}
 
+	var certRotator *certs.Rotator
 	if enableWebhooks {
 		webhookOptions := webhook.Options{
 			TLSOpts: tlsOpts,
@@ -114,6 +199,16 @@ func main() {
 		if len(certDir) > 0 {
 			webhookOptions.CertDir = certDir
 		}
+		if manageWebhookCerts {
+			namespace := certs.InClusterNamespace("tensegrity")
+			certRotator = certs.NewRotator(nil, nil,
+				types.NamespacedName{Namespace: namespace, Name: webhookCertSecret},
+				certs.ServiceDNSNames(webhookService, namespace)...)
+			certRotator.MutatingWebhookConfigurations = []string{mutatingWebhookConfiguration}
+			certRotator.ValidatingWebhookConfigurations = []string{validatingWebhookConfiguration}
+			certRotator.CustomResourceDefinitions = customResourceDefinitions
+			webhookOptions.TLSOpts = append(slices.Clone(tlsOpts), certRotator.ConfigureTLS)
+		}
 
 		webhookServer := webhook.NewServer(webhookOptions)
 		mgrOptions.WebhookServer = webhookServer
@@ -126,14 +221,36 @@ func main() {
 	}
 
 	ctx := context.Background()
+	if len(otlpEndpoint) > 0 {
+		tracerProvider, err := newTracerProvider(ctx, otlpEndpoint)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		otel.SetTracerProvider(tracerProvider)
+		if err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return tracerProvider.Shutdown(context.Background())
+		})); err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+	}
+
 	config := reconcilers.NewConfig(mgr, nil, syncPeriod)
 	validationReconciler := controllerv1alpha1.NewValidationReconciler()
 	consumerReconciler := controllerv1alpha1.NewConsumerReconciler()
+	consumerReconciler.ShowEventValues = showEventValues
+	consumerReconciler.MetricsSensitiveKeys = metricsSensitiveKeys
 	consumerSecretReconciler := controllerv1alpha1.NewConsumerSecretReconciler()
 	consumerConfigMapReconciler := controllerv1alpha1.NewConsumerConfigMapReconciler()
 	producerReconciler := controllerv1alpha1.NewProducerReconciler()
+	producerReconciler.ShowEventValues = showEventValues
+	producerReconciler.MetricsSensitiveKeys = metricsSensitiveKeys
+	producerReconciler.SourceKinds = parseSourceKinds(sourceKinds)
 	producerSecretReconciler := controllerv1alpha1.NewProducerSecretReconciler()
 	producerConfigMapReconciler := controllerv1alpha1.NewProducerConfigMapReconciler()
+	statusReconciler := controllerv1alpha1.NewStatusReconciler()
 
 	if err = controllerk8sv1alpha1.NewDeploymentReconciler(
 		&config,
@@ -143,7 +260,8 @@ func main() {
 		consumerConfigMapReconciler,
 		producerReconciler,
 		producerSecretReconciler,
-		producerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+		producerConfigMapReconciler,
+		statusReconciler).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Deployment", "version", "k8s/v1alpha1")
 		os.Exit(1)
 	}
@@ -155,7 +273,8 @@ func main() {
 		consumerConfigMapReconciler,
 		producerReconciler,
 		producerSecretReconciler,
-		producerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+		producerConfigMapReconciler,
+		statusReconciler).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "StatefulSet", "version", "k8s/v1alpha1")
 		os.Exit(1)
 	}
@@ -167,19 +286,103 @@ func main() {
 		consumerConfigMapReconciler,
 		producerReconciler,
 		producerSecretReconciler,
-		producerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+		producerConfigMapReconciler,
+		statusReconciler).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DaemonSet", "version", "k8s/v1alpha1")
 		os.Exit(1)
 	}
+	if err = controllerk8sv1alpha1.NewJobReconciler(
+		&config,
+		validationReconciler,
+		consumerReconciler,
+		consumerSecretReconciler,
+		consumerConfigMapReconciler,
+		producerReconciler,
+		producerSecretReconciler,
+		producerConfigMapReconciler,
+		statusReconciler).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Job", "version", "k8s/v1alpha1")
+		os.Exit(1)
+	}
+	if err = controllerk8sv1alpha1.NewCronJobReconciler(
+		&config,
+		validationReconciler,
+		consumerReconciler,
+		consumerSecretReconciler,
+		consumerConfigMapReconciler,
+		producerReconciler,
+		producerSecretReconciler,
+		producerConfigMapReconciler,
+		statusReconciler).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CronJob", "version", "k8s/v1alpha1")
+		os.Exit(1)
+	}
+	var sealedKeys controllerv1alpha1.SealedKeys
+	if enableSealing {
+		namespace := certs.InClusterNamespace("tensegrity")
+		keyManager := sealing.NewKeyManager(nil, nil,
+			types.NamespacedName{Namespace: namespace, Name: sealingKeysSecret},
+			types.NamespacedName{Namespace: namespace, Name: sealingPublicKeyConfigMap})
+		keyManager.RotationPeriod = sealingKeyRotation
+		if err = keyManager.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up sealing keys")
+			os.Exit(1)
+		}
+		sealedKeys = keyManager
+	}
 	if err = controllerv1alpha1.NewStaticReconciler(
 		&config,
 		validationReconciler,
+		controllerv1alpha1.NewStaticDataReconciler(sealedKeys),
+		consumerReconciler,
+		consumerSecretReconciler,
+		consumerConfigMapReconciler,
 		producerReconciler,
 		producerSecretReconciler,
-		producerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+		producerConfigMapReconciler,
+		statusReconciler).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Static", "version", "v1alpha1")
 		os.Exit(1)
 	}
+	if enableAnnotations {
+		annotationReconciler := controllerv1alpha1.NewAnnotationReconciler()
+		if err = controllerappsv1.NewDeploymentReconciler(
+			&config,
+			annotationReconciler,
+			validationReconciler,
+			consumerReconciler,
+			consumerSecretReconciler,
+			consumerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Deployment", "version", "apps/v1")
+			os.Exit(1)
+		}
+		if err = controllerappsv1.NewStatefulSetReconciler(
+			&config,
+			annotationReconciler,
+			validationReconciler,
+			consumerReconciler,
+			consumerSecretReconciler,
+			consumerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "StatefulSet", "version", "apps/v1")
+			os.Exit(1)
+		}
+		if err = controllerappsv1.NewDaemonSetReconciler(
+			&config,
+			annotationReconciler,
+			validationReconciler,
+			consumerReconciler,
+			consumerSecretReconciler,
+			consumerConfigMapReconciler).SetupWithManager(ctx, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "DaemonSet", "version", "apps/v1")
+			os.Exit(1)
+		}
+	}
+	if certRotator != nil {
+		if err = certRotator.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up webhook certificates")
+			os.Exit(1)
+		}
+	}
 	if enableWebhooks {
 		if err = new(apik8sv1alpha1.Deployment).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Deployment")
@@ -193,13 +396,49 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "DaemonSet")
 			os.Exit(1)
 		}
+		if err = new(apik8sv1alpha1.Job).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Job")
+			os.Exit(1)
+		}
+		if err = new(apik8sv1alpha1.CronJob).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CronJob")
+			os.Exit(1)
+		}
 		if err = new(apiv1alpha1.Static).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Static")
 			os.Exit(1)
 		}
+		if err = webhookcorev1.SetupPodWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+		if migrateStorageVersions {
+			if err = migration.NewStorageVersionMigrator(nil, nil,
+				customResourceDefinitions...).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to set up storage version migration")
+				os.Exit(1)
+			}
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
+	keysCollector := controllerv1alpha1.NewKeysCollector(mgr.GetClient(), mgr.GetCache(),
+		new(apik8sv1alpha1.DeploymentList),
+		new(apik8sv1alpha1.StatefulSetList),
+		new(apik8sv1alpha1.DaemonSetList),
+		new(apik8sv1alpha1.JobList),
+		new(apik8sv1alpha1.CronJobList),
+		new(apiv1alpha1.StaticList))
+	if enableAnnotations {
+		keysCollector.Forgotten = []client.Object{
+			new(appsv1.Deployment), new(appsv1.StatefulSet), new(appsv1.DaemonSet)}
+	}
+	metrics.Registry.MustRegister(keysCollector)
+	if err := mgr.Add(keysCollector); err != nil {
+		setupLog.Error(err, "unable to set up metrics of deleted resources")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -215,3 +454,36 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newTracerProvider creates a tracer provider exporting spans in batches to an OTLP/HTTP endpoint.
+func newTracerProvider(ctx context.Context, endpoint string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(sdkresource.NewSchemaless(
+			attribute.String("service.name", "tensegrity-controller"))),
+	), nil
+}
+
+// formatSourceKinds formats kinds as a value of the source-kinds flag.
+func formatSourceKinds(kinds []schema.GroupKind) string {
+	values := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		values = append(values, kind.String())
+	}
+	return strings.Join(values, ",")
+}
+
+// parseSourceKinds parses a value of the source-kinds flag.
+func parseSourceKinds(value string) []schema.GroupKind {
+	var kinds []schema.GroupKind
+	for _, kind := range strings.Split(value, ",") {
+		if kind = strings.TrimSpace(kind); len(kind) > 0 {
+			kinds = append(kinds, schema.ParseGroupKind(kind))
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,115 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+// tensegrity-seal seals a value of a key of a Static with a public key of Tensegrity controller, the sealed value
+// goes into sealedData of the Static and is opened only by the controller. The value is read from stdin:
+//
+//	echo -n 'P@33W0RD' | tensegrity-seal --namespace default --name postgres --key password
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fastforgeinc/tensegrity/internal/sealing"
+)
+
+func main() {
+	var namespace string
+	var name string
+	var key string
+	var publicKeyFile string
+	var controllerNamespace string
+	var publicKeyConfigMap string
+	flag.StringVar(&namespace, "namespace", "default", "The namespace of the Static.")
+	flag.StringVar(&name, "name", "", "The name of the Static.")
+	flag.StringVar(&key, "key", "", "The key of sealedData of the Static.")
+	flag.StringVar(&publicKeyFile, "public-key", "",
+		"A PEM file of the public key, if not set it is read from the cluster.")
+	flag.StringVar(&controllerNamespace, "controller-namespace", "tensegrity",
+		"The namespace of Tensegrity controller the public key is read from.")
+	flag.StringVar(&publicKeyConfigMap, "public-key-config-map", "tensegrity-sealing-public-key",
+		"The ConfigMap the public key is read from.")
+	flag.Parse()
+
+	if err := run(namespace, name, key, publicKeyFile,
+		types.NamespacedName{Namespace: controllerNamespace, Name: publicKeyConfigMap}); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(namespace, name, key, publicKeyFile string, configMap types.NamespacedName) error {
+	if len(name) == 0 || len(key) == 0 {
+		return errors.New("--name and --key are required")
+	}
+	data, err := readPublicKey(publicKeyFile, configMap)
+	if err != nil {
+		return err
+	}
+	publicKey, err := sealing.ParsePublicKey(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse public key")
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return errors.Wrap(err, "failed to read value")
+	}
+	sealed, err := sealing.Seal(publicKey, sealing.Scope{Namespace: namespace, Name: name, Key: key},
+		strings.TrimSuffix(string(value), "\n"))
+	if err != nil {
+		return errors.Wrap(err, "failed to seal value")
+	}
+	fmt.Println(sealed)
+	return nil
+}
+
+// readPublicKey reads the public key from a file, or from the ConfigMap it is published in by the controller.
+func readPublicKey(publicKeyFile string, configMap types.NamespacedName) ([]byte, error) {
+	if len(publicKeyFile) > 0 {
+		return os.ReadFile(publicKeyFile)
+	}
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(config, client.Options{Scheme: clientgoscheme.Scheme})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	obj := new(corev1.ConfigMap)
+	if err = c.Get(ctx, configMap, obj); err != nil {
+		return nil, errors.Wrapf(err, "failed to read public key of ConfigMap %s", configMap)
+	}
+	return []byte(obj.Data[sealing.PublicKeyKey]), nil
+}
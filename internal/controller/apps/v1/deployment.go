@@ -0,0 +1,111 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+
+	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/internal/controller/v1alpha1"
+)
+
+func NewDeploymentReconciler(
+	config *reconcilers.Config,
+	annotationReconciler *v1alpha1.AnnotationReconciler,
+	validationReconciler *v1alpha1.ValidationReconciler,
+	consumerReconciler *v1alpha1.ConsumerReconciler,
+	consumerSecretReconciler *v1alpha1.ConsumerSecretReconciler,
+	consumerConfigMapReconciler *v1alpha1.ConsumerConfigMapReconciler) *DeploymentReconciler {
+
+	return &DeploymentReconciler{
+		Name: "AppsDeploymentReconciler",
+		Setup: func(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
+			// named explicitly to not clash with k8s.tensegrity.fastforge.io Deployment controller
+			builder.Named("apps-deployment")
+			builder.WithEventFilter(v1alpha1.AnnotatedPredicate[*appsv1.Deployment]())
+			builder.Watches(new(apiv1alpha1.Static), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.CronJob), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.DaemonSet), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Deployment), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Job), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.StatefulSet), reconcilers.EnqueueTracked(ctx))
+			return nil
+		},
+		Config:           *config,
+		SkipStatusUpdate: true,
+		Reconciler: v1alpha1.TraceSequence(reconcilers.Sequence[*appsv1.Deployment]{
+			&reconcilers.CastResource[*appsv1.Deployment, *apiv1alpha1.Tensegrity]{
+				Reconciler: v1alpha1.TraceSequence(reconcilers.Sequence[*apiv1alpha1.Tensegrity]{
+					annotationReconciler,
+					validationReconciler,
+					consumerReconciler,
+				}),
+			},
+			&reconcilers.CastResource[*appsv1.Deployment, *metav1.PartialObjectMetadata]{
+				Reconciler: consumerSecretReconciler,
+			},
+			&reconcilers.CastResource[*appsv1.Deployment, *metav1.PartialObjectMetadata]{
+				Reconciler: consumerConfigMapReconciler,
+			},
+			NewDeploymentInjectionReconciler(),
+		}),
+	}
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+
+// DeploymentReconciler reconciles native k8s.io/api/apps/v1.Deployment resource annotated with
+// tensegrity.fastforge.io/spec, and runs sequence of consumer reconcilers to get desired workload.
+type DeploymentReconciler = reconcilers.ResourceReconciler[*appsv1.Deployment]
+
+func NewDeploymentInjectionReconciler() *DeploymentInjectionReconciler {
+	r := new(DeploymentInjectionReconciler)
+	r.deploymentInjectionReconciler = deploymentInjectionReconciler{
+		Name: "DeploymentInjectionReconciler",
+		Sync: r.Sync,
+	}
+	return r
+}
+
+// DeploymentInjectionReconciler updates existing k8s.io/api/apps/v1.Deployment with consumed
+// ConfigMap and Secret, instead of creating a child.
+type DeploymentInjectionReconciler struct {
+	deploymentInjectionReconciler
+}
+
+func (r *DeploymentInjectionReconciler) Sync(ctx context.Context, resource *appsv1.Deployment) error {
+	desired := resource.DeepCopy()
+	if !v1alpha1.InjectConsumed(ctx, &desired.ObjectMeta, &desired.Spec.Template) {
+		return nil
+	}
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	if err := config.Update(ctx, desired); err != nil {
+		return err
+	}
+	v1alpha1.RecordRollout(ctx, resource)
+	return nil
+}
+
+type deploymentInjectionReconciler = reconcilers.SyncReconciler[*appsv1.Deployment]
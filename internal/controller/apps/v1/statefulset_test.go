@@ -0,0 +1,149 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/internal/controller/v1alpha1"
+)
+
+var _ = Describe("StatefulSet Controller", func() {
+	Context("When reconciling an annotated resource", func() {
+		const resourceName = "test-statefulset"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		consumedEnvFrom := corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: resourceName + "-consumed"}},
+		}
+		var static *apiv1alpha1.Static
+
+		BeforeEach(func() {
+			By("creating the annotated resource for the Kind StatefulSet")
+			static = createStatic(ctx, resourceName+"-postgres", map[string]string{"host": "postgres.testing"})
+			resource := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					Annotations: map[string]string{
+						apiv1alpha1.SpecAnnotation: `
+consumes:
+  - apiVersion: tensegrity.fastforge.io/v1alpha1
+    kind: Static
+    name: ` + static.Name + `
+    maps:
+      DATABASE_HOST: host
+`,
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": resourceName},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": resourceName},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "test", Image: "test"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance StatefulSet")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteStatic(ctx, static)
+		})
+
+		It("should inject consumed keys and remove them with the annotation", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := NewStatefulSetReconciler(
+				reconcilerConfig, annotationReconciler, validationReconciler,
+				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler)
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Spec.Template.Spec.Containers[0].EnvFrom).To(ConsistOf(consumedEnvFrom))
+			Expect(resource.Annotations).To(
+				HaveKeyWithValue(apiv1alpha1.InjectedAnnotation, "ConfigMap/"+resourceName+"-consumed"))
+			Expect(resource.Spec.Template.Annotations).To(
+				HaveKey("tensegrity.fastforge.io/consumerConfigMapVersion"))
+
+			By("Removing the annotation")
+			delete(resource.Annotations, apiv1alpha1.SpecAnnotation)
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Spec.Template.Spec.Containers[0].EnvFrom).To(BeEmpty())
+			Expect(resource.Annotations).NotTo(HaveKey(apiv1alpha1.InjectedAnnotation))
+			Expect(resource.Spec.Template.Annotations).NotTo(
+				HaveKey("tensegrity.fastforge.io/consumerConfigMapVersion"))
+		})
+	})
+
+	Context("When filtering events", func() {
+		It("should pass only annotated or injected resources", func() {
+			p := v1alpha1.AnnotatedPredicate[*appsv1.StatefulSet]()
+			unannotated := &appsv1.StatefulSet{}
+			annotated := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{apiv1alpha1.SpecAnnotation: "{}"}}}
+			injected := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{apiv1alpha1.InjectedAnnotation: "ConfigMap/test"}}}
+
+			Expect(p.Create(event.CreateEvent{Object: unannotated})).To(BeFalse())
+			Expect(p.Create(event.CreateEvent{Object: annotated})).To(BeTrue())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: unannotated, ObjectNew: unannotated})).To(BeFalse())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: annotated, ObjectNew: unannotated})).To(BeTrue())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: injected, ObjectNew: injected})).To(BeTrue())
+			Expect(p.Create(event.CreateEvent{Object: &apiv1alpha1.Static{}})).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("CronJob Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-cronjob"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		var static *apiv1alpha1.Static
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind CronJob")
+			static = createStatic(ctx, resourceName+"-postgres", map[string]string{"host": "postgres.testing"})
+			resource := &k8sv1alpha1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: k8sv1alpha1.CronJobSpec{
+					CronJobSpec: batchv1.CronJobSpec{
+						Schedule: "* * * * *",
+						JobTemplate: batchv1.JobTemplateSpec{
+							Spec: batchv1.JobSpec{
+								Template: corev1.PodTemplateSpec{
+									Spec: corev1.PodSpec{
+										RestartPolicy: corev1.RestartPolicyNever,
+										Containers:    []corev1.Container{{Name: "report", Image: "busybox"}},
+									},
+								},
+							},
+						},
+					},
+					TensegritySpec: consumesStatic(resourceName, static, map[string]string{"POSTGRES_HOST": "host"}),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &k8sv1alpha1.CronJob{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance CronJob")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteStatic(ctx, static)
+		})
+
+		It("should inject consumed keys into the job template", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := NewCronJobReconciler(
+				reconcilerConfig, validationReconciler,
+				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			consumed := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-consumed", Namespace: "default"}, consumed)).To(Succeed())
+			Expect(consumed.Data).To(HaveKeyWithValue("POSTGRES_HOST", "postgres.testing"))
+
+			child := &batchv1.CronJob{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+			Expect(child.Spec.Schedule).To(Equal("* * * * *"))
+			container := child.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+			Expect(container.EnvFrom).To(ConsistOf(corev1.EnvFromSource{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: resourceName + "-consumed"}},
+			}))
+			Expect(child.Spec.JobTemplate.Spec.Template.Annotations).To(
+				HaveKey("tensegrity.fastforge.io/consumerConfigMapVersion"))
+
+			resource := &k8sv1alpha1.CronJob{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ConsumedConfigMapName).To(Equal(resourceName + "-consumed"))
+			Expect(resource.Status.ConsumedKeys).To(HaveLen(1))
+			Expect(resource.Status.ConsumedKeys[0].Status).To(Equal(apiv1alpha1.ConsumedSuccess))
+		})
+	})
+})
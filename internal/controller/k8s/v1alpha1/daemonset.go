@@ -41,42 +41,52 @@ func NewDaemonSetReconciler(
 	consumerConfigMapReconciler *v1alpha1.ConsumerConfigMapReconciler,
 	producerReconciler *v1alpha1.ProducerReconciler,
 	producerSecretReconciler *v1alpha1.ProducerSecretReconciler,
-	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler) *DaemonSetReconciler {
+	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler,
+	statusReconciler *v1alpha1.StatusReconciler) *DaemonSetReconciler {
 
 	return &DaemonSetReconciler{
 		Name: "DaemonSetReconciler",
 		Setup: func(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
 			builder.Watches(new(apiv1alpha1.Static), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.CronJob), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.DaemonSet), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.Deployment), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Job), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.StatefulSet), reconcilers.EnqueueTracked(ctx))
 			return nil
 		},
 		Config: *config,
-		Reconciler: reconcilers.Sequence[*k8sv1alpha1.DaemonSet]{
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
-				Reconciler: validationReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
-				Reconciler: consumerReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
-				Reconciler: consumerSecretReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
-				Reconciler: consumerConfigMapReconciler,
-			},
-			NewDaemonSetChildReconciler(),
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
-				Reconciler: producerReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
-				Reconciler: producerSecretReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
-				Reconciler: producerConfigMapReconciler,
-			},
-		},
+		Reconciler: v1alpha1.Trace[*k8sv1alpha1.DaemonSet](&reconcilers.TryCatch[*k8sv1alpha1.DaemonSet]{
+			Name: "DaemonSetReconciler",
+			Try: v1alpha1.TraceSequence(reconcilers.Sequence[*k8sv1alpha1.DaemonSet]{
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
+					Reconciler: validationReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
+					Reconciler: consumerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerConfigMapReconciler,
+				},
+				NewDaemonSetChildReconciler(),
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
+					Reconciler: producerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
+					Reconciler: producerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *metav1.PartialObjectMetadata]{
+					Reconciler: producerConfigMapReconciler,
+				},
+			}),
+			Catch: v1alpha1.CatchReconcileError[*k8sv1alpha1.DaemonSet],
+			Finally: v1alpha1.Trace[*k8sv1alpha1.DaemonSet](&reconcilers.CastResource[*k8sv1alpha1.DaemonSet, *apiv1alpha1.Tensegrity]{
+				Reconciler: statusReconciler,
+			}),
+		}),
 	}
 }
 
@@ -94,9 +104,7 @@ func NewDaemonSetChildReconciler() *DaemonSetChildReconciler {
 		Name:                       "DaemonSetChildReconciler",
 		DesiredChild:               r.DesiredChild,
 		ReflectChildStatusOnParent: r.ReflectChildStatusOnParent,
-		ChildObjectManager: &reconcilers.UpdatingObjectManager[*appsv1.DaemonSet]{
-			MergeBeforeUpdate: r.MergeBeforeUpdate,
-		},
+		ChildObjectManager:         new(v1alpha1.ApplyingObjectManager[*appsv1.DaemonSet]),
 	}
 	return r
 }
@@ -171,13 +179,27 @@ func (r *DaemonSetChildReconciler) DesiredChild(
 	return child, nil
 }
 
-func (r *DaemonSetChildReconciler) MergeBeforeUpdate(current, desired *appsv1.DaemonSet) {
-	current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
-	current.Labels = desired.Labels
-}
-
+// ReflectChildStatusOnParent mirrors scheduled pods and conditions of the child DaemonSet,
+// and stashes its rollout progress, the last observed status is kept when the child is failed to reconcile.
 func (r *DaemonSetChildReconciler) ReflectChildStatusOnParent(
-	_ context.Context, _ *k8sv1alpha1.DaemonSet, _ *appsv1.DaemonSet, _ error) {
+	ctx context.Context, parent *k8sv1alpha1.DaemonSet, child *appsv1.DaemonSet, err error) {
+
+	if err != nil {
+		v1alpha1.StashWorkloadRollout(ctx, err.Error())
+		return
+	}
+	parent.Status = k8sv1alpha1.DaemonSetStatus{TensegrityStatus: parent.Status.TensegrityStatus}
+	if child == nil {
+		return
+	}
+	v1alpha1.RecordRollout(ctx, parent)
+	v1alpha1.StashWorkloadRollout(ctx, daemonSetRollout(child))
+	parent.Status.DesiredNumberScheduled = child.Status.DesiredNumberScheduled
+	parent.Status.CurrentNumberScheduled = child.Status.CurrentNumberScheduled
+	parent.Status.NumberReady = child.Status.NumberReady
+	parent.Status.UpdatedNumberScheduled = child.Status.UpdatedNumberScheduled
+	parent.Status.NumberAvailable = child.Status.NumberAvailable
+	parent.Status.WorkloadConditions = child.Status.Conditions
 }
 
 type daemonSetChildReconciler = reconcilers.ChildReconciler[
@@ -83,7 +83,8 @@ var _ = Describe("DaemonSet Controller", func() {
 			controllerReconciler := NewDaemonSetReconciler(
 				reconcilerConfig, validationReconciler,
 				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
-				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance)
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
@@ -92,4 +93,75 @@ var _ = Describe("DaemonSet Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When mirroring the child DaemonSet status", func() {
+		const resourceName = "test-daemonset-status"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &k8sv1alpha1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: k8sv1alpha1.DaemonSetSpec{
+					DaemonSetSpec: appsv1.DaemonSetSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": resourceName}},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": resourceName}},
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "agent", Image: "busybox"}},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &k8sv1alpha1.DaemonSet{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should mirror scheduled pods, and report the rollout", func() {
+			controllerReconciler := NewDaemonSetReconciler(
+				reconcilerConfig, validationReconciler,
+				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			reconcileDaemonSet := func() *k8sv1alpha1.DaemonSet {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				resource := &k8sv1alpha1.DaemonSet{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				return resource
+			}
+
+			reconcileDaemonSet()
+			child := &appsv1.DaemonSet{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+			child.Status = appsv1.DaemonSetStatus{
+				ObservedGeneration:     child.Generation,
+				DesiredNumberScheduled: 3,
+				CurrentNumberScheduled: 3,
+				NumberReady:            3,
+				UpdatedNumberScheduled: 3,
+				NumberAvailable:        2,
+			}
+			Expect(k8sClient.Status().Update(ctx, child)).To(Succeed())
+
+			resource := reconcileDaemonSet()
+			Expect(resource.Status.DesiredNumberScheduled).To(BeEquivalentTo(3))
+			Expect(resource.Status.NumberReady).To(BeEquivalentTo(3))
+			Expect(resource.Status.NumberAvailable).To(BeEquivalentTo(2))
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionFalse, "2 of 3 updated pods are available")
+
+			child.Status.NumberAvailable = 3
+			Expect(k8sClient.Status().Update(ctx, child)).To(Succeed())
+
+			resource = reconcileDaemonSet()
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionTrue, "")
+		})
+	})
 })
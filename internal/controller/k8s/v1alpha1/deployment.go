@@ -41,42 +41,52 @@ func NewDeploymentReconciler(
 	consumerConfigMapReconciler *v1alpha1.ConsumerConfigMapReconciler,
 	producerReconciler *v1alpha1.ProducerReconciler,
 	producerSecretReconciler *v1alpha1.ProducerSecretReconciler,
-	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler) *DeploymentReconciler {
+	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler,
+	statusReconciler *v1alpha1.StatusReconciler) *DeploymentReconciler {
 
 	return &DeploymentReconciler{
 		Name: "DeploymentReconciler",
 		Setup: func(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
 			builder.Watches(new(apiv1alpha1.Static), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.CronJob), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.DaemonSet), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.Deployment), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Job), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.StatefulSet), reconcilers.EnqueueTracked(ctx))
 			return nil
 		},
 		Config: *config,
-		Reconciler: reconcilers.Sequence[*k8sv1alpha1.Deployment]{
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
-				Reconciler: validationReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
-				Reconciler: consumerReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
-				Reconciler: consumerSecretReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
-				Reconciler: consumerConfigMapReconciler,
-			},
-			NewDeploymentChildReconciler(),
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
-				Reconciler: producerReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
-				Reconciler: producerSecretReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
-				Reconciler: producerConfigMapReconciler,
-			},
-		},
+		Reconciler: v1alpha1.Trace[*k8sv1alpha1.Deployment](&reconcilers.TryCatch[*k8sv1alpha1.Deployment]{
+			Name: "DeploymentReconciler",
+			Try: v1alpha1.TraceSequence(reconcilers.Sequence[*k8sv1alpha1.Deployment]{
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
+					Reconciler: validationReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
+					Reconciler: consumerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerConfigMapReconciler,
+				},
+				NewDeploymentChildReconciler(),
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
+					Reconciler: producerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
+					Reconciler: producerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Deployment, *metav1.PartialObjectMetadata]{
+					Reconciler: producerConfigMapReconciler,
+				},
+			}),
+			Catch: v1alpha1.CatchReconcileError[*k8sv1alpha1.Deployment],
+			Finally: v1alpha1.Trace[*k8sv1alpha1.Deployment](&reconcilers.CastResource[*k8sv1alpha1.Deployment, *apiv1alpha1.Tensegrity]{
+				Reconciler: statusReconciler,
+			}),
+		}),
 	}
 }
 
@@ -94,8 +104,8 @@ func NewDeploymentChildReconciler() *DeploymentChildReconciler {
 		Name:                       "DeploymentChildReconciler",
 		DesiredChild:               r.DesiredChild,
 		ReflectChildStatusOnParent: r.ReflectChildStatusOnParent,
-		ChildObjectManager: &reconcilers.UpdatingObjectManager[*appsv1.Deployment]{
-			MergeBeforeUpdate: r.MergeBeforeUpdate,
+		ChildObjectManager: &v1alpha1.ApplyingObjectManager[*appsv1.Deployment]{
+			MergeBeforeApply: r.MergeBeforeApply,
 		},
 	}
 	return r
@@ -180,13 +190,34 @@ func (r *DeploymentChildReconciler) DesiredChild(
 	return child, nil
 }
 
-func (r *DeploymentChildReconciler) MergeBeforeUpdate(current, desired *appsv1.Deployment) {
-	current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
-	current.Labels = desired.Labels
+// MergeBeforeApply leaves replicas to a HorizontalPodAutoscaler or another controller
+// scaling the child Deployment through the scale subresource.
+func (r *DeploymentChildReconciler) MergeBeforeApply(actual, desired *appsv1.Deployment) {
+	if v1alpha1.IsManagedBySubresource(actual, "scale", "spec", "replicas") {
+		desired.Spec.Replicas = nil
+	}
 }
 
+// ReflectChildStatusOnParent mirrors replicas and conditions of the child Deployment,
+// and stashes its rollout progress, the last observed status is kept when the child is failed to reconcile.
 func (r *DeploymentChildReconciler) ReflectChildStatusOnParent(
-	_ context.Context, _ *k8sv1alpha1.Deployment, _ *appsv1.Deployment, _ error) {
+	ctx context.Context, parent *k8sv1alpha1.Deployment, child *appsv1.Deployment, err error) {
+
+	if err != nil {
+		v1alpha1.StashWorkloadRollout(ctx, err.Error())
+		return
+	}
+	parent.Status = k8sv1alpha1.DeploymentStatus{TensegrityStatus: parent.Status.TensegrityStatus}
+	if child == nil {
+		return
+	}
+	v1alpha1.RecordRollout(ctx, parent)
+	v1alpha1.StashWorkloadRollout(ctx, deploymentRollout(child))
+	parent.Status.Replicas = child.Status.Replicas
+	parent.Status.ReadyReplicas = child.Status.ReadyReplicas
+	parent.Status.UpdatedReplicas = child.Status.UpdatedReplicas
+	parent.Status.AvailableReplicas = child.Status.AvailableReplicas
+	parent.Status.WorkloadConditions = child.Status.Conditions
 }
 
 type deploymentChildReconciler = reconcilers.ChildReconciler[
@@ -24,13 +24,17 @@ import (
 	. "github.com/onsi/gomega"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	controllerv1alpha1 "github.com/fastforgeinc/tensegrity/internal/controller/v1alpha1"
 )
 
 var _ = Describe("Deployment Controller", func() {
@@ -83,7 +87,8 @@ var _ = Describe("Deployment Controller", func() {
 			controllerReconciler := NewDeploymentReconciler(
 				reconcilerConfig, validationReconciler,
 				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
-				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance)
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
@@ -92,4 +97,200 @@ var _ = Describe("Deployment Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When mirroring the child Deployment status", func() {
+		const resourceName = "test-deployment-status"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &k8sv1alpha1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: k8sv1alpha1.DeploymentSpec{
+					DeploymentSpec: appsv1.DeploymentSpec{
+						Replicas: ptr.To(int32(2)),
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": resourceName}},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": resourceName}},
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "api", Image: "busybox"}},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &k8sv1alpha1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should mirror replicas and conditions, and report the rollout", func() {
+			controllerReconciler := NewDeploymentReconciler(
+				reconcilerConfig, validationReconciler,
+				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			reconcileDeployment := func() *k8sv1alpha1.Deployment {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				resource := &k8sv1alpha1.Deployment{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				return resource
+			}
+
+			resource := reconcileDeployment()
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionFalse,
+				"waiting for deployment spec update to be observed")
+
+			child := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+			child.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: child.Generation,
+				Replicas:           2,
+				UpdatedReplicas:    2,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable"},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, child)).To(Succeed())
+
+			resource = reconcileDeployment()
+			Expect(resource.Status.Replicas).To(BeEquivalentTo(2))
+			Expect(resource.Status.UpdatedReplicas).To(BeEquivalentTo(2))
+			Expect(resource.Status.ReadyReplicas).To(BeEquivalentTo(1))
+			Expect(resource.Status.AvailableReplicas).To(BeEquivalentTo(1))
+			Expect(resource.Status.WorkloadConditions).To(HaveLen(1))
+			Expect(resource.Status.WorkloadConditions[0].Reason).To(Equal("MinimumReplicasUnavailable"))
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionFalse, "1 of 2 updated replicas are available")
+
+			child.Status.ReadyReplicas, child.Status.AvailableReplicas = 2, 2
+			child.Status.Conditions[0].Status, child.Status.Conditions[0].Reason = corev1.ConditionTrue, "MinimumReplicasAvailable"
+			Expect(k8sClient.Status().Update(ctx, child)).To(Succeed())
+
+			resource = reconcileDeployment()
+			Expect(resource.Status.AvailableReplicas).To(BeEquivalentTo(2))
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionTrue, "")
+		})
+	})
+
+	Context("When applying the child Deployment", func() {
+		const resourceName = "test-deployment-apply"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		labels := map[string]string{"app": resourceName}
+
+		var controllerReconciler interface {
+			Reconcile(context.Context, reconcile.Request) (reconcile.Result, error)
+		}
+		reconcileChild := func() *appsv1.Deployment {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			child := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+			return child
+		}
+
+		BeforeEach(func() {
+			controllerReconciler = NewDeploymentReconciler(
+				reconcilerConfig, validationReconciler,
+				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			resource := &k8sv1alpha1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: k8sv1alpha1.DeploymentSpec{
+					DeploymentSpec: appsv1.DeploymentSpec{
+						Replicas: ptr.To(int32(2)),
+						Selector: &metav1.LabelSelector{MatchLabels: labels},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: labels},
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "api", Image: "busybox:1.36"}},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &k8sv1alpha1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			child := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, child)).To(Succeed())
+		})
+
+		It("should propagate spec edits, and leave replicas scaled through the scale subresource", func() {
+			child := reconcileChild()
+			Expect(child.Spec.Replicas).To(HaveValue(BeEquivalentTo(2)))
+
+			By("scaling the child as a HorizontalPodAutoscaler does")
+			Expect(k8sClient.SubResource("scale").Update(ctx, child, client.WithSubResourceBody(&autoscalingv1.Scale{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: 7},
+			}))).To(Succeed())
+
+			By("editing the image and replicas of the resource")
+			resource := &k8sv1alpha1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Replicas = ptr.To(int32(3))
+			resource.Spec.Template.Spec.Containers[0].Image = "busybox:1.37"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			child = reconcileChild()
+			Expect(child.Spec.Template.Spec.Containers[0].Image).To(Equal("busybox:1.37"))
+			Expect(child.Spec.Replicas).To(HaveValue(BeEquivalentTo(7)))
+		})
+
+		It("should take over fields set by updates, so fields which are not applied anymore are removed", func() {
+			resource := &k8sv1alpha1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("creating the child with an update as former versions did")
+			legacy := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: k8sv1alpha1.GroupVersion.String(), Kind: "Deployment", Name: resourceName,
+						UID: resource.UID, Controller: ptr.To(true), BlockOwnerDeletion: ptr.To(true)}},
+				},
+				Spec: *resource.Spec.DeploymentSpec.DeepCopy(),
+			}
+			legacy.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "LEGACY", Value: "true"}}
+			Expect(k8sClient.Create(ctx, legacy, client.FieldOwner(controllerv1alpha1.UpdateFieldManager))).To(Succeed())
+
+			child := reconcileChild()
+			Expect(child.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+			updatedByLegacy := func(child *appsv1.Deployment) bool {
+				for _, entry := range child.ManagedFields {
+					if entry.Manager == controllerv1alpha1.UpdateFieldManager &&
+						entry.Operation == metav1.ManagedFieldsOperationUpdate && entry.Subresource == "" {
+						return true
+					}
+				}
+				return false
+			}
+			Expect(updatedByLegacy(child)).To(BeFalse())
+
+			By("updating the applied child by a field manager of the same name")
+			child.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "EXTERNAL", Value: "true"}}
+			Expect(k8sClient.Update(ctx, child, client.FieldOwner(controllerv1alpha1.UpdateFieldManager))).To(Succeed())
+
+			child = reconcileChild()
+			Expect(child.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(corev1.EnvVar{Name: "EXTERNAL", Value: "true"}))
+			Expect(updatedByLegacy(child)).To(BeTrue())
+		})
+	})
 })
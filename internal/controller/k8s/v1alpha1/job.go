@@ -0,0 +1,209 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+
+	"reconciler.io/runtime/reconcilers"
+
+	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/internal/controller/v1alpha1"
+)
+
+func NewJobReconciler(
+	config *reconcilers.Config,
+	validationReconciler *v1alpha1.ValidationReconciler,
+	consumerReconciler *v1alpha1.ConsumerReconciler,
+	consumerSecretReconciler *v1alpha1.ConsumerSecretReconciler,
+	consumerConfigMapReconciler *v1alpha1.ConsumerConfigMapReconciler,
+	producerReconciler *v1alpha1.ProducerReconciler,
+	producerSecretReconciler *v1alpha1.ProducerSecretReconciler,
+	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler,
+	statusReconciler *v1alpha1.StatusReconciler) *JobReconciler {
+
+	return &JobReconciler{
+		Name: "JobReconciler",
+		Setup: func(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
+			builder.Watches(new(apiv1alpha1.Static), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.CronJob), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.DaemonSet), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Deployment), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Job), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.StatefulSet), reconcilers.EnqueueTracked(ctx))
+			return nil
+		},
+		Config: *config,
+		Reconciler: v1alpha1.Trace[*k8sv1alpha1.Job](&reconcilers.TryCatch[*k8sv1alpha1.Job]{
+			Name: "JobReconciler",
+			Try: v1alpha1.TraceSequence(reconcilers.Sequence[*k8sv1alpha1.Job]{
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *apiv1alpha1.Tensegrity]{
+					Reconciler: validationReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *apiv1alpha1.Tensegrity]{
+					Reconciler: consumerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerConfigMapReconciler,
+				},
+				NewJobChildReconciler(),
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *apiv1alpha1.Tensegrity]{
+					Reconciler: producerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *metav1.PartialObjectMetadata]{
+					Reconciler: producerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.Job, *metav1.PartialObjectMetadata]{
+					Reconciler: producerConfigMapReconciler,
+				},
+			}),
+			Catch: v1alpha1.CatchReconcileError[*k8sv1alpha1.Job],
+			Finally: v1alpha1.Trace[*k8sv1alpha1.Job](&reconcilers.CastResource[*k8sv1alpha1.Job, *apiv1alpha1.Tensegrity]{
+				Reconciler: statusReconciler,
+			}),
+		}),
+	}
+}
+
+// +kubebuilder:rbac:groups=k8s.tensegrity.fastforge.io,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=k8s.tensegrity.fastforge.io,resources=jobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=k8s.tensegrity.fastforge.io,resources=jobs/finalizers,verbs=update
+
+// JobReconciler reconciles tensegrity api/k8s/v1alpha1.Job resource,
+// and runs sequence of other reconcilers to get desired workload.
+type JobReconciler = reconcilers.ResourceReconciler[*k8sv1alpha1.Job]
+
+func NewJobChildReconciler() *JobChildReconciler {
+	r := new(JobChildReconciler)
+	r.jobChildReconciler = jobChildReconciler{
+		Name:                       "JobChildReconciler",
+		DesiredChild:               r.DesiredChild,
+		ReflectChildStatusOnParent: r.ReflectChildStatusOnParent,
+		ChildObjectManager: &reconcilers.UpdatingObjectManager[*batchv1.Job]{
+			MergeBeforeUpdate: r.MergeBeforeUpdate,
+		},
+	}
+	return r
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs/finalizers,verbs=update
+
+// JobChildReconciler creates child k8s.io/api/batch/v1.Job from workload specs,
+// adds ConfigMap and Secret if they are present.
+type JobChildReconciler struct {
+	jobChildReconciler
+}
+
+func (r *JobChildReconciler) DesiredChild(
+	ctx context.Context, resource *k8sv1alpha1.Job) (*batchv1.Job, error) {
+
+	child := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        resource.Name,
+			Labels:      resource.Labels,
+			Namespace:   resource.Namespace,
+			Annotations: make(map[string]string),
+		},
+		Spec: resource.Spec.JobSpec,
+	}
+
+	var envFrom []corev1.EnvFromSource
+	if name := v1alpha1.ConsumerSecretNameFromContext(ctx); len(name) > 0 {
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		})
+
+		if key, value := v1alpha1.ConsumerSecretAnnotationFromContext(ctx); len(key) > 0 && len(value) > 0 {
+			child.Annotations[key] = value
+			if child.Spec.Template.Annotations == nil {
+				child.Spec.Template.Annotations = make(map[string]string)
+			}
+			child.Spec.Template.Annotations[key] = value
+		}
+	}
+
+	if name := v1alpha1.ConsumerConfigMapNameFromContext(ctx); len(name) > 0 {
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		})
+
+		if key, value := v1alpha1.ConsumerConfigMapAnnotationFromContext(ctx); len(key) > 0 && len(value) > 0 {
+			child.Annotations[key] = value
+			if child.Spec.Template.Annotations == nil {
+				child.Spec.Template.Annotations = make(map[string]string)
+			}
+			child.Spec.Template.Annotations[key] = value
+		}
+	}
+
+	if len(envFrom) > 0 {
+		for i, container := range child.Spec.Template.Spec.InitContainers {
+			child.Spec.Template.Spec.InitContainers[i].EnvFrom = append(
+				container.EnvFrom, envFrom...)
+		}
+		for i, container := range child.Spec.Template.Spec.Containers {
+			child.Spec.Template.Spec.Containers[i].EnvFrom = append(
+				container.EnvFrom, envFrom...)
+		}
+	}
+
+	return child, nil
+}
+
+// MergeBeforeUpdate merges only metadata, since pod template of a Job is immutable,
+// and labels are merged as Job labels are defaulted from its pod template.
+func (r *JobChildReconciler) MergeBeforeUpdate(current, desired *batchv1.Job) {
+	current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
+	current.Labels = reconcilers.MergeMaps(current.Labels, desired.Labels)
+}
+
+// ReflectChildStatusOnParent stashes a reason the child Job is not ready, the error when it is failed to reconcile.
+func (r *JobChildReconciler) ReflectChildStatusOnParent(
+	ctx context.Context, _ *k8sv1alpha1.Job, child *batchv1.Job, err error) {
+
+	if err != nil {
+		v1alpha1.StashWorkloadRollout(ctx, err.Error())
+		return
+	}
+	if child == nil {
+		return
+	}
+	v1alpha1.StashWorkloadRollout(ctx, jobRollout(child))
+}
+
+type jobChildReconciler = reconcilers.ChildReconciler[
+	*k8sv1alpha1.Job,
+	*batchv1.Job,
+	*batchv1.JobList,
+]
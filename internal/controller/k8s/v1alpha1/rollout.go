@@ -0,0 +1,125 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The rollout functions return a reason a child workload is not rolled out yet, or an empty string
+// when it is, the same way as kubectl rollout status does. Jobs and CronJobs are not supported by kubectl,
+// they follow kstatus, where a started Job is current unless it is failed.
+
+func deploymentRollout(child *appsv1.Deployment) string {
+	if child.Generation > child.Status.ObservedGeneration {
+		return "waiting for deployment spec update to be observed"
+	}
+	for _, condition := range child.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return fmt.Sprintf("deployment %q exceeded its progress deadline", child.Name)
+		}
+	}
+	replicas := int32(1)
+	if child.Spec.Replicas != nil {
+		replicas = *child.Spec.Replicas
+	}
+	switch {
+	case child.Status.UpdatedReplicas < replicas:
+		return fmt.Sprintf("%d out of %d new replicas have been updated", child.Status.UpdatedReplicas, replicas)
+	case child.Status.Replicas > child.Status.UpdatedReplicas:
+		return fmt.Sprintf("%d old replicas are pending termination",
+			child.Status.Replicas-child.Status.UpdatedReplicas)
+	case child.Status.AvailableReplicas < child.Status.UpdatedReplicas:
+		return fmt.Sprintf("%d of %d updated replicas are available",
+			child.Status.AvailableReplicas, child.Status.UpdatedReplicas)
+	}
+	return ""
+}
+
+func statefulSetRollout(child *appsv1.StatefulSet) string {
+	if child.Generation > child.Status.ObservedGeneration {
+		return "waiting for statefulset spec update to be observed"
+	}
+	replicas := int32(1)
+	if child.Spec.Replicas != nil {
+		replicas = *child.Spec.Replicas
+	}
+	if child.Status.ReadyReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas are ready", child.Status.ReadyReplicas, replicas)
+	}
+	if child.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return ""
+	}
+	if rollingUpdate := child.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil &&
+		rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		if updated := replicas - *rollingUpdate.Partition; child.Status.UpdatedReplicas < updated {
+			return fmt.Sprintf("%d of %d replicas are updated", child.Status.UpdatedReplicas, updated)
+		}
+		return ""
+	}
+	if child.Status.UpdateRevision != child.Status.CurrentRevision {
+		return fmt.Sprintf("%d of %d replicas are updated", child.Status.UpdatedReplicas, replicas)
+	}
+	return ""
+}
+
+func daemonSetRollout(child *appsv1.DaemonSet) string {
+	if child.Generation > child.Status.ObservedGeneration {
+		return "waiting for daemonset spec update to be observed"
+	}
+	if child.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return ""
+	}
+	switch {
+	case child.Status.UpdatedNumberScheduled < child.Status.DesiredNumberScheduled:
+		return fmt.Sprintf("%d out of %d new pods have been updated",
+			child.Status.UpdatedNumberScheduled, child.Status.DesiredNumberScheduled)
+	case child.Status.NumberAvailable < child.Status.DesiredNumberScheduled:
+		return fmt.Sprintf("%d of %d updated pods are available",
+			child.Status.NumberAvailable, child.Status.DesiredNumberScheduled)
+	}
+	return ""
+}
+
+func jobRollout(child *batchv1.Job) string {
+	for _, condition := range child.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("job %q has failed: %s", child.Name, condition.Reason)
+		}
+	}
+	if child.Status.StartTime == nil {
+		return "waiting for job to start"
+	}
+	return ""
+}
+
+func cronJobRollout(child *batchv1.CronJob) string {
+	if len(child.Status.Active) > 0 || child.Status.LastScheduleTime == nil {
+		return ""
+	}
+	if lastSuccessful := child.Status.LastSuccessfulTime; lastSuccessful == nil ||
+		lastSuccessful.Before(child.Status.LastScheduleTime) {
+		return fmt.Sprintf("last scheduled job of cronjob %q has not succeeded", child.Name)
+	}
+	return ""
+}
@@ -41,42 +41,52 @@ func NewStatefulSetReconciler(
 	consumerConfigMapReconciler *v1alpha1.ConsumerConfigMapReconciler,
 	producerReconciler *v1alpha1.ProducerReconciler,
 	producerSecretReconciler *v1alpha1.ProducerSecretReconciler,
-	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler) *StatefulSetReconciler {
+	producerConfigMapReconciler *v1alpha1.ProducerConfigMapReconciler,
+	statusReconciler *v1alpha1.StatusReconciler) *StatefulSetReconciler {
 
 	return &StatefulSetReconciler{
 		Name:   "StatefulSetReconciler",
 		Config: *config,
 		Setup: func(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
 			builder.Watches(new(apiv1alpha1.Static), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.CronJob), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.DaemonSet), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.Deployment), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Job), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.StatefulSet), reconcilers.EnqueueTracked(ctx))
 			return nil
 		},
-		Reconciler: reconcilers.Sequence[*k8sv1alpha1.StatefulSet]{
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
-				Reconciler: validationReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
-				Reconciler: consumerReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
-				Reconciler: consumerSecretReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
-				Reconciler: consumerConfigMapReconciler,
-			},
-			NewStatefulSetChildReconciler(),
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
-				Reconciler: producerReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
-				Reconciler: producerSecretReconciler,
-			},
-			&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
-				Reconciler: producerConfigMapReconciler,
-			},
-		},
+		Reconciler: v1alpha1.Trace[*k8sv1alpha1.StatefulSet](&reconcilers.TryCatch[*k8sv1alpha1.StatefulSet]{
+			Name: "StatefulSetReconciler",
+			Try: v1alpha1.TraceSequence(reconcilers.Sequence[*k8sv1alpha1.StatefulSet]{
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
+					Reconciler: validationReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
+					Reconciler: consumerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerConfigMapReconciler,
+				},
+				NewStatefulSetChildReconciler(),
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
+					Reconciler: producerReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
+					Reconciler: producerSecretReconciler,
+				},
+				&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *metav1.PartialObjectMetadata]{
+					Reconciler: producerConfigMapReconciler,
+				},
+			}),
+			Catch: v1alpha1.CatchReconcileError[*k8sv1alpha1.StatefulSet],
+			Finally: v1alpha1.Trace[*k8sv1alpha1.StatefulSet](&reconcilers.CastResource[*k8sv1alpha1.StatefulSet, *apiv1alpha1.Tensegrity]{
+				Reconciler: statusReconciler,
+			}),
+		}),
 	}
 }
 
@@ -94,8 +104,8 @@ func NewStatefulSetChildReconciler() *StatefulSetChildReconciler {
 		Name:                       "StatefulSetChildReconciler",
 		DesiredChild:               r.DesiredChild,
 		ReflectChildStatusOnParent: r.ReflectChildStatusOnParent,
-		ChildObjectManager: &reconcilers.UpdatingObjectManager[*appsv1.StatefulSet]{
-			MergeBeforeUpdate: r.MergeBeforeUpdate,
+		ChildObjectManager: &v1alpha1.ApplyingObjectManager[*appsv1.StatefulSet]{
+			MergeBeforeApply: r.MergeBeforeApply,
 		},
 	}
 	return r
@@ -171,13 +181,35 @@ func (r *StatefulSetChildReconciler) DesiredChild(
 	return child, nil
 }
 
-func (r *StatefulSetChildReconciler) MergeBeforeUpdate(current, desired *appsv1.StatefulSet) {
-	current.Annotations = reconcilers.MergeMaps(current.Annotations, desired.Annotations)
-	current.Labels = desired.Labels
+// MergeBeforeApply leaves replicas to a HorizontalPodAutoscaler or another controller
+// scaling the child StatefulSet through the scale subresource.
+func (r *StatefulSetChildReconciler) MergeBeforeApply(actual, desired *appsv1.StatefulSet) {
+	if v1alpha1.IsManagedBySubresource(actual, "scale", "spec", "replicas") {
+		desired.Spec.Replicas = nil
+	}
 }
 
+// ReflectChildStatusOnParent mirrors replicas and conditions of the child StatefulSet,
+// and stashes its rollout progress, the last observed status is kept when the child is failed to reconcile.
 func (r *StatefulSetChildReconciler) ReflectChildStatusOnParent(
-	_ context.Context, _ *k8sv1alpha1.StatefulSet, _ *appsv1.StatefulSet, _ error) {
+	ctx context.Context, parent *k8sv1alpha1.StatefulSet, child *appsv1.StatefulSet, err error) {
+
+	if err != nil {
+		v1alpha1.StashWorkloadRollout(ctx, err.Error())
+		return
+	}
+	parent.Status = k8sv1alpha1.StatefulSetStatus{TensegrityStatus: parent.Status.TensegrityStatus}
+	if child == nil {
+		return
+	}
+	v1alpha1.RecordRollout(ctx, parent)
+	v1alpha1.StashWorkloadRollout(ctx, statefulSetRollout(child))
+	parent.Status.Replicas = child.Status.Replicas
+	parent.Status.ReadyReplicas = child.Status.ReadyReplicas
+	parent.Status.CurrentReplicas = child.Status.CurrentReplicas
+	parent.Status.UpdatedReplicas = child.Status.UpdatedReplicas
+	parent.Status.AvailableReplicas = child.Status.AvailableReplicas
+	parent.Status.WorkloadConditions = child.Status.Conditions
 }
 
 type statefulSetChildReconciler = reconcilers.ChildReconciler[
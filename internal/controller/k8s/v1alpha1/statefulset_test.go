@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
@@ -83,7 +84,8 @@ var _ = Describe("StatefulSet Controller", func() {
 			controllerReconciler := NewStatefulSetReconciler(
 				reconcilerConfig, validationReconciler,
 				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
-				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance)
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
@@ -92,4 +94,79 @@ var _ = Describe("StatefulSet Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When mirroring the child StatefulSet status", func() {
+		const resourceName = "test-statefulset-status"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &k8sv1alpha1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: k8sv1alpha1.StatefulSetSpec{
+					StatefulSetSpec: appsv1.StatefulSetSpec{
+						Replicas: ptr.To(int32(3)),
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": resourceName}},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": resourceName}},
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "db", Image: "busybox"}},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &k8sv1alpha1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should mirror replicas and revisions, and report the rollout", func() {
+			controllerReconciler := NewStatefulSetReconciler(
+				reconcilerConfig, validationReconciler,
+				consumerReconciler, consumerSecretReconciler, consumerConfigMapReconciler,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			reconcileStatefulSet := func() *k8sv1alpha1.StatefulSet {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				resource := &k8sv1alpha1.StatefulSet{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				return resource
+			}
+
+			reconcileStatefulSet()
+			child := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, child)).To(Succeed())
+			child.Status = appsv1.StatefulSetStatus{
+				ObservedGeneration: child.Generation,
+				Replicas:           3,
+				ReadyReplicas:      3,
+				CurrentReplicas:    2,
+				UpdatedReplicas:    1,
+				CurrentRevision:    resourceName + "-1",
+				UpdateRevision:     resourceName + "-2",
+			}
+			Expect(k8sClient.Status().Update(ctx, child)).To(Succeed())
+
+			resource := reconcileStatefulSet()
+			Expect(resource.Status.Replicas).To(BeEquivalentTo(3))
+			Expect(resource.Status.ReadyReplicas).To(BeEquivalentTo(3))
+			Expect(resource.Status.CurrentReplicas).To(BeEquivalentTo(2))
+			Expect(resource.Status.UpdatedReplicas).To(BeEquivalentTo(1))
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionFalse, "1 of 3 replicas are updated")
+
+			child.Status.CurrentReplicas, child.Status.UpdatedReplicas = 3, 3
+			child.Status.CurrentRevision = child.Status.UpdateRevision
+			Expect(k8sClient.Status().Update(ctx, child)).To(Succeed())
+
+			resource = reconcileStatefulSet()
+			expectReady(resource.Status.TensegrityStatus, corev1.ConditionTrue, "")
+		})
+	})
 })
@@ -18,6 +18,7 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"runtime"
@@ -30,6 +31,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -40,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
 	controllerv1alpha1 "github.com/fastforgeinc/tensegrity/internal/controller/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
@@ -59,6 +63,7 @@ var consumerConfigMapReconciler *controllerv1alpha1.ConsumerConfigMapReconciler
 var producerReconcilerInstance *controllerv1alpha1.ProducerReconciler
 var producerSecretReconcilerInstance *controllerv1alpha1.ProducerSecretReconciler
 var producerConfigMapReconcilerInstance *controllerv1alpha1.ProducerConfigMapReconciler
+var statusReconcilerInstance *controllerv1alpha1.StatusReconciler
 
 func TestControllers(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -71,7 +76,7 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "..", "config", "crd", "bases")},
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "..", "manifests", "crd", "bases")},
 		ErrorIfCRDPathMissing: true,
 
 		// The BinaryAssetsDirectory is only required if you want to run the tests directly
@@ -92,6 +97,9 @@ var _ = BeforeSuite(func() {
 	err = k8sv1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = apiv1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -116,6 +124,7 @@ var _ = BeforeSuite(func() {
 	producerReconcilerInstance = controllerv1alpha1.NewProducerReconciler()
 	producerSecretReconcilerInstance = controllerv1alpha1.NewProducerSecretReconciler()
 	producerConfigMapReconcilerInstance = controllerv1alpha1.NewProducerConfigMapReconciler()
+	statusReconcilerInstance = controllerv1alpha1.NewStatusReconciler()
 })
 
 var _ = AfterSuite(func() {
@@ -123,3 +132,56 @@ var _ = AfterSuite(func() {
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
+
+// createStatic creates a Static with a produced ConfigMap holding data, so workloads can consume its keys.
+func createStatic(ctx context.Context, name string, data map[string]string) *apiv1alpha1.Static {
+	static := &apiv1alpha1.Static{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+	}
+	Expect(k8sClient.Create(ctx, static)).To(Succeed())
+	static.Status.ProducedConfigMapName = name + apiv1alpha1.DefaultProducesConfigMapNamePrefix
+	Expect(k8sClient.Status().Update(ctx, static)).To(Succeed())
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: static.Status.ProducedConfigMapName, Namespace: "default"},
+		Data:       data,
+	}
+	Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+	return static
+}
+
+// deleteStatic deletes a Static created by createStatic with its produced ConfigMap.
+func deleteStatic(ctx context.Context, static *apiv1alpha1.Static) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: static.Status.ProducedConfigMapName, Namespace: "default"},
+	}
+	Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+	Expect(k8sClient.Delete(ctx, static)).To(Succeed())
+}
+
+// consumesStatic returns TensegritySpec of a workload consuming keys of a Static by maps.
+func consumesStatic(name string, static *apiv1alpha1.Static, maps map[string]string) apiv1alpha1.TensegritySpec {
+	return apiv1alpha1.TensegritySpec{
+		Delegates: []apiv1alpha1.DelegateSpec{
+			{ObjectReference: corev1.ObjectReference{Kind: "Namespace", Name: "default"}},
+		},
+		Consumes: []apiv1alpha1.ConsumesSpec{
+			{ObjectReference: corev1.ObjectReference{
+				APIVersion: apiv1alpha1.GroupVersion.String(), Kind: "Static", Name: static.Name},
+				Maps: maps},
+		},
+		ConsumesConfigMapName: name + apiv1alpha1.DefaultConsumesConfigMapNamePrefix,
+		ConsumesSecretName:    name + apiv1alpha1.DefaultConsumesSecretNamePrefix,
+	}
+}
+
+// expectReady expects a Ready condition of a workload status with a message, when it is not ready.
+func expectReady(status apiv1alpha1.TensegrityStatus, ready corev1.ConditionStatus, message string) {
+	condition := apiv1alpha1.GetTensegrityCondition(status, apiv1alpha1.TensegrityReady)
+	Expect(condition).NotTo(BeNil())
+	Expect(condition.Status).To(Equal(ready))
+	if ready != corev1.ConditionTrue {
+		Expect(condition.Reason).To(Equal(apiv1alpha1.RolloutInProgressReason))
+		Expect(condition.Message).To(Equal(fmt.Sprintf(apiv1alpha1.RolloutInProgressMessage, message)))
+	}
+}
@@ -0,0 +1,118 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"reconciler.io/runtime/reconcilers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+const annotatedStashKey reconcilers.StashKey = "tensegrity.fastforge.io/annotated"
+
+// annotatedSpec is a subset of TensegritySpec allowed in SpecAnnotation of a native workload resource.
+type annotatedSpec struct {
+	Delegates             []v1alpha1.DelegateSpec        `json:"delegates,omitempty"`
+	Consumes              []v1alpha1.ConsumesSpec        `json:"consumes,omitempty"`
+	Templates             []v1alpha1.TemplateSpec        `json:"templates,omitempty"`
+	ConsumesSecretName    string                         `json:"consumesSecretName,omitempty"`
+	ConsumesConfigMapName string                         `json:"consumesConfigMapName,omitempty"`
+	ConsumesFailurePolicy v1alpha1.ConsumesFailurePolicy `json:"consumesFailurePolicy,omitempty"`
+}
+
+func NewAnnotationReconciler() *AnnotationReconciler {
+	r := new(AnnotationReconciler)
+	r.workloadReconciler = workloadReconciler{
+		Name: "AnnotationReconciler",
+		Sync: r.Sync,
+	}
+	return r
+}
+
+// AnnotationReconciler reads TensegritySpec from SpecAnnotation of a native workload resource,
+// and sets defaults the same way webhooks do for Tensegrity workload resources.
+type AnnotationReconciler struct {
+	workloadReconciler
+}
+
+func (r *AnnotationReconciler) Sync(ctx context.Context, resource *v1alpha1.Tensegrity) error {
+	reconcilers.StashValue(ctx, annotatedStashKey, true)
+	resource.Spec = v1alpha1.TensegritySpec{}
+	value, ok := resource.Annotations[v1alpha1.SpecAnnotation]
+	if !ok {
+		return nil
+	}
+
+	spec := new(annotatedSpec)
+	if err := yaml.UnmarshalStrict([]byte(value), spec); err != nil {
+		return errors.Wrap(err, v1alpha1.SpecAnnotation)
+	}
+
+	resource.Spec.Delegates = spec.Delegates
+	resource.Spec.Consumes = spec.Consumes
+	resource.Spec.Templates = spec.Templates
+	resource.Spec.ConsumesSecretName = spec.ConsumesSecretName
+	resource.Spec.ConsumesConfigMapName = spec.ConsumesConfigMapName
+	resource.Spec.ConsumesFailurePolicy = spec.ConsumesFailurePolicy
+	resource.Spec.SetDefaultNamespaceDelegate(resource.GetNamespace())
+	resource.Spec.SetDefaultConsumesConfigMapName(resource.GetName() + v1alpha1.DefaultConsumesConfigMapNamePrefix)
+	resource.Spec.SetDefaultConsumesSecretName(resource.GetName() + v1alpha1.DefaultConsumesSecretNamePrefix)
+	return nil
+}
+
+// statusPersisted reports whether status of the reconciled resource is persisted, which is not the case for native
+// workload resources, so status of their consumed envs always starts empty.
+func statusPersisted(ctx context.Context) bool {
+	annotated, _ := reconcilers.RetrieveValue(ctx, annotatedStashKey).(bool)
+	return !annotated
+}
+
+// AnnotatedPredicate filters out events of native workload resources of type T which are neither annotated with
+// SpecAnnotation nor injected before, so removal of the annotation is still reconciled. Events of other types pass.
+func AnnotatedPredicate[T client.Object]() predicate.Predicate {
+	annotated := func(obj client.Object) bool {
+		if _, ok := obj.(T); !ok {
+			return true
+		}
+		annotations := obj.GetAnnotations()
+		_, spec := annotations[v1alpha1.SpecAnnotation]
+		_, injected := annotations[v1alpha1.InjectedAnnotation]
+		return spec || injected
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return annotated(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return annotated(e.ObjectOld) || annotated(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return annotated(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return annotated(e.Object)
+		},
+	}
+}
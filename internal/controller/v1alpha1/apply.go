@@ -0,0 +1,191 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManager is a name of a field manager child objects are applied with.
+const FieldManager = "tensegrity"
+
+// UpdateFieldManager is a name of a field manager child objects were updated with before they were applied.
+// The API server named field managers of updates by the user agent of the released controller binary.
+const UpdateFieldManager = "manager"
+
+// ApplyingObjectManager manages child objects with server-side apply, so a desired object is authoritative
+// for fields it sets, and fields owned by other field managers are left alone.
+type ApplyingObjectManager[T client.Object] struct {
+	// MergeBeforeApply adjusts a desired object by an actual one before it is applied,
+	// e.g. to leave out fields managed by other field managers.
+	//
+	// +optional
+	MergeBeforeApply func(actual, desired T)
+}
+
+func (m *ApplyingObjectManager[T]) SetupWithManager(_ context.Context, _ ctrl.Manager, _ *builder.Builder) error {
+	return nil
+}
+
+func (m *ApplyingObjectManager[T]) Manage(ctx context.Context, resource client.Object, actual, desired T) (T, error) {
+	var empty T
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+
+	if isNilObject(desired) {
+		if isNilObject(actual) {
+			return empty, nil
+		}
+		if err := config.Delete(ctx, actual); err != nil && !k8serrors.IsNotFound(err) {
+			recordEvent(ctx, resource, corev1.EventTypeWarning, "DeleteFailed",
+				"Failed to delete %s %q: %v", typeName(actual), actual.GetName(), err)
+			return empty, err
+		}
+		recordEvent(ctx, resource, corev1.EventTypeNormal, "Deleted",
+			"Deleted %s %q", typeName(actual), actual.GetName())
+		return empty, nil
+	}
+
+	if !isNilObject(actual) {
+		upgraded, err := upgradeManagedFields(ctx, config, actual)
+		if err != nil {
+			recordEvent(ctx, resource, corev1.EventTypeWarning, "UpdateFailed",
+				"Failed to migrate field managers of %s %q: %v", typeName(actual), actual.GetName(), err)
+			return empty, err
+		}
+		actual = upgraded
+	}
+
+	applied := desired.DeepCopyObject().(T)
+	if !isNilObject(actual) && m.MergeBeforeApply != nil {
+		m.MergeBeforeApply(actual, applied)
+	}
+	gvk, err := config.GroupVersionKindFor(applied)
+	if err != nil {
+		return empty, err
+	}
+	applied.GetObjectKind().SetGroupVersionKind(gvk)
+	applied.SetManagedFields(nil)
+	applied.SetResourceVersion("")
+
+	err = config.Patch(ctx, applied, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+	switch {
+	case err != nil && isNilObject(actual):
+		recordEvent(ctx, resource, corev1.EventTypeWarning, "CreationFailed",
+			"Failed to create %s %q: %v", gvk.Kind, applied.GetName(), err)
+		return empty, err
+	case err != nil:
+		recordEvent(ctx, resource, corev1.EventTypeWarning, "UpdateFailed",
+			"Failed to update %s %q: %v", gvk.Kind, applied.GetName(), err)
+		return empty, err
+	case isNilObject(actual):
+		recordEvent(ctx, resource, corev1.EventTypeNormal, "Created",
+			"Created %s %q", gvk.Kind, applied.GetName())
+	case actual.GetResourceVersion() != applied.GetResourceVersion():
+		recordEvent(ctx, resource, corev1.EventTypeNormal, "Updated",
+			"Updated %s %q", gvk.Kind, applied.GetName())
+	}
+	return applied, nil
+}
+
+// upgradeManagedFields moves fields owned by UpdateFieldManager through updates to FieldManager once,
+// the same way as kubectl does when it switches to server-side apply, so fields which are not applied
+// anymore are removed instead of being left behind by the former owner.
+func upgradeManagedFields[T client.Object](ctx context.Context, config reconcilers.Config, actual T) (T, error) {
+	if !needsManagedFieldsUpgrade(actual) {
+		return actual, nil
+	}
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(actual, sets.New(UpdateFieldManager), FieldManager)
+	if err != nil || patch == nil {
+		return actual, err
+	}
+	upgraded := actual.DeepCopyObject().(T)
+	if err = config.Patch(ctx, upgraded, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return actual, err
+	}
+	return upgraded, nil
+}
+
+// needsManagedFieldsUpgrade returns true when fields of an object are owned by UpdateFieldManager through updates,
+// and the object was not applied by FieldManager yet, so later updates by a manager of the same name are left alone.
+func needsManagedFieldsUpgrade(obj client.Object) bool {
+	var updated bool
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Subresource != "" {
+			continue
+		}
+		switch {
+		case entry.Manager == FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply:
+			return false
+		case entry.Manager == UpdateFieldManager && entry.Operation == metav1.ManagedFieldsOperationUpdate:
+			updated = true
+		}
+	}
+	return updated
+}
+
+// IsManagedBySubresource returns true when a field of an object is owned by any field manager through a subresource,
+// e.g. spec.replicas of a Deployment scaled by a HorizontalPodAutoscaler through the scale subresource.
+func IsManagedBySubresource(obj client.Object, subresource string, path ...string) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Subresource != subresource || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		found := true
+		for _, field := range path {
+			next, ok := fields["f:"+field].(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			fields = next
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func isNilObject(obj client.Object) bool {
+	if obj == nil {
+		return true
+	}
+	value := reflect.ValueOf(obj)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+func typeName(obj client.Object) string {
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
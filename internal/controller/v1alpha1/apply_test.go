@@ -0,0 +1,74 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Server-side Apply", func() {
+	managedBy := func(manager, subresource, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:     manager,
+			Operation:   metav1.ManagedFieldsOperationUpdate,
+			Subresource: subresource,
+			FieldsV1:    &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	It("should find fields owned through a subresource", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+			{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply,
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{}}}`)}},
+			managedBy("kube-controller-manager", "scale", `{"f:spec":{"f:replicas":{}}}`),
+		}}}
+		Expect(IsManagedBySubresource(deployment, "scale", "spec", "replicas")).To(BeTrue())
+		Expect(IsManagedBySubresource(deployment, "scale", "spec", "template")).To(BeFalse())
+		Expect(IsManagedBySubresource(deployment, "status", "spec", "replicas")).To(BeFalse())
+	})
+
+	It("should not find fields owned by updates of the main resource", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+			managedBy("kubectl-edit", "", `{"f:spec":{"f:replicas":{}}}`),
+			managedBy("kube-controller-manager", "scale", `not json`),
+		}}}
+		Expect(IsManagedBySubresource(deployment, "scale", "spec", "replicas")).To(BeFalse())
+		Expect(IsManagedBySubresource(&appsv1.Deployment{}, "scale", "spec", "replicas")).To(BeFalse())
+	})
+
+	It("should upgrade fields owned by updates only until the object is applied", func() {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+			managedBy(UpdateFieldManager, "", `{"f:spec":{"f:template":{}}}`),
+		}}}
+		Expect(needsManagedFieldsUpgrade(deployment)).To(BeTrue())
+
+		deployment.ManagedFields = append(deployment.ManagedFields, metav1.ManagedFieldsEntry{
+			Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)}})
+		Expect(needsManagedFieldsUpgrade(deployment)).To(BeFalse())
+
+		deployment.ManagedFields = []metav1.ManagedFieldsEntry{
+			managedBy(UpdateFieldManager, "status", `{"f:status":{}}`),
+			managedBy("kubectl-edit", "", `{"f:spec":{"f:replicas":{}}}`),
+		}
+		Expect(needsManagedFieldsUpgrade(deployment)).To(BeFalse())
+	})
+})
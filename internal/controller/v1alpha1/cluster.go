@@ -0,0 +1,290 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// remoteClusterTimeout limits reads from a remote cluster, so an unreachable cluster does not block reconciliation.
+const remoteClusterTimeout = 30 * time.Second
+
+const (
+	// remoteClusterSweepInterval is how often referrers which are not seen anymore are looked up.
+	remoteClusterSweepInterval = 1 * time.Hour
+	// remoteClusterReferrerTTL forgets a referrer which has not got a remote cluster for longer, e.g. a deleted one,
+	// it exceeds the default resync period of the manager cache, so live referrers are seen in the meantime.
+	remoteClusterReferrerTTL = 24 * time.Hour
+)
+
+var clusterlog = ctrl.Log.WithName("cluster-registry")
+
+type remoteClusterKey struct {
+	secret    types.NamespacedName
+	namespace string
+}
+
+// ClusterRegistry runs caches of remote clusters referenced by Cluster delegates,
+// and forwards events of remote objects to controllers, so they are enqueued as tracked ones.
+// A remote cluster is stopped when no resource refers to it anymore.
+type ClusterRegistry struct {
+	mu       sync.Mutex
+	once     sync.Once
+	ctx      context.Context
+	scheme   *runtime.Scheme
+	clusters map[remoteClusterKey]*RemoteCluster
+	events   []chan event.GenericEvent
+	now      func() time.Time
+}
+
+func NewClusterRegistry() *ClusterRegistry {
+	return &ClusterRegistry{
+		clusters: make(map[remoteClusterKey]*RemoteCluster),
+		now:      time.Now,
+	}
+}
+
+// Setup adds the registry to a manager once and returns a source of remote events for a controller.
+func (r *ClusterRegistry) Setup(ctx context.Context, mgr ctrl.Manager) (source.Source, error) {
+	var err error
+	r.once.Do(func() {
+		r.scheme = mgr.GetScheme()
+		err = mgr.Add(r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make(chan event.GenericEvent, 1024)
+	r.events = append(r.events, events)
+	return source.Channel(events, reconcilers.EnqueueTracked(ctx)), nil
+}
+
+// Start implements manager.Runnable, remote clusters are running until the manager is stopped.
+func (r *ClusterRegistry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	r.ctx = ctx
+	r.mu.Unlock()
+
+	ticker := time.NewTicker(remoteClusterSweepInterval)
+	defer ticker.Stop()
+	for done := false; !done; {
+		select {
+		case <-ticker.C:
+			r.sweep()
+		case <-ctx.Done():
+			done = true
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, remote := range r.clusters {
+		remote.cancel()
+		delete(r.clusters, key)
+	}
+	return nil
+}
+
+// Get returns a running remote cluster for a kubeconfig Secret and a remote namespace referred by a resource,
+// the cluster is restarted when the kubeconfig changes.
+func (r *ClusterRegistry) Get(referrer types.UID, secret *corev1.Secret, namespace string) (*RemoteCluster, error) {
+	kubeconfig, ok := secret.Data[v1alpha1.KubeconfigSecretKey]
+	if !ok {
+		return nil, errors.Errorf("secret %s/%s has no %s key",
+			secret.Namespace, secret.Name, v1alpha1.KubeconfigSecretKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ctx == nil {
+		return nil, errors.New("cluster registry is not started")
+	}
+
+	key := remoteClusterKey{secret: client.ObjectKeyFromObject(secret), namespace: namespace}
+	referrers := map[types.UID]time.Time{referrer: r.now()}
+	if remote, ok := r.clusters[key]; ok {
+		if bytes.Equal(remote.kubeconfig, kubeconfig) {
+			remote.referrers[referrer] = r.now()
+			return remote, nil
+		}
+		for uid, seen := range remote.referrers {
+			if uid != referrer {
+				referrers[uid] = seen
+			}
+		}
+		remote.cancel()
+		delete(r.clusters, key)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid kubeconfig in secret %s", key.secret)
+	}
+	c, err := cluster.New(config, func(o *cluster.Options) {
+		o.Scheme = r.scheme
+		o.Cache.DefaultNamespaces = map[string]cache.Config{namespace: {}}
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create cluster from secret %s", key.secret)
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	remote := &RemoteCluster{
+		Cluster:    c,
+		ctx:        ctx,
+		kubeconfig: kubeconfig,
+		cancel:     cancel,
+		referrers:  referrers,
+		watched:    make(map[schema.GroupVersionKind]struct{}),
+		send:       r.send,
+	}
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			clusterlog.Error(err, "remote cluster stopped", "secret", key.secret, "namespace", namespace)
+		}
+	}()
+	r.clusters[key] = remote
+	return remote, nil
+}
+
+// Release forgets a referrer of remote clusters it has not got since a time, e.g. before resolving its keys,
+// and stops clusters which are not referred anymore.
+func (r *ClusterRegistry) Release(referrer types.UID, since time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, remote := range r.clusters {
+		if seen, ok := remote.referrers[referrer]; ok && seen.Before(since) {
+			delete(remote.referrers, referrer)
+			r.stopUnreferred(key, remote)
+		}
+	}
+}
+
+// sweep forgets referrers which have not got remote clusters for remoteClusterReferrerTTL,
+// and stops clusters which are not referred anymore.
+func (r *ClusterRegistry) sweep() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expired := r.now().Add(-remoteClusterReferrerTTL)
+	for key, remote := range r.clusters {
+		for uid, seen := range remote.referrers {
+			if seen.Before(expired) {
+				delete(remote.referrers, uid)
+			}
+		}
+		r.stopUnreferred(key, remote)
+	}
+}
+
+func (r *ClusterRegistry) stopUnreferred(key remoteClusterKey, remote *RemoteCluster) {
+	if len(remote.referrers) > 0 {
+		return
+	}
+	clusterlog.Info("stopping remote cluster which is not referred", "secret", key.secret, "namespace", key.namespace)
+	remote.cancel()
+	delete(r.clusters, key)
+}
+
+func (r *ClusterRegistry) send(ctx context.Context, obj client.Object) {
+	r.mu.Lock()
+	events := r.events
+	r.mu.Unlock()
+	for _, ch := range events {
+		select {
+		case ch <- event.GenericEvent{Object: obj}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RemoteCluster is a cluster.Cluster of a Cluster delegate restricted to a remote namespace.
+type RemoteCluster struct {
+	cluster.Cluster
+	mu         sync.Mutex
+	ctx        context.Context
+	kubeconfig []byte
+	cancel     context.CancelFunc
+	referrers  map[types.UID]time.Time
+	watched    map[schema.GroupVersionKind]struct{}
+	send       func(ctx context.Context, obj client.Object)
+}
+
+// Watch forwards events of objects of the same kind to controllers, duck typed objects
+// are watched as unstructured ones the same way as they are read by a duck aware client.
+func (c *RemoteCluster) Watch(ctx context.Context, obj client.Object) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvks, _, err := c.GetScheme().ObjectKinds(obj); err != nil {
+		u := new(unstructured.Unstructured)
+		u.SetGroupVersionKind(gvk)
+		obj = u
+	} else if gvk.Empty() {
+		gvk = gvks[0]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.watched[gvk]; ok {
+		return nil
+	}
+	informer, err := c.GetCache().GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	handle := func(o interface{}) {
+		if tombstone, ok := o.(toolscache.DeletedFinalStateUnknown); ok {
+			o = tombstone.Obj
+		}
+		if obj, ok := o.(client.Object); ok {
+			c.send(c.ctx, obj)
+		}
+	}
+	if _, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    handle,
+		UpdateFunc: func(_, o interface{}) { handle(o) },
+		DeleteFunc: handle,
+	}); err != nil {
+		return err
+	}
+	c.watched[gvk] = struct{}{}
+	return nil
+}
@@ -0,0 +1,239 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("Cluster Registry", Ordered, func() {
+	const remoteNamespace = "staging"
+	const referrer = types.UID("referrer")
+
+	var remoteEnv *envtest.Environment
+	var remoteClient client.Client
+	var registry *ClusterRegistry
+	var events chan event.GenericEvent
+	var secret *corev1.Secret
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	BeforeAll(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+
+		By("bootstrapping remote test environment")
+		remoteEnv = &envtest.Environment{
+			CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "manifests", "crd", "bases")},
+			ErrorIfCRDPathMissing: true,
+			BinaryAssetsDirectory: filepath.Join("..", "..", "..", "bin", "k8s",
+				fmt.Sprintf("1.29.0-%s-%s", runtime.GOOS, runtime.GOARCH)),
+		}
+		remoteCfg, err := remoteEnv.Start()
+		Expect(err).NotTo(HaveOccurred())
+
+		remoteClient, err = client.New(remoteCfg, client.Options{Scheme: scheme.Scheme})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remoteClient.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: remoteNamespace},
+		})).To(Succeed())
+
+		user, err := remoteEnv.AddUser(envtest.User{Name: "tensegrity", Groups: []string{"system:masters"}}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		kubeconfig, err := user.KubeConfig()
+		Expect(err).NotTo(HaveOccurred())
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "staging-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{apiv1alpha1.KubeconfigSecretKey: kubeconfig},
+		}
+
+		registry = NewClusterRegistry()
+		_, err = registry.Setup(ctx, mgr)
+		Expect(err).NotTo(HaveOccurred())
+		events = registry.events[0]
+		go func() {
+			defer GinkgoRecover()
+			Expect(registry.Start(ctx)).To(Succeed())
+		}()
+		Eventually(func() error {
+			_, err := registry.Get(referrer, secret, remoteNamespace)
+			return err
+		}).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		cancel()
+		By("tearing down the remote test environment")
+		Expect(remoteEnv.Stop()).To(Succeed())
+	})
+
+	It("should read objects of a remote cluster namespace", func() {
+		Expect(remoteClient.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres-produced", Namespace: remoteNamespace},
+			Data:       map[string]string{"host": "postgres.staging"},
+		})).To(Succeed())
+
+		remote, err := registry.Get(referrer, secret, remoteNamespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		configMap := new(corev1.ConfigMap)
+		Eventually(func() error {
+			return remote.GetClient().Get(ctx, client.ObjectKey{
+				Namespace: remoteNamespace, Name: "postgres-produced"}, configMap)
+		}).Should(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("host", "postgres.staging"))
+	})
+
+	It("should forward events of watched remote objects", func() {
+		remote, err := registry.Get(referrer, secret, remoteNamespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remote.Watch(ctx, new(corev1.ConfigMap))).To(Succeed())
+
+		configMap := new(corev1.ConfigMap)
+		Expect(remoteClient.Get(ctx, client.ObjectKey{
+			Namespace: remoteNamespace, Name: "postgres-produced"}, configMap)).To(Succeed())
+		configMap.Data["host"] = "postgres.staging.svc"
+		Expect(remoteClient.Update(ctx, configMap)).To(Succeed())
+
+		Eventually(func() string {
+			select {
+			case e := <-events:
+				if cm, ok := e.Object.(*corev1.ConfigMap); ok {
+					return cm.Data["host"]
+				}
+			default:
+			}
+			return ""
+		}).Should(Equal("postgres.staging.svc"))
+	})
+
+	It("should restart a remote cluster when kubeconfig changes", func() {
+		remote, err := registry.Get(referrer, secret, remoteNamespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		rotated := secret.DeepCopy()
+		rotated.Data[apiv1alpha1.KubeconfigSecretKey] = append(rotated.Data[apiv1alpha1.KubeconfigSecretKey], '\n')
+		restarted, err := registry.Get(referrer, rotated, remoteNamespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restarted).NotTo(BeIdenticalTo(remote))
+	})
+
+	It("should resolve consumed keys through a Cluster delegate", func() {
+		By("creating a produced Static in the remote cluster")
+		remoteStatic := &apiv1alpha1.Static{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: remoteNamespace},
+		}
+		Expect(remoteClient.Create(ctx, remoteStatic)).To(Succeed())
+		remoteStatic.Status.ProducedConfigMapName = "postgres-produced"
+		Expect(remoteClient.Status().Update(ctx, remoteStatic)).To(Succeed())
+
+		By("creating a consuming Static with a Cluster delegate")
+		Expect(k8sClient.Create(ctx, secret.DeepCopy())).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, secret.DeepCopy())).To(Succeed())
+		})
+		static := &apiv1alpha1.Static{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-consumer", Namespace: "default"},
+			Spec: apiv1alpha1.StaticSpec{TensegritySpec: apiv1alpha1.TensegritySpec{
+				Delegates: []apiv1alpha1.DelegateSpec{
+					{ObjectReference: corev1.ObjectReference{
+						Kind: "Cluster", Name: secret.Name, Namespace: remoteNamespace}},
+				},
+				Consumes: []apiv1alpha1.ConsumesSpec{
+					{ObjectReference: corev1.ObjectReference{
+						APIVersion: apiv1alpha1.GroupVersion.String(), Kind: "Static", Name: "postgres"},
+						Maps: map[string]string{"DATABASE_HOST": "host"}},
+				},
+				ConsumesConfigMapName: "cluster-consumer-consumed",
+				ConsumesSecretName:    "cluster-consumer-consumed",
+			}},
+		}
+		Expect(k8sClient.Create(ctx, static)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, static)).To(Succeed())
+		})
+
+		consumer := NewConsumerReconciler()
+		consumer.clusters = registry
+		controllerReconciler := NewStaticReconciler(
+			reconcilerConfig, validationReconciler, NewStaticDataReconciler(nil),
+			consumer, consumerSecretReconcilerInstance, consumerConfigMapReconcilerInstance,
+			producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+			statusReconcilerInstance)
+		Eventually(func(g Gomega) {
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(static),
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			consumed := new(corev1.ConfigMap)
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{
+				Namespace: "default", Name: "cluster-consumer-consumed"}, consumed)).To(Succeed())
+			g.Expect(consumed.Data).To(HaveKeyWithValue("DATABASE_HOST", "postgres.staging.svc"))
+		}).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(static), static)).To(Succeed())
+		Expect(static.Status.ConsumedKeys).To(HaveLen(1))
+		Expect(static.Status.ConsumedKeys[0].Delegate).To(Equal(&corev1.ObjectReference{
+			Kind: "Cluster", Name: secret.Name, Namespace: remoteNamespace}))
+	})
+
+	It("should stop a remote cluster which is not referred anymore", func() {
+		remote, err := registry.Get(referrer, secret, "default")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = registry.Get("other", secret, "default")
+		Expect(err).NotTo(HaveOccurred())
+
+		registry.Release(referrer, time.Now())
+		Expect(remote.ctx.Err()).NotTo(HaveOccurred())
+
+		registry.Release("other", time.Now())
+		Expect(remote.ctx.Err()).To(MatchError(context.Canceled))
+		restarted, err := registry.Get(referrer, secret, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restarted).NotTo(BeIdenticalTo(remote))
+
+		By("forgetting referrers which are not seen for a long time")
+		registry.now = func() time.Time { return time.Now().Add(2 * remoteClusterReferrerTTL) }
+		DeferCleanup(func() { registry.now = time.Now })
+		registry.sweep()
+		Expect(restarted.ctx.Err()).To(MatchError(context.Canceled))
+	})
+
+	It("should fail when a Secret has no kubeconfig", func() {
+		_, err := registry.Get(referrer, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"},
+		}, remoteNamespace)
+		Expect(err).To(HaveOccurred())
+	})
+})
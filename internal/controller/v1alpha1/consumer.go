@@ -18,14 +18,19 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	"reconciler.io/runtime/reconcilers"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -37,11 +42,28 @@ import (
 
 type consumedDelegate struct {
 	v1alpha1.ConsumesSpec
-	Delegate corev1.ObjectReference
+	// Delegates is a delegate each env is consumed from.
+	Delegates map[string]corev1.ObjectReference
+	// Statuses is a status of each env which is not consumed successfully, e.g. defaulted.
+	Statuses map[string]v1alpha1.ConsumedStatus
+	// Imported is a set of envs imported by ImportSpec.
+	Imported map[string]struct{}
+}
+
+func newConsumedDelegate(consumes v1alpha1.ConsumesSpec) consumedDelegate {
+	consumed := consumedDelegate{
+		ConsumesSpec: consumes,
+		Delegates:    make(map[string]corev1.ObjectReference, len(consumes.Maps)),
+		Statuses:     make(map[string]v1alpha1.ConsumedStatus),
+		Imported:     make(map[string]struct{}),
+	}
+	consumed.Maps = make(map[string]string, len(consumes.Maps))
+	return consumed
 }
 
 func NewConsumerReconciler() *ConsumerReconciler {
 	r := new(ConsumerReconciler)
+	r.clusters = NewClusterRegistry()
 	r.workloadReconciler = workloadReconciler{
 		Name:  "ConsumerReconciler",
 		Sync:  r.Sync,
@@ -58,22 +80,52 @@ func NewConsumerReconciler() *ConsumerReconciler {
 // for further processing by ConsumerConfigMapReconciler and ConsumerSecretReconciler.
 type ConsumerReconciler struct {
 	workloadReconciler
+	clusters *ClusterRegistry
+	// ShowEventValues allows events to show values of changed envs which are not sensitive.
+	ShowEventValues bool
+	// MetricsSensitiveKeys allows metrics to be labeled with names of envs which are not consumed,
+	// it is not known whether they are sensitive.
+	MetricsSensitiveKeys bool
 }
 
-func (r *ConsumerReconciler) Setup(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
+func (r *ConsumerReconciler) Setup(ctx context.Context, mgr ctrl.Manager, builder *builder.Builder) error {
 	builder.Watches(new(corev1.Secret), reconcilers.EnqueueTracked(ctx))
 	builder.Watches(new(corev1.ConfigMap), reconcilers.EnqueueTracked(ctx))
 	builder.Watches(new(corev1.Namespace), reconcilers.EnqueueTracked(ctx))
+	remoteSource, err := r.clusters.Setup(ctx, mgr)
+	if err != nil {
+		return err
+	}
+	builder.WatchesRawSource(remoteSource)
 	return nil
 }
 
 func (r *ConsumerReconciler) Sync(ctx context.Context, resource *v1alpha1.Tensegrity) (err error) {
+	previousKeys := resource.Status.ConsumedKeys
 	if len(resource.Spec.Consumes) == 0 {
+		if len(resource.Status.ConsumedConfigMapName) > 0 || len(resource.Status.ConsumedSecretName) > 0 {
+			if err = r.recordChanges(ctx, resource, previousKeys, nil, nil); err != nil {
+				return err
+			}
+		}
 		resource.Status.ClearConsumes()
+		v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityDegraded)
+		r.clusters.Release(resource.UID, time.Now())
 		return nil
 	}
 
+	resolved := time.Now()
 	keys, sensitiveKeys, err := r.getKeys(ctx, resource)
+	if err == nil || errors.Is(err, reconcilers.ErrHaltSubReconcilers) {
+		// remote clusters of delegates which are not looked up anymore are released
+		r.clusters.Release(resource.UID, resolved)
+	}
+	if err == nil {
+		err = r.renderTemplates(resource, keys, sensitiveKeys)
+	}
+	if err == nil {
+		err = r.recordChanges(ctx, resource, previousKeys, keys, sensitiveKeys)
+	}
 	if len(keys) > 0 && err == nil {
 		reconcilers.StashValue(ctx, consumerConfigMapKeysStashKey, keys)
 		reconcilers.StashValue(ctx, consumerConfigMapNameStashKey, resource.Spec.ConsumesConfigMapName)
@@ -113,7 +165,29 @@ func (r *ConsumerReconciler) getKeys(
 		switch delegate.Kind {
 		case "Namespace":
 			if err = r.getKeysFromNamespace(
-				ctx, delegate, consumesByRef, consumedByRef, keys, sensitiveKeys); err != nil {
+				ctx, delegate.ObjectReference, consumesByRef, consumedByRef, keys, sensitiveKeys); err != nil {
+
+				return nil, nil, err
+			}
+		case "NamespaceSelector":
+			var namespaces []corev1.Namespace
+			if namespaces, err = r.selectNamespaces(ctx, delegate); err != nil {
+				return nil, nil, err
+			}
+			for _, namespace := range namespaces {
+				if len(consumesByRef) == 0 {
+					break
+				}
+				namespaceRef := corev1.ObjectReference{Kind: "Namespace", Name: namespace.Name}
+				if err = r.getKeysFromNamespace(
+					ctx, namespaceRef, consumesByRef, consumedByRef, keys, sensitiveKeys); err != nil {
+
+					return nil, nil, err
+				}
+			}
+		case "Cluster":
+			if err = r.getKeysFromCluster(
+				ctx, resource, delegate.ObjectReference, consumesByRef, consumedByRef, keys, sensitiveKeys); err != nil {
 
 				return nil, nil, err
 			}
@@ -122,20 +196,261 @@ func (r *ConsumerReconciler) getKeys(
 		}
 	}
 
+	for consumesRef, consumes := range consumesByRef {
+		consumed, ok := consumedByRef[consumesRef]
+		if !ok {
+			consumed = newConsumedDelegate(consumes)
+		}
+		_, imported := consumedByRef[consumesRef]
+		pending := r.defaultEnvs(consumes, consumes.Maps, consumed, keys)
+		if len(consumed.Maps) > 0 {
+			consumedByRef[consumesRef] = consumed
+		}
+		if len(pending) == 0 && (consumes.Import == nil || imported) {
+			delete(consumesByRef, consumesRef)
+		} else {
+			consumes.Maps = pending
+			consumesByRef[consumesRef] = consumes
+		}
+	}
+
+	previous := resource.Status.ConsumedKeys
+	resource.Status.ConsumedKeys = make([]v1alpha1.ConsumedKeyStatus, 0, len(resource.Spec.Consumes))
 	for consumedRef, consumed := range consumedByRef {
-		r.updateKeyStatus(resource, ptr.To(consumed.Delegate), consumedRef, consumed.ConsumesSpec, nil)
+		r.updateKeyStatus(resource, consumed, consumedRef, nil)
 	}
 
 	err = errors.Errorf("consumed key by reference is not found")
 	for consumesRef, consumes := range consumesByRef {
-		r.updateKeyStatus(resource, nil, consumesRef, consumes, err)
+		failed := consumedDelegate{ConsumesSpec: consumes}
+		if _, imported := consumedByRef[consumesRef]; consumes.Import != nil && !imported {
+			failed.Maps = make(map[string]string, len(consumes.Maps)+1)
+			for env, key := range consumes.Maps {
+				failed.Maps[env] = key
+			}
+			failed.Maps[consumes.Import.Prefix+"*"] = "*"
+		}
+		r.updateKeyStatus(resource, failed, consumesRef, err)
 	}
 	resource.Status.SortConsumes()
 	r.updateStatus(resource)
-	if len(consumesByRef) > 0 {
-		return nil, nil, reconcilers.ErrHaltSubReconcilers
+	r.observeKeys(ctx, resource, previous)
+	if len(consumesByRef) == 0 {
+		v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityDegraded)
+		return keys, sensitiveKeys, nil
+	}
+
+	if resource.Spec.ConsumesFailurePolicy == v1alpha1.RetainLastKnownGood {
+		staleEnvs, retained, err := r.retainKeys(ctx, resource, consumesByRef, consumedByRef, keys, sensitiveKeys)
+		if err != nil {
+			return nil, nil, err
+		}
+		if retained {
+			r.updateDegradedStatus(resource, staleEnvs)
+			return keys, sensitiveKeys, nil
+		}
+	}
+	v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityDegraded)
+	return nil, nil, reconcilers.ErrHaltSubReconcilers
+}
+
+// retainKeys puts last known good values of not resolved envs from the consumed ConfigMap and Secret
+// into keys, it returns false when some of envs have never been consumed, so there is nothing to retain.
+func (r *ConsumerReconciler) retainKeys(
+	ctx context.Context, resource *v1alpha1.Tensegrity,
+	consumesByRef map[corev1.ObjectReference]v1alpha1.ConsumesSpec,
+	consumedByRef map[corev1.ObjectReference]consumedDelegate,
+	keys, sensitiveKeys map[string]string) (staleEnvs []string, retained bool, err error) {
+
+	data, sensitiveData, err := getOwnedData(ctx, resource.Namespace,
+		resource.Spec.ConsumesConfigMapName, consumerConfigMapReconcilerName,
+		resource.Spec.ConsumesSecretName, consumerSecretReconcilerName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for consumesRef, consumes := range consumesByRef {
+		if _, imported := consumedByRef[consumesRef]; consumes.Import != nil && !imported {
+			// envs imported before are not known, so all consumed envs with the import prefix are retained
+			if len(consumes.Import.Prefix) == 0 {
+				return nil, false, nil
+			}
+			for env, v := range data {
+				if _, ok := keys[env]; !ok && strings.HasPrefix(env, consumes.Import.Prefix) {
+					keys[env] = v
+					staleEnvs = append(staleEnvs, env)
+				}
+			}
+			for env, v := range sensitiveData {
+				if _, ok := sensitiveKeys[env]; !ok && strings.HasPrefix(env, consumes.Import.Prefix) {
+					sensitiveKeys[env] = v
+					staleEnvs = append(staleEnvs, env)
+				}
+			}
+		}
+		for env := range consumes.Maps {
+			if v, ok := data[env]; ok {
+				keys[env] = v
+			} else if v, ok := sensitiveData[env]; ok {
+				sensitiveKeys[env] = v
+			} else {
+				return nil, false, nil
+			}
+			staleEnvs = append(staleEnvs, env)
+		}
+	}
+	sort.Strings(staleEnvs)
+	return staleEnvs, true, nil
+}
+
+// recordChanges records events when consumed envs are added, changed or removed, or switch their delegates,
+// envs changed in existing consumed ConfigMap and Secret are stashed for a workload rollout. Delegate switches of
+// native workload resources are not recorded, since previous delegates are taken from the status, which is not
+// persisted for them.
+func (r *ConsumerReconciler) recordChanges(
+	ctx context.Context, resource *v1alpha1.Tensegrity, previousKeys []v1alpha1.ConsumedKeyStatus,
+	keys, sensitiveKeys map[string]string) error {
+
+	data, sensitiveData, err := getOwnedData(ctx, resource.Namespace,
+		resource.Spec.ConsumesConfigMapName, consumerConfigMapReconcilerName,
+		resource.Spec.ConsumesSecretName, consumerSecretReconcilerName)
+	if err != nil {
+		return err
+	}
+
+	changes := diffKeys(data, sensitiveData, keys, sensitiveKeys)
+	if len(changes) > 0 {
+		recordEvent(ctx, resource, corev1.EventTypeNormal, ConsumedKeysChangedReason,
+			"Consumed envs changed: %s.", formatKeyChanges(changes, r.ShowEventValues))
+	}
+	if len(data) > 0 || len(sensitiveData) > 0 {
+		stashConsumedChanges(ctx, keyChangeNames(changes))
+	} else {
+		stashConsumedChanges(ctx, nil)
+	}
+
+	type delegateSwitch struct {
+		from, to string
+	}
+	previousDelegates := make(map[string]*corev1.ObjectReference, len(previousKeys))
+	for _, consumed := range previousKeys {
+		if consumed.Status == v1alpha1.ConsumedSuccess {
+			previousDelegates[consumed.Env] = consumed.Delegate
+		}
+	}
+	var switches []delegateSwitch
+	switchedEnvs := make(map[delegateSwitch][]string)
+	for _, consumed := range resource.Status.ConsumedKeys {
+		previous, ok := previousDelegates[consumed.Env]
+		if !ok || previous == nil || consumed.Delegate == nil || *previous == *consumed.Delegate ||
+			consumed.Status != v1alpha1.ConsumedSuccess {
+			continue
+		}
+		s := delegateSwitch{from: formatDelegate(previous), to: formatDelegate(consumed.Delegate)}
+		if _, ok = switchedEnvs[s]; !ok {
+			switches = append(switches, s)
+		}
+		switchedEnvs[s] = append(switchedEnvs[s], consumed.Env)
+	}
+	for _, s := range switches {
+		envs := switchedEnvs[s]
+		sort.Strings(envs)
+		recordEvent(ctx, resource, corev1.EventTypeNormal, DelegateSwitchedReason,
+			"Envs %s switched delegate from %s to %s.", strings.Join(envs, ", "), s.from, s.to)
+	}
+	return nil
+}
+
+func (r *ConsumerReconciler) updateDegradedStatus(resource *v1alpha1.Tensegrity, staleEnvs []string) {
+	since := metav1.Now()
+	if current := v1alpha1.GetTensegrityCondition(
+		resource.Status, v1alpha1.TensegrityDegraded); current != nil && current.Status == corev1.ConditionTrue {
+		since = current.LastTransitionTime
+	}
+	message := fmt.Sprintf(v1alpha1.KeysStaleMessage, since.UTC().Format(time.RFC3339), strings.Join(staleEnvs, ", "))
+	condition := v1alpha1.NewTensegrityCondition(
+		v1alpha1.TensegrityDegraded, corev1.ConditionTrue, v1alpha1.KeysStaleReason, message)
+	v1alpha1.SetTensegrityCondition(&resource.Status, *condition)
+}
+
+// renderTemplates builds envs of TemplateSpec from consumed envs, a result goes into sensitive keys
+// when any of used envs is sensitive, so it is never exposed by the consumed ConfigMap.
+func (r *ConsumerReconciler) renderTemplates(resource *v1alpha1.Tensegrity, keys, sensitiveKeys map[string]string) error {
+	if len(resource.Spec.Templates) == 0 {
+		return nil
+	}
+
+	data := make(map[string]string, len(keys)+len(sensitiveKeys))
+	sensitiveEnvs := make(map[string]struct{}, len(sensitiveKeys))
+	for env, v := range keys {
+		data[env] = v
+	}
+	for env, v := range sensitiveKeys {
+		decoded, _ := base64.StdEncoding.DecodeString(v)
+		data[env] = string(decoded)
+		sensitiveEnvs[env] = struct{}{}
+	}
+
+	for _, t := range resource.Spec.Templates {
+		tmpl, err := t.Parse()
+		if err != nil {
+			return errors.Wrapf(err, "invalid template of env %s", t.Env)
+		}
+		var value bytes.Buffer
+		if err = tmpl.Execute(&value, data); err != nil {
+			return errors.Wrapf(err, "unable to render template of env %s", t.Env)
+		}
+
+		sensitive := false
+		envs, all := v1alpha1.TemplateEnvs(tmpl)
+		if all {
+			sensitive = len(sensitiveEnvs) > 0
+		}
+		for _, env := range envs {
+			if _, ok := sensitiveEnvs[env]; ok {
+				sensitive = true
+			}
+		}
+		if sensitive {
+			sensitiveKeys[t.Env] = base64.StdEncoding.EncodeToString(value.Bytes())
+		} else {
+			keys[t.Env] = value.String()
+		}
+	}
+	return nil
+}
+
+// selectNamespaces lists namespaces matching a NamespaceSelector delegate ordered by PriorityLabel,
+// higher first, and then by name. The selector is tracked, so namespaces label changes are watched.
+func (r *ConsumerReconciler) selectNamespaces(
+	ctx context.Context, delegate v1alpha1.DelegateSpec) ([]corev1.Namespace, error) {
+
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	selector, err := metav1.LabelSelectorAsSelector(delegate.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid namespace selector")
+	}
+	namespaces := new(corev1.NamespaceList)
+	if err = config.TrackAndList(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(namespaces.Items, func(i, j int) bool {
+		left, right := namespacePriority(&namespaces.Items[i]), namespacePriority(&namespaces.Items[j])
+		if left != right {
+			return left > right
+		}
+		return namespaces.Items[i].Name < namespaces.Items[j].Name
+	})
+	return namespaces.Items, nil
+}
+
+func namespacePriority(namespace *corev1.Namespace) int {
+	value, ok := namespace.Labels[v1alpha1.PriorityLabel]
+	if !ok {
+		value = namespace.Annotations[v1alpha1.PriorityLabel]
 	}
-	return keys, sensitiveKeys, nil
+	priority, _ := strconv.Atoi(value)
+	return priority
 }
 
 func (r *ConsumerReconciler) getKeysFromNamespace(
@@ -154,25 +469,95 @@ func (r *ConsumerReconciler) getKeysFromNamespace(
 		return err
 	}
 
+	return r.getKeysFromDelegate(
+		ctx, config, nil, namespace.Name, delegate, consumesByRef, consumedByRef, keys, sensitiveKeys)
+}
+
+func (r *ConsumerReconciler) getKeysFromCluster(
+	ctx context.Context, resource *v1alpha1.Tensegrity, delegate corev1.ObjectReference,
+	consumesByRef map[corev1.ObjectReference]v1alpha1.ConsumesSpec,
+	consumedByRef map[corev1.ObjectReference]consumedDelegate,
+	keys, sensitiveKeys map[string]string) error {
+
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	secret := new(corev1.Secret)
+	secret.SetName(delegate.Name)
+	secret.SetNamespace(resource.Namespace)
+	err := config.TrackAndGet(ctx, client.ObjectKeyFromObject(secret), secret)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	remote, err := r.clusters.Get(resource.UID, secret, delegate.Namespace)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, remoteClusterTimeout)
+	defer cancel()
+	return r.getKeysFromDelegate(
+		ctx, config.WithCluster(remote), remote, delegate.Namespace, delegate,
+		consumesByRef, consumedByRef, keys, sensitiveKeys)
+}
+
+// getKeysFromDelegate resolves consumed keys from a namespace of a local or remote cluster,
+// objects of a remote cluster are watched to enqueue tracking resources on changes.
+func (r *ConsumerReconciler) getKeysFromDelegate(
+	ctx context.Context, config reconcilers.Config, remote *RemoteCluster,
+	namespace string, delegate corev1.ObjectReference,
+	consumesByRef map[corev1.ObjectReference]v1alpha1.ConsumesSpec,
+	consumedByRef map[corev1.ObjectReference]consumedDelegate,
+	keys, sensitiveKeys map[string]string) (err error) {
+
+	ctx, span := startSpan(ctx, "DelegateLookup", delegateAttributes(delegate, namespace)...)
+	defer func() {
+		endSpan(span, err)
+		span.End()
+	}()
+
+	trackAndGet := func(consumesRef corev1.ObjectReference, obj client.Object) (err error) {
+		attributes := append(delegateAttributes(delegate, namespace), consumedAttributes(consumesRef)...)
+		ctx, span := startSpan(ctx, "TrackAndGet", append(attributes, objectAttributes(obj)...)...)
+		defer func() {
+			if k8serrors.IsNotFound(err) {
+				span.SetAttributes(objectFoundAttribute.Bool(false))
+			} else {
+				endSpan(span, err)
+			}
+			span.End()
+		}()
+		if remote != nil {
+			if err = remote.Watch(ctx, obj); err != nil {
+				return err
+			}
+		}
+		return config.TrackAndGet(ctx, client.ObjectKeyFromObject(obj), obj)
+	}
+
 ConsumesByRefLoop:
 	for consumesRef, consumes := range consumesByRef {
 		tensegrity := v1alpha1.TensegrityFromRef(consumesRef)
-		tensegrity.SetNamespace(namespace.Name)
-		err = config.TrackAndGet(ctx, client.ObjectKeyFromObject(tensegrity), tensegrity)
+		tensegrity.SetNamespace(namespace)
+		err := trackAndGet(consumesRef, tensegrity)
 		if k8serrors.IsNotFound(err) {
 			continue
 		} else if err != nil {
 			return err
 		}
 
+		// with KeyResolution each env resolves on its own, so missing produced objects are treated as empty
+		keyResolution := consumes.Resolution == v1alpha1.KeyResolution
+
 		configMap := new(corev1.ConfigMap)
 		if len(tensegrity.Status.ProducedConfigMapName) > 0 {
 			configMap.SetName(tensegrity.Status.ProducedConfigMapName)
-			configMap.SetNamespace(namespace.Name)
-			err = config.TrackAndGet(ctx, client.ObjectKeyFromObject(configMap), configMap)
-			if k8serrors.IsNotFound(err) {
+			configMap.SetNamespace(namespace)
+			err = trackAndGet(consumesRef, configMap)
+			if k8serrors.IsNotFound(err) && !keyResolution {
 				continue
-			} else if err != nil {
+			} else if err != nil && !k8serrors.IsNotFound(err) {
 				return err
 			}
 		}
@@ -180,55 +565,171 @@ ConsumesByRefLoop:
 		secret := new(corev1.Secret)
 		if len(tensegrity.Status.ProducedSecretName) > 0 {
 			secret.SetName(tensegrity.Status.ProducedSecretName)
-			secret.SetNamespace(namespace.Name)
-			err = config.TrackAndGet(ctx, client.ObjectKeyFromObject(secret), secret)
-			if k8serrors.IsNotFound(err) {
+			secret.SetNamespace(namespace)
+			err = trackAndGet(consumesRef, secret)
+			if k8serrors.IsNotFound(err) && !keyResolution {
 				continue
-			} else if err != nil {
+			} else if err != nil && !k8serrors.IsNotFound(err) {
 				return err
 			}
 		}
 
+		localMaps := make(map[string]string, len(consumes.Maps))
 		localKeys := make(map[string]string, len(configMap.Data))
 		localSensitiveKeys := make(map[string]string, len(secret.Data))
 		for env, key := range consumes.Maps {
 			if v, ok := configMap.Data[key]; ok {
+				localMaps[env] = key
 				localKeys[env] = v
 				continue
 			}
 			if v, ok := secret.Data[key]; ok {
+				localMaps[env] = key
 				localSensitiveKeys[env] = base64.StdEncoding.EncodeToString(v)
 				continue
 			}
-			continue ConsumesByRefLoop
+			if !keyResolution && !consumes.Envs[env].IsOptional() {
+				continue ConsumesByRefLoop
+			}
 		}
 
+		consumed, ok := consumedByRef[consumesRef]
+		if !ok {
+			consumed = newConsumedDelegate(consumes)
+		}
+		if consumes.Import != nil {
+			r.importEnvs(consumes, consumed, configMap, secret, localMaps, localKeys, localSensitiveKeys)
+		}
+		if len(localKeys) > 0 {
+			observeProducedChange(ctx, configMap)
+		}
+		if len(localSensitiveKeys) > 0 {
+			observeProducedChange(ctx, secret)
+		}
+		pending := make(map[string]string, len(consumes.Maps))
+		for env, key := range consumes.Maps {
+			pending[env] = key
+		}
 		for env, v := range localKeys {
 			keys[env] = v
+			consumed.Maps[env] = localMaps[env]
+			consumed.Delegates[env] = delegate
+			delete(pending, env)
 		}
 		for env, v := range localSensitiveKeys {
 			sensitiveKeys[env] = v
+			consumed.Maps[env] = localMaps[env]
+			consumed.Delegates[env] = delegate
+			delete(pending, env)
+		}
+		if !keyResolution {
+			// the reference is resolved by this delegate, so missing optional envs are not looked up further
+			pending = r.defaultEnvs(consumes, pending, consumed, keys)
 		}
-		consumedByRef[consumesRef] = consumedDelegate{
-			ConsumesSpec: consumes,
-			Delegate:     delegate,
+		if len(consumed.Maps) > 0 || consumes.Import != nil {
+			consumedByRef[consumesRef] = consumed
+		}
+		if len(pending) == 0 {
+			delete(consumesByRef, consumesRef)
+		} else {
+			consumes.Maps = pending
+			consumesByRef[consumesRef] = consumes
 		}
-		delete(consumesByRef, consumesRef)
 	}
 
 	return nil
 }
 
-func (r *ConsumerReconciler) updateKeyStatus(
-	resource *v1alpha1.Tensegrity, delegate *corev1.ObjectReference, ref corev1.ObjectReference,
-	consumes v1alpha1.ConsumesSpec, err error) {
+// importEnvs adds envs for all keys of produced ConfigMap and Secret by ImportSpec, explicitly mapped keys and envs,
+// and envs already consumed from previous delegates are not imported.
+func (r *ConsumerReconciler) importEnvs(
+	consumes v1alpha1.ConsumesSpec, consumed consumedDelegate,
+	configMap *corev1.ConfigMap, secret *corev1.Secret,
+	localMaps, localKeys, localSensitiveKeys map[string]string) {
 
-	resource.Status.ConsumedKeys = make([]v1alpha1.ConsumedKeyStatus, 0, len(resource.Spec.Produces))
+	explicitKeys := make(map[string]struct{}, len(consumes.Maps))
+	explicitEnvs := make(map[string]struct{}, len(consumes.Maps))
 	for env, key := range consumes.Maps {
+		explicitKeys[key] = struct{}{}
+		explicitEnvs[env] = struct{}{}
+	}
+	for env, key := range consumed.Maps {
+		if _, ok := consumed.Imported[env]; !ok {
+			explicitKeys[key] = struct{}{}
+			explicitEnvs[env] = struct{}{}
+		}
+	}
+
+	importEnv := func(key string) (string, bool) {
+		if _, ok := explicitKeys[key]; ok {
+			return "", false
+		}
+		env := consumes.Import.EnvName(key)
+		if _, ok := explicitEnvs[env]; ok {
+			return "", false
+		}
+		if _, ok := consumed.Maps[env]; ok {
+			return "", false
+		}
+		if _, ok := localMaps[env]; ok {
+			return "", false
+		}
+		consumed.Imported[env] = struct{}{}
+		localMaps[env] = key
+		return env, true
+	}
+	for key, v := range configMap.Data {
+		if env, ok := importEnv(key); ok {
+			localKeys[env] = v
+		}
+	}
+	for key, v := range secret.Data {
+		if env, ok := importEnv(key); ok {
+			localSensitiveKeys[env] = base64.StdEncoding.EncodeToString(v)
+		}
+	}
+}
+
+// defaultEnvs sets defaults of pending optional envs or leaves them out,
+// it returns pending envs which are required.
+func (r *ConsumerReconciler) defaultEnvs(
+	consumes v1alpha1.ConsumesSpec, pending map[string]string,
+	consumed consumedDelegate, keys map[string]string) map[string]string {
+
+	required := make(map[string]string, len(pending))
+	for env, key := range pending {
+		settings := consumes.Envs[env]
+		switch {
+		case settings.Default != nil:
+			keys[env] = *settings.Default
+			consumed.Maps[env] = key
+			consumed.Statuses[env] = v1alpha1.ConsumedDefaulted
+		case settings.Optional:
+			consumed.Maps[env] = key
+			consumed.Statuses[env] = v1alpha1.ConsumedOmitted
+		default:
+			required[env] = key
+		}
+	}
+	return required
+}
+
+func (r *ConsumerReconciler) updateKeyStatus(
+	resource *v1alpha1.Tensegrity, consumed consumedDelegate, ref corev1.ObjectReference, err error) {
+
+	for env, key := range consumed.Maps {
+		var delegate *corev1.ObjectReference
+		if d, ok := consumed.Delegates[env]; ok {
+			delegate = ptr.To(d)
+		}
+		status := v1alpha1.ConsumedSuccess
+		if s, ok := consumed.Statuses[env]; ok {
+			status = s
+		}
 		consumedKeyStatus := v1alpha1.ConsumedKeyStatus{
 			ObjectReference: ref,
 			Delegate:        delegate,
-			Status:          v1alpha1.ConsumedSuccess,
+			Status:          status,
 			Key:             key,
 			Env:             env,
 		}
@@ -240,6 +741,36 @@ func (r *ConsumerReconciler) updateKeyStatus(
 	}
 }
 
+// observeKeys counts envs which started to be consumed from delegates, and envs which started to fail,
+// comparing with previous statuses of consumed envs. Envs of native workload resources are not counted, since
+// their previous statuses are not persisted, and would be counted on every reconcile.
+func (r *ConsumerReconciler) observeKeys(
+	ctx context.Context, resource *v1alpha1.Tensegrity, previous []v1alpha1.ConsumedKeyStatus) {
+
+	if !statusPersisted(ctx) {
+		return
+	}
+
+	previousByEnv := make(map[string]v1alpha1.ConsumedKeyStatus, len(previous))
+	for _, consumed := range previous {
+		previousByEnv[consumed.Env] = consumed
+	}
+	for _, consumed := range resource.Status.ConsumedKeys {
+		was, ok := previousByEnv[consumed.Env]
+		switch {
+		case consumed.Status == v1alpha1.ConsumedFailure:
+			if !ok || was.Status != v1alpha1.ConsumedFailure {
+				observeFailure(ctx, resource, consumedDirection, consumed.Env, true,
+					r.MetricsSensitiveKeys, NotFoundFailure)
+			}
+		case consumed.Delegate != nil:
+			if !ok || was.Delegate == nil || *was.Delegate != *consumed.Delegate {
+				observeDelegateHit(*consumed.Delegate)
+			}
+		}
+	}
+}
+
 func (r *ConsumerReconciler) updateStatus(resource *v1alpha1.Tensegrity) {
 	resource.Status.Consumed = ptr.To(v1alpha1.ConsumedSuccess)
 	condition := v1alpha1.NewTensegrityCondition(
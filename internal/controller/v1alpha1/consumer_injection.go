@@ -0,0 +1,138 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+const (
+	injectedSecretPrefix    = "Secret/"
+	injectedConfigMapPrefix = "ConfigMap/"
+)
+
+// InjectConsumed adds consumed Secret and ConfigMap from context to envFrom of a pod template of a native
+// workload resource, and removes ones injected before and tracked by InjectedAnnotation.
+// It returns true if a workload resource has to be updated.
+func InjectConsumed(ctx context.Context, meta *metav1.ObjectMeta, template *corev1.PodTemplateSpec) bool {
+	_, annotated := meta.Annotations[v1alpha1.SpecAnnotation]
+	injected := meta.Annotations[v1alpha1.InjectedAnnotation]
+	if !annotated && len(injected) == 0 {
+		return false
+	}
+
+	originalMeta := meta.DeepCopy()
+	originalTemplate := template.DeepCopy()
+
+	removeInjected(template.Spec.InitContainers, injected)
+	removeInjected(template.Spec.Containers, injected)
+	for _, key := range []string{string(consumerSecretVersionStashKey), string(consumerConfigMapVersionStashKey)} {
+		delete(meta.Annotations, key)
+		delete(template.Annotations, key)
+	}
+	delete(meta.Annotations, v1alpha1.InjectedAnnotation)
+
+	var sources []string
+	var envFrom []corev1.EnvFromSource
+	if name := ConsumerSecretNameFromContext(ctx); len(name) > 0 {
+		sources = append(sources, injectedSecretPrefix+name)
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		})
+		key, value := ConsumerSecretAnnotationFromContext(ctx)
+		setVersionAnnotation(meta, template, key, value)
+	}
+	if name := ConsumerConfigMapNameFromContext(ctx); len(name) > 0 {
+		sources = append(sources, injectedConfigMapPrefix+name)
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		})
+		key, value := ConsumerConfigMapAnnotationFromContext(ctx)
+		setVersionAnnotation(meta, template, key, value)
+	}
+
+	if len(envFrom) > 0 {
+		for i, container := range template.Spec.InitContainers {
+			template.Spec.InitContainers[i].EnvFrom = append(container.EnvFrom, envFrom...)
+		}
+		for i, container := range template.Spec.Containers {
+			template.Spec.Containers[i].EnvFrom = append(container.EnvFrom, envFrom...)
+		}
+		sort.Strings(sources)
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[v1alpha1.InjectedAnnotation] = strings.Join(sources, ",")
+	}
+
+	return !equality.Semantic.DeepEqual(originalMeta, meta) ||
+		!equality.Semantic.DeepEqual(originalTemplate, template)
+}
+
+func removeInjected(containers []corev1.Container, injected string) {
+	if len(injected) == 0 {
+		return
+	}
+	sources := make(map[string]struct{})
+	for _, source := range strings.Split(injected, ",") {
+		sources[source] = struct{}{}
+	}
+	for i, container := range containers {
+		var envFrom []corev1.EnvFromSource
+		for _, source := range container.EnvFrom {
+			if source.SecretRef != nil {
+				if _, ok := sources[injectedSecretPrefix+source.SecretRef.Name]; ok {
+					continue
+				}
+			}
+			if source.ConfigMapRef != nil {
+				if _, ok := sources[injectedConfigMapPrefix+source.ConfigMapRef.Name]; ok {
+					continue
+				}
+			}
+			envFrom = append(envFrom, source)
+		}
+		containers[i].EnvFrom = envFrom
+	}
+}
+
+func setVersionAnnotation(meta *metav1.ObjectMeta, template *corev1.PodTemplateSpec, key, value string) {
+	if len(key) == 0 || len(value) == 0 {
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[key] = value
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	template.Annotations[key] = value
+}
@@ -0,0 +1,254 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	k8sv1alpha1 "github.com/fastforgeinc/tensegrity/api/k8s/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// newTensegrityLists returns lists of all kinds of Tensegrity resources which may consume keys.
+func newTensegrityLists() []client.ObjectList {
+	return []client.ObjectList{
+		new(k8sv1alpha1.CronJobList),
+		new(k8sv1alpha1.DaemonSetList),
+		new(k8sv1alpha1.DeploymentList),
+		new(k8sv1alpha1.JobList),
+		new(k8sv1alpha1.StatefulSetList),
+		new(v1alpha1.StaticList),
+	}
+}
+
+// newTensegrityObjects returns objects of all kinds of Tensegrity resources which may consume keys.
+func newTensegrityObjects() []client.Object {
+	return []client.Object{
+		new(k8sv1alpha1.CronJob),
+		new(k8sv1alpha1.DaemonSet),
+		new(k8sv1alpha1.Deployment),
+		new(k8sv1alpha1.Job),
+		new(k8sv1alpha1.StatefulSet),
+		new(v1alpha1.Static),
+	}
+}
+
+// toTensegrity converts a Tensegrity resource of any kind into the duck type.
+func toTensegrity(obj runtime.Object) (*v1alpha1.Tensegrity, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	resource := new(v1alpha1.Tensegrity)
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// consumedProducersIndex indexes Tensegrity resources by producers they consume keys from.
+const consumedProducersIndex = "status.consumedProducers"
+
+type consumerKey struct {
+	consumer corev1.ObjectReference
+	delegate corev1.ObjectReference
+}
+
+type producerKey struct {
+	schema.GroupKind
+	types.NamespacedName
+}
+
+func (k producerKey) String() string {
+	return k.GroupKind.String() + "/" + k.NamespacedName.String()
+}
+
+// consumedProducers returns producers a resource consumes keys from through Namespace delegates, which include
+// namespaces selected by NamespaceSelector delegates. Producers consumed through Cluster delegates are
+// in other clusters, so they are not returned.
+func consumedProducers(consumer *v1alpha1.Tensegrity) []producerKey {
+	var producers []producerKey
+	seen := make(map[producerKey]struct{})
+	for _, consumed := range consumer.Status.ConsumedKeys {
+		if consumed.Status != v1alpha1.ConsumedSuccess || consumed.Delegate == nil ||
+			consumed.Delegate.Kind != "Namespace" {
+			continue
+		}
+		producer := producerKey{
+			GroupKind:      schema.FromAPIVersionAndKind(consumed.APIVersion, consumed.Kind).GroupKind(),
+			NamespacedName: types.NamespacedName{Namespace: consumed.Delegate.Name, Name: consumed.Name},
+		}
+		if _, ok := seen[producer]; !ok {
+			seen[producer] = struct{}{}
+			producers = append(producers, producer)
+		}
+	}
+	return producers
+}
+
+// setupConsumers indexes Tensegrity resources of all kinds by producers they consume keys from, once per manager,
+// and watches them, so only producers consumed from before and after a change are reconciled.
+func (r *ProducerReconciler) setupConsumers(ctx context.Context, mgr ctrl.Manager, builder *builder.Builder) error {
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	gvk, err := config.GroupVersionKindFor(reconcilers.RetrieveOriginalResourceType(ctx))
+	if err != nil {
+		return err
+	}
+	r.consumersOnce.Do(func() {
+		r.consumersErr = indexConsumers(ctx, mgr)
+	})
+	if r.consumersErr != nil {
+		return r.consumersErr
+	}
+
+	mapper := func(_ context.Context, obj client.Object) []reconcile.Request {
+		consumer, err := toTensegrity(obj)
+		if err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, producer := range consumedProducers(consumer) {
+			if producer.GroupKind == gvk.GroupKind() {
+				requests = append(requests, reconcile.Request{NamespacedName: producer.NamespacedName})
+			}
+		}
+		return requests
+	}
+	for _, obj := range newTensegrityObjects() {
+		builder.Watches(obj, handler.EnqueueRequestsFromMapFunc(mapper))
+	}
+	return nil
+}
+
+// indexConsumers indexes Tensegrity resources of all kinds by producers they consume keys from.
+func indexConsumers(ctx context.Context, mgr ctrl.Manager) error {
+	for _, obj := range newTensegrityObjects() {
+		if err := mgr.GetFieldIndexer().IndexField(ctx, obj, consumedProducersIndex, func(obj client.Object) []string {
+			consumer, err := toTensegrity(obj)
+			if err != nil {
+				return nil
+			}
+			var values []string
+			for _, producer := range consumedProducers(consumer) {
+				values = append(values, producer.String())
+			}
+			return values
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateConsumers lists resources of the same cluster consuming keys produced by a resource, they are looked up
+// by consumedProducersIndex, and setupConsumers reconciles the producer when consumers come and go.
+func (r *ProducerReconciler) updateConsumers(ctx context.Context, resource *v1alpha1.Tensegrity) error {
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	producer := schema.GroupKind{Kind: resourceKind(ctx, resource)}
+	if gvk, err := config.GroupVersionKindFor(reconcilers.RetrieveOriginalResourceType(ctx)); err == nil {
+		producer = gvk.GroupKind()
+	}
+	index := producerKey{GroupKind: producer, NamespacedName: client.ObjectKeyFromObject(resource)}.String()
+
+	keys := make(map[consumerKey]map[string]struct{})
+	for _, list := range newTensegrityLists() {
+		if err := config.List(ctx, list, client.MatchingFields{consumedProducersIndex: index}); err != nil {
+			return err
+		}
+		gvk, err := config.GroupVersionKindFor(list)
+		if err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			consumer, err := toTensegrity(item)
+			if err != nil {
+				return err
+			}
+			ref := corev1.ObjectReference{
+				APIVersion: gvk.GroupVersion().String(),
+				Kind:       gvk.Kind[:len(gvk.Kind)-len("List")],
+				Namespace:  consumer.Namespace,
+				Name:       consumer.Name,
+			}
+			for _, consumed := range consumer.Status.ConsumedKeys {
+				if consumed.Status != v1alpha1.ConsumedSuccess || consumed.Delegate == nil ||
+					consumed.Delegate.Kind != "Namespace" || consumed.Delegate.Name != resource.Namespace ||
+					consumed.Name != resource.Name ||
+					schema.FromAPIVersionAndKind(consumed.APIVersion, consumed.Kind).GroupKind() != producer {
+					continue
+				}
+				key := consumerKey{consumer: ref, delegate: *consumed.Delegate}
+				if _, ok := keys[key]; !ok {
+					keys[key] = make(map[string]struct{})
+				}
+				keys[key][consumed.Key] = struct{}{}
+			}
+		}
+	}
+
+	consumers := make([]v1alpha1.ConsumerStatus, 0, len(keys))
+	for key, consumedKeys := range keys {
+		consumer := v1alpha1.ConsumerStatus{
+			ObjectReference: key.consumer,
+			Delegate:        key.delegate,
+			Keys:            make([]string, 0, len(consumedKeys)),
+		}
+		for consumedKey := range consumedKeys {
+			consumer.Keys = append(consumer.Keys, consumedKey)
+		}
+		sort.Strings(consumer.Keys)
+		consumers = append(consumers, consumer)
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		left, right := consumers[i].ObjectReference, consumers[j].ObjectReference
+		if left.Namespace != right.Namespace {
+			return left.Namespace < right.Namespace
+		}
+		if left.Kind != right.Kind {
+			return left.Kind < right.Kind
+		}
+		return left.Name < right.Name
+	})
+
+	resource.Status.ConsumerCount = int32(len(consumers))
+	if len(consumers) > v1alpha1.MaxConsumers {
+		consumers = consumers[:v1alpha1.MaxConsumers]
+	}
+	if len(consumers) == 0 {
+		consumers = nil
+	}
+	resource.Status.Consumers = consumers
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reconciler.io/runtime/reconcilers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+const (
+	ConsumedKeysChangedReason = "ConsumedKeysChanged"
+	ProducedKeysChangedReason = "ProducedKeysChanged"
+	DelegateSwitchedReason    = "DelegateSwitched"
+	RolloutTriggeredReason    = "RolloutTriggered"
+	SealedKeysOutdatedReason  = "SealedKeysOutdated"
+)
+
+// eventMessageLimit is a maximum length of an event message, longer messages are truncated.
+const eventMessageLimit = 1024
+
+const consumedChangesStashKey reconcilers.StashKey = "tensegrity.fastforge.io/consumedChanges"
+
+type keyChange struct {
+	name      string
+	action    string
+	previous  *string
+	current   *string
+	sensitive bool
+}
+
+func (c keyChange) String(showValues bool) string {
+	if !showValues || c.sensitive {
+		return c.name + " " + c.action
+	}
+	switch {
+	case c.previous != nil && c.current != nil:
+		return fmt.Sprintf("%s %s (%q -> %q)", c.name, c.action, *c.previous, *c.current)
+	case c.current != nil:
+		return fmt.Sprintf("%s %s (%q)", c.name, c.action, *c.current)
+	default:
+		return fmt.Sprintf("%s %s (%q)", c.name, c.action, *c.previous)
+	}
+}
+
+// diffKeys compares previous and current keys ordered by name, a key is sensitive when it is sensitive
+// before or after the change, so its values are never shown.
+func diffKeys(previous, previousSensitive, current, currentSensitive map[string]string) []keyChange {
+	lookup := func(keys, sensitiveKeys map[string]string, name string) (*string, bool) {
+		if v, ok := sensitiveKeys[name]; ok {
+			return &v, true
+		}
+		if v, ok := keys[name]; ok {
+			return &v, false
+		}
+		return nil, false
+	}
+
+	names := make(map[string]struct{}, len(current)+len(currentSensitive))
+	for _, keys := range []map[string]string{previous, previousSensitive, current, currentSensitive} {
+		for name := range keys {
+			names[name] = struct{}{}
+		}
+	}
+
+	var changes []keyChange
+	for name := range names {
+		before, beforeSensitive := lookup(previous, previousSensitive, name)
+		after, afterSensitive := lookup(current, currentSensitive, name)
+		change := keyChange{name: name, previous: before, current: after, sensitive: beforeSensitive || afterSensitive}
+		switch {
+		case before == nil:
+			change.action = "added"
+		case after == nil:
+			change.action = "removed"
+		case *before != *after || beforeSensitive != afterSensitive:
+			change.action = "changed"
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].name < changes[j].name
+	})
+	return changes
+}
+
+func formatKeyChanges(changes []keyChange, showValues bool) string {
+	formatted := make([]string, 0, len(changes))
+	for _, change := range changes {
+		formatted = append(formatted, change.String(showValues))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func keyChangeNames(changes []keyChange) []string {
+	names := make([]string, 0, len(changes))
+	for _, change := range changes {
+		names = append(names, change.name)
+	}
+	return names
+}
+
+// getOwnedData returns data of a ConfigMap and a Secret in a namespace created by named reconcilers,
+// Secret values are base64 encoded the same way as sensitive keys, missing or foreign objects have no data.
+func getOwnedData(
+	ctx context.Context, namespace, configMapName, configMapReconciler, secretName, secretReconciler string) (
+	data, sensitiveData map[string]string, err error) {
+
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	data = make(map[string]string)
+	if len(configMapName) > 0 {
+		configMap := new(corev1.ConfigMap)
+		err = config.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configMapName}, configMap)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		if configMap.Annotations["reconciler"] == configMapReconciler {
+			for key, v := range configMap.Data {
+				data[key] = v
+			}
+		}
+	}
+
+	sensitiveData = make(map[string]string)
+	if len(secretName) > 0 {
+		secret := new(corev1.Secret)
+		err = config.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, secret)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		if secret.Annotations["reconciler"] == secretReconciler {
+			for key, v := range secret.Data {
+				sensitiveData[key] = base64.StdEncoding.EncodeToString(v)
+			}
+		}
+	}
+	return data, sensitiveData, nil
+}
+
+// stashConsumedChanges stashes names of consumed envs changed in existing consumed ConfigMap and Secret,
+// so workloads using them are rolled out.
+func stashConsumedChanges(ctx context.Context, envs []string) {
+	if len(envs) == 0 {
+		reconcilers.ClearValue(ctx, consumedChangesStashKey)
+		return
+	}
+	reconcilers.StashValue(ctx, consumedChangesStashKey, envs)
+}
+
+func consumedChangesFromContext(ctx context.Context) []string {
+	if envs, ok := reconcilers.RetrieveValue(ctx, consumedChangesStashKey).([]string); ok {
+		return envs
+	}
+	return nil
+}
+
+// RecordRollout records an event and metrics of a workload rolled out because of changed consumed envs,
+// it is expected to be called once the workload pod template is updated.
+func RecordRollout(ctx context.Context, obj client.Object) {
+	if envs := consumedChangesFromContext(ctx); len(envs) > 0 {
+		recordEvent(ctx, obj, corev1.EventTypeNormal, RolloutTriggeredReason,
+			"Workload rollout is triggered by changed envs: %s.", strings.Join(envs, ", "))
+		observeRollout(ctx, obj)
+	}
+}
+
+// recordEvent records an event on a reconciled resource, duck typed resources are not registered
+// in a scheme, so they get a kind of the original resource type to be referenced by the event.
+func recordEvent(ctx context.Context, obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	if config.Recorder == nil {
+		return
+	}
+	if resource, ok := obj.(*v1alpha1.Tensegrity); ok && resource.GetObjectKind().GroupVersionKind().Empty() {
+		if gvk, err := config.GroupVersionKindFor(reconcilers.RetrieveOriginalResourceType(ctx)); err == nil {
+			resource = resource.DeepCopy()
+			resource.SetGroupVersionKind(gvk)
+			obj = resource
+		}
+	}
+	message := fmt.Sprintf(messageFmt, args...)
+	if len(message) > eventMessageLimit {
+		message = message[:eventMessageLimit-3] + "..."
+	}
+	config.Recorder.Event(obj, eventType, reason, message)
+}
+
+// formatDelegate formats a delegate reference for event messages, e.g. Namespace staging.
+func formatDelegate(delegate *corev1.ObjectReference) string {
+	if delegate == nil {
+		return "default"
+	}
+	if len(delegate.Namespace) > 0 {
+		return fmt.Sprintf("%s %s (namespace %s)", delegate.Kind, delegate.Name, delegate.Namespace)
+	}
+	return delegate.Kind + " " + delegate.Name
+}
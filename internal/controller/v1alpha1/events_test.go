@@ -0,0 +1,51 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Key Changes", func() {
+	It("should list added, changed and removed keys in order", func() {
+		changes := diffKeys(
+			map[string]string{"HOST": "db.staging", "PORT": "5432", "USER": "app"},
+			map[string]string{"PASSWORD": "c2VjcmV0"},
+			map[string]string{"HOST": "db.alice", "PORT": "5432", "NAME": "app"},
+			map[string]string{"PASSWORD": "c2VjcmV0Mg==", "USER": "YXBw"})
+		Expect(keyChangeNames(changes)).To(Equal([]string{"HOST", "NAME", "PASSWORD", "USER"}))
+		Expect(formatKeyChanges(changes, false)).To(Equal(
+			"HOST changed, NAME added, PASSWORD changed, USER changed"))
+	})
+
+	It("should show values which are not sensitive only when allowed", func() {
+		changes := diffKeys(
+			map[string]string{"HOST": "db.staging", "PORT": "5432"},
+			map[string]string{"PASSWORD": "c2VjcmV0"},
+			map[string]string{"HOST": "db.alice"},
+			map[string]string{"TOKEN": "dG9rZW4="})
+		Expect(formatKeyChanges(changes, true)).To(Equal(
+			`HOST changed ("db.staging" -> "db.alice"), PASSWORD removed, PORT removed ("5432"), TOKEN added`))
+	})
+
+	It("should have no changes for the same keys", func() {
+		keys := map[string]string{"HOST": "db.staging"}
+		Expect(diffKeys(keys, nil, keys, nil)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,314 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// Reasons of failed resolutions of consumed envs and produced keys reported by metrics.
+const (
+	NotFoundFailure   = "NotFound"
+	JSONPathFailure   = "JSONPathError"
+	ExpressionFailure = "ExpressionError"
+	EmptyValueFailure = "EmptyValue"
+	NotReadyFailure   = "NotReady"
+	SelectorFailure   = "SelectorError"
+	SealedFailure     = "SealedError"
+	NotAllowedFailure = "NotAllowed"
+	UnknownFailure    = "Unknown"
+)
+
+const (
+	consumedDirection = "consumed"
+	producedDirection = "produced"
+	// redactedKey replaces names of sensitive keys in metric labels.
+	redactedKey = "<redacted>"
+	// keysCollectorTimeout limits listing of resources on a scrape.
+	keysCollectorTimeout = 10 * time.Second
+)
+
+const producedChangedStashKey reconcilers.StashKey = "tensegrity.fastforge.io/producedChanged"
+
+var metricslog = ctrl.Log.WithName("metrics")
+
+var (
+	resolutionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tensegrity_resolution_failures_total",
+		Help: "Number of consumed envs and produced keys which started to fail, by reason.",
+	}, []string{"namespace", "kind", "name", "direction", "key", "reason"})
+	delegateHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tensegrity_delegate_hits_total",
+		Help: "Number of envs which started to be consumed from a delegate namespace, cluster is set for Cluster delegates.",
+	}, []string{"namespace", "cluster"})
+	rolloutsTriggered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tensegrity_rollouts_triggered_total",
+		Help: "Number of workload rollouts triggered by changed consumed envs.",
+	}, []string{"namespace", "kind", "name"})
+	rolloutLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tensegrity_rollout_latency_seconds",
+		Help:    "Time from the latest change of consumed produced ConfigMaps and Secrets to a workload rollout.",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 14),
+	}, []string{"namespace", "kind"})
+
+	consumedKeysDesc = prometheus.NewDesc("tensegrity_consumed_keys",
+		"Number of consumed envs of a resource by status.", []string{"namespace", "kind", "name", "status"}, nil)
+	producedKeysDesc = prometheus.NewDesc("tensegrity_produced_keys",
+		"Number of produced keys of a resource by status.", []string{"namespace", "kind", "name", "status"}, nil)
+)
+
+func init() {
+	metrics.Registry.MustRegister(resolutionFailures, delegateHits, rolloutsTriggered, rolloutLatency)
+}
+
+// resolutionError is an error of a key resolution with a reason of a failure reported by metrics,
+// it keeps a message of the original error, so statuses are not affected.
+type resolutionError struct {
+	reason string
+	err    error
+}
+
+func (e *resolutionError) Error() string { return e.err.Error() }
+
+func (e *resolutionError) Unwrap() error { return e.err }
+
+func withFailureReason(reason string, err error) error {
+	return &resolutionError{reason: reason, err: err}
+}
+
+func failureReason(err error) string {
+	var resolution *resolutionError
+	switch {
+	case errors.As(err, &resolution):
+		return resolution.reason
+	case k8serrors.IsNotFound(err):
+		return NotFoundFailure
+	default:
+		return UnknownFailure
+	}
+}
+
+// observeFailure counts a key which started to fail, names of sensitive keys are redacted
+// unless showSensitiveKeys is set. Keys which keep failing are not counted again on further reconciles.
+func observeFailure(
+	ctx context.Context, resource *v1alpha1.Tensegrity, direction, key string, sensitive, showSensitiveKeys bool,
+	reason string) {
+
+	if sensitive && !showSensitiveKeys {
+		key = redactedKey
+	}
+	resolutionFailures.WithLabelValues(
+		resource.Namespace, resourceKind(ctx, resource), resource.Name, direction, key, reason).Inc()
+}
+
+// observeDelegateHit counts an env which started to be consumed from a delegate, NamespaceSelector delegates
+// count selected namespaces.
+func observeDelegateHit(delegate corev1.ObjectReference) {
+	switch delegate.Kind {
+	case "Cluster":
+		delegateHits.WithLabelValues(delegate.Namespace, delegate.Name).Inc()
+	default:
+		delegateHits.WithLabelValues(delegate.Name, "").Inc()
+	}
+}
+
+// setChangedAnnotation marks a produced ConfigMap or Secret as changed now.
+func setChangedAnnotation(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[v1alpha1.ChangedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// observeProducedChange stashes the latest change time of produced ConfigMaps and Secrets keys are consumed from.
+func observeProducedChange(ctx context.Context, obj metav1.Object) {
+	changed := obj.GetCreationTimestamp().Time
+	if value, ok := obj.GetAnnotations()[v1alpha1.ChangedAnnotation]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			changed = t
+		}
+	}
+	if latest, ok := reconcilers.RetrieveValue(ctx, producedChangedStashKey).(time.Time); ok && latest.After(changed) {
+		return
+	}
+	reconcilers.StashValue(ctx, producedChangedStashKey, changed)
+}
+
+// observeRollout counts a triggered rollout of a workload and observes its latency.
+func observeRollout(ctx context.Context, obj client.Object) {
+	kind := resourceKind(ctx, obj)
+	rolloutsTriggered.WithLabelValues(obj.GetNamespace(), kind, obj.GetName()).Inc()
+	if changed, ok := reconcilers.RetrieveValue(ctx, producedChangedStashKey).(time.Time); ok && !changed.IsZero() {
+		rolloutLatency.WithLabelValues(obj.GetNamespace(), kind).Observe(time.Since(changed).Seconds())
+	}
+}
+
+// resourceKind returns a kind of a reconciled resource, duck typed resources get it from the original resource type.
+func resourceKind(ctx context.Context, obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; len(kind) > 0 {
+		return kind
+	}
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	if _, ok := obj.(*v1alpha1.Tensegrity); ok {
+		obj = reconcilers.RetrieveOriginalResourceType(ctx)
+	}
+	if gvk, err := config.GroupVersionKindFor(obj); err == nil {
+		return gvk.Kind
+	}
+	return ""
+}
+
+// forgetResource deletes metrics labeled with a deleted resource, so they don't pile up.
+func forgetResource(namespace, kind, name string) {
+	resolutionFailures.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "kind": kind, "name": name})
+	rolloutsTriggered.DeleteLabelValues(namespace, kind, name)
+}
+
+// KeysCollector reports consumed and produced keys of resources by status, resources are listed
+// from a cache on each scrape, so deleted resources are not reported. Once it is started, other metrics
+// labeled with resources are deleted when the resources are deleted.
+type KeysCollector struct {
+	client client.Client
+	cache  cache.Cache
+	lists  []client.ObjectList
+	// Forgotten are types of other resources labeled in metrics, such as annotated native workloads,
+	// metrics of them are deleted once they are deleted, the same way as for listed resources.
+	Forgotten []client.Object
+}
+
+func NewKeysCollector(client client.Client, cache cache.Cache, lists ...client.ObjectList) *KeysCollector {
+	return &KeysCollector{client: client, cache: cache, lists: lists}
+}
+
+// Start deletes metrics of resources once they are deleted, it is expected to be added to a manager.
+func (c *KeysCollector) Start(ctx context.Context) error {
+	objs := append([]client.Object(nil), c.Forgotten...)
+	for _, list := range c.lists {
+		gvk, err := c.client.GroupVersionKindFor(list)
+		if err != nil {
+			return err
+		}
+		gvk.Kind = gvk.Kind[:len(gvk.Kind)-len("List")]
+		obj, err := c.client.Scheme().New(gvk)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, obj.(client.Object))
+	}
+	for _, obj := range objs {
+		gvk, err := c.client.GroupVersionKindFor(obj)
+		if err != nil {
+			return err
+		}
+		informer, err := c.cache.GetInformer(ctx, obj)
+		if err != nil {
+			return err
+		}
+		kind := gvk.Kind
+		if _, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(o interface{}) {
+				if tombstone, ok := o.(toolscache.DeletedFinalStateUnknown); ok {
+					o = tombstone.Obj
+				}
+				if obj, ok := o.(client.Object); ok {
+					forgetResource(obj.GetNamespace(), kind, obj.GetName())
+				}
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (c *KeysCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- consumedKeysDesc
+	ch <- producedKeysDesc
+}
+
+func (c *KeysCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), keysCollectorTimeout)
+	defer cancel()
+
+	for _, list := range c.lists {
+		list = list.DeepCopyObject().(client.ObjectList)
+		gvk, err := c.client.GroupVersionKindFor(list)
+		if err != nil {
+			metricslog.Error(err, "unable to get kind of list")
+			continue
+		}
+		kind := gvk.Kind[:len(gvk.Kind)-len("List")]
+		if err = c.client.List(ctx, list); err != nil {
+			metricslog.V(1).Info("unable to list resources", "kind", kind, "error", err.Error())
+			continue
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			metricslog.Error(err, "unable to extract list", "kind", kind)
+			continue
+		}
+		for _, item := range items {
+			resource, err := toTensegrity(item)
+			if err != nil {
+				metricslog.Error(err, "unable to convert resource", "kind", kind)
+				continue
+			}
+			c.collect(ch, kind, resource)
+		}
+	}
+}
+
+func (c *KeysCollector) collect(ch chan<- prometheus.Metric, kind string, resource *v1alpha1.Tensegrity) {
+	consumed := make(map[v1alpha1.ConsumedStatus]int)
+	for _, key := range resource.Status.ConsumedKeys {
+		consumed[key.Status]++
+	}
+	for status, count := range consumed {
+		ch <- prometheus.MustNewConstMetric(consumedKeysDesc, prometheus.GaugeValue, float64(count),
+			resource.Namespace, kind, resource.Name, string(status))
+	}
+
+	produced := make(map[v1alpha1.ProducedStatus]int)
+	for _, key := range resource.Status.ProducedKeys {
+		produced[key.Status]++
+	}
+	for status, count := range produced {
+		ch <- prometheus.MustNewConstMetric(producedKeysDesc, prometheus.GaugeValue, float64(count),
+			resource.Namespace, kind, resource.Name, string(status))
+	}
+}
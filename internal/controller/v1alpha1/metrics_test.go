@@ -0,0 +1,137 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"reconciler.io/runtime/reconcilers"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("Failure Reasons", func() {
+	It("should keep a message of an error with a reason", func() {
+		err := withFailureReason(EmptyValueFailure, errors.Wrap(errors.New("value is empty"), "fieldPath"))
+		Expect(err.Error()).To(Equal("fieldPath: value is empty"))
+		Expect(failureReason(err)).To(Equal(EmptyValueFailure))
+		Expect(failureReason(errors.Wrap(err, "fallback"))).To(Equal(EmptyValueFailure))
+	})
+
+	It("should classify not found and unknown errors", func() {
+		notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "services"}, "postgres")
+		Expect(failureReason(notFound)).To(Equal(NotFoundFailure))
+		Expect(failureReason(errors.New("connection refused"))).To(Equal(UnknownFailure))
+	})
+})
+
+var _ = Describe("Resource Metrics", func() {
+	ctx := context.Background()
+	newResource := func(name string) *v1alpha1.Tensegrity {
+		return &v1alpha1.Tensegrity{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metrics"},
+		}
+	}
+	failures := func(name, env string) float64 {
+		return testutil.ToFloat64(resolutionFailures.WithLabelValues(
+			"metrics", "Deployment", name, consumedDirection, env, NotFoundFailure))
+	}
+
+	It("should count envs only when they start to fail or switch delegates", func() {
+		r := NewConsumerReconciler()
+		r.MetricsSensitiveKeys = true
+		resource := newResource("api")
+		staging := &corev1.ObjectReference{Kind: "Namespace", Name: "metrics-staging"}
+		user := &corev1.ObjectReference{Kind: "Namespace", Name: "metrics-user"}
+		stagingHits := func() float64 { return testutil.ToFloat64(delegateHits.WithLabelValues("metrics-staging", "")) }
+		userHits := func() float64 { return testutil.ToFloat64(delegateHits.WithLabelValues("metrics-user", "")) }
+
+		resource.Status.ConsumedKeys = []v1alpha1.ConsumedKeyStatus{
+			{Env: "POSTGRES_HOST", Status: v1alpha1.ConsumedFailure},
+			{Env: "REDIS_HOST", Status: v1alpha1.ConsumedSuccess, Delegate: staging},
+		}
+		r.observeKeys(ctx, resource, nil)
+		Expect(failures("api", "POSTGRES_HOST")).To(BeEquivalentTo(1))
+		Expect(stagingHits()).To(BeEquivalentTo(1))
+
+		previous := resource.Status.ConsumedKeys
+		r.observeKeys(ctx, resource, previous)
+		Expect(failures("api", "POSTGRES_HOST")).To(BeEquivalentTo(1))
+		Expect(stagingHits()).To(BeEquivalentTo(1))
+
+		resource.Status.ConsumedKeys = []v1alpha1.ConsumedKeyStatus{
+			{Env: "POSTGRES_HOST", Status: v1alpha1.ConsumedSuccess},
+			{Env: "REDIS_HOST", Status: v1alpha1.ConsumedSuccess, Delegate: user},
+		}
+		r.observeKeys(ctx, resource, previous)
+		Expect(userHits()).To(BeEquivalentTo(1))
+
+		previous = resource.Status.ConsumedKeys
+		resource.Status.ConsumedKeys = []v1alpha1.ConsumedKeyStatus{
+			{Env: "POSTGRES_HOST", Status: v1alpha1.ConsumedFailure},
+		}
+		r.observeKeys(ctx, resource, previous)
+		Expect(failures("api", "POSTGRES_HOST")).To(BeEquivalentTo(2))
+	})
+
+	It("should not count envs of annotated native workloads", func() {
+		ctx := reconcilers.WithStash(context.Background())
+		r := NewConsumerReconciler()
+		r.MetricsSensitiveKeys = true
+		resource := newResource("annotated")
+		delegate := &corev1.ObjectReference{Kind: "Namespace", Name: "metrics-annotated"}
+		hits := func() float64 { return testutil.ToFloat64(delegateHits.WithLabelValues("metrics-annotated", "")) }
+		Expect(NewAnnotationReconciler().Sync(ctx, resource)).To(Succeed())
+
+		for range 2 {
+			resource.Status.ConsumedKeys = []v1alpha1.ConsumedKeyStatus{
+				{Env: "POSTGRES_HOST", Status: v1alpha1.ConsumedFailure},
+				{Env: "REDIS_HOST", Status: v1alpha1.ConsumedSuccess, Delegate: delegate},
+			}
+			r.observeKeys(ctx, resource, nil)
+		}
+		Expect(failures("annotated", "POSTGRES_HOST")).To(BeZero())
+		Expect(hits()).To(BeZero())
+	})
+
+	It("should delete metrics of a deleted resource", func() {
+		resolutionFailures.WithLabelValues(
+			"metrics", "Deployment", "deleted", producedDirection, "host", EmptyValueFailure).Inc()
+		resolutionFailures.WithLabelValues(
+			"metrics", "Deployment", "deleted", consumedDirection, "HOST", NotFoundFailure).Inc()
+		resolutionFailures.WithLabelValues(
+			"metrics", "Deployment", "kept", consumedDirection, "HOST", NotFoundFailure).Inc()
+		rolloutsTriggered.WithLabelValues("metrics", "Deployment", "deleted").Inc()
+		failuresBefore := testutil.CollectAndCount(resolutionFailures)
+		rolloutsBefore := testutil.CollectAndCount(rolloutsTriggered)
+
+		forgetResource("metrics", "Deployment", "deleted")
+		Expect(testutil.CollectAndCount(resolutionFailures)).To(Equal(failuresBefore - 2))
+		Expect(testutil.CollectAndCount(rolloutsTriggered)).To(Equal(rolloutsBefore - 1))
+		Expect(failures("kept", "HOST")).To(BeEquivalentTo(1))
+	})
+})
@@ -21,15 +21,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/util/jsonpath"
 	"k8s.io/utils/ptr"
 	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
@@ -37,35 +46,118 @@ import (
 
 func NewProducerReconciler() *ProducerReconciler {
 	r := new(ProducerReconciler)
+	r.sources = NewSourceWatcher()
 	r.workloadReconciler = workloadReconciler{
-		Name: "ProducerReconciler",
-		Sync: r.Sync,
+		Name:  "ProducerReconciler",
+		Sync:  r.Sync,
+		Setup: r.Setup,
 	}
 	return r
 }
 
 type ProducerReconciler struct {
 	workloadReconciler
+	sources *SourceWatcher
+	// consumersOnce indexes consumers once, since the reconciler is shared by controllers of all kinds.
+	consumersOnce sync.Once
+	consumersErr  error
+	// ShowEventValues allows events to show values of changed keys which are not sensitive.
+	ShowEventValues bool
+	// MetricsSensitiveKeys allows metrics to be labeled with names of sensitive keys.
+	MetricsSensitiveKeys bool
+	// SourceKinds are kinds of source objects which are watched for selectors and readyWhen,
+	// DefaultSourceKinds when empty, sources of other kinds fail.
+	SourceKinds []schema.GroupKind
+}
+
+func (r *ProducerReconciler) Setup(ctx context.Context, mgr ctrl.Manager, builder *builder.Builder) error {
+	r.sources.Kinds = r.SourceKinds
+	source, err := r.sources.Setup(ctx, mgr)
+	if err != nil {
+		return err
+	}
+	builder.WatchesRawSource(source)
+	return r.setupConsumers(ctx, mgr, builder)
 }
 
 func (r *ProducerReconciler) Sync(ctx context.Context, resource *v1alpha1.Tensegrity) error {
-	if len(resource.Spec.Produces) == 0 {
+	data, _ := reconcilers.RetrieveValue(ctx, producerDataStashKey).(producedData)
+	if len(resource.Spec.Produces) == 0 && len(data.Data) == 0 && len(data.SensitiveData) == 0 &&
+		len(data.SealedData) == 0 && len(data.SealedErrors) == 0 {
+		if len(resource.Status.ProducedConfigMapName) > 0 || len(resource.Status.ProducedSecretName) > 0 {
+			if err := r.recordChanges(ctx, resource, nil, nil); err != nil {
+				return err
+			}
+		}
 		resource.Status.ClearProduces()
+		r.sources.Release(resource.UID, time.Now())
 		return nil
 	}
 
 	var seenError bool
-	keys := make(map[string]string, len(resource.Spec.Produces))
-	sensitiveKeys := make(map[string]string, len(resource.Spec.Produces))
+	keys := make(map[string]string, len(resource.Spec.Produces)+len(data.Data))
+	sensitiveKeys := make(map[string]string,
+		len(resource.Spec.Produces)+len(data.SensitiveData)+len(data.SealedData))
 
-	resource.Status.ProducedKeys = make([]v1alpha1.ProducedKeyStatus, 0, len(resource.Spec.Produces))
+	// keys which keep failing are not counted by metrics again
+	previouslyFailed := make(map[string]bool, len(resource.Status.ProducedKeys))
+	for _, produced := range resource.Status.ProducedKeys {
+		previouslyFailed[produced.Key] = produced.Status == v1alpha1.ProducedFailure
+	}
+	resource.Status.ProducedKeys = make([]v1alpha1.ProducedKeyStatus, 0,
+		len(resource.Spec.Produces)+len(data.Data)+len(data.SensitiveData)+len(data.SealedData)+len(data.SealedErrors))
+	for key, value := range data.Data {
+		keys[key] = value
+		resource.Status.ProducedKeys = append(resource.Status.ProducedKeys,
+			r.getDataKeyStatus(data.Reference, "spec.data", key, value, false))
+	}
+	for key, value := range data.SensitiveData {
+		sensitiveKeys[key] = base64.StdEncoding.EncodeToString([]byte(value))
+		resource.Status.ProducedKeys = append(resource.Status.ProducedKeys,
+			r.getDataKeyStatus(data.Reference, "spec.sensitiveData", key, value, true))
+	}
+	for key, value := range data.SealedData {
+		sensitiveKeys[key] = base64.StdEncoding.EncodeToString([]byte(value))
+		resource.Status.ProducedKeys = append(resource.Status.ProducedKeys,
+			r.getDataKeyStatus(data.Reference, "spec.sealedData", key, value, true))
+	}
+	for key, err := range data.SealedErrors {
+		status := r.getDataKeyStatus(data.Reference, "spec.sealedData", key, "", true)
+		status.Status = v1alpha1.ProducedFailure
+		status.Reason = ptr.To(err.Error())
+		resource.Status.ProducedKeys = append(resource.Status.ProducedKeys, status)
+		seenError = true
+		if !previouslyFailed[key] {
+			observeFailure(ctx, resource, producedDirection, key, true, r.MetricsSensitiveKeys, failureReason(err))
+		}
+	}
+	resolved := time.Now()
 	for _, produces := range resource.Spec.Produces {
 		var err error
 		var value string
 		var object *unstructured.Unstructured
-		object, err = r.getObject(ctx, resource.Namespace, produces)
+		var source v1alpha1.ProducesSourceSpec
+		var failedSources []v1alpha1.ProducedSourceStatus
+		sources := append([]v1alpha1.ProducesSourceSpec{produces.ProducesSourceSpec}, produces.Fallbacks...)
+		// keys produced from literals of a Static itself are produced as written, without looking it up
+		literal, isLiteral := r.literalValue(data.Reference, produces)
+		if isLiteral {
+			source, value = produces.ProducesSourceSpec, literal
+			sources = nil
+		}
+		for i := range sources {
+			source = sources[i]
+			object, value, err = r.resolveValue(ctx, resource, produces.Key, source)
+			if err == nil || i == len(sources)-1 {
+				break
+			}
+			failedSources = append(failedSources, v1alpha1.ProducedSourceStatus{
+				ObjectReference: r.getSourceReference(resource.Namespace, source),
+				Selector:        r.getSourceSelector(source),
+				Reason:          err.Error(),
+			})
+		}
 		if err == nil {
-			value, err = r.parseValue(object, produces)
 			switch {
 			case produces.Sensitive && produces.Encoded:
 				sensitiveKeys[produces.Key] = value
@@ -75,14 +167,33 @@ func (r *ProducerReconciler) Sync(ctx context.Context, resource *v1alpha1.Tenseg
 				keys[produces.Key] = value
 			}
 		}
-		resource.Status.ProducedKeys = append(resource.Status.ProducedKeys,
-			r.getKeyStatus(object, produces, value, err))
+		status := r.getKeyStatus(object, produces, source, value, err)
+		if isLiteral {
+			status.ObjectReference = data.Reference
+			status.FieldPath = source.FieldPath
+		}
+		status.FailedSources = failedSources
+		if err == nil && len(failedSources) > 0 {
+			status.Fallback = ptr.To(int32(len(failedSources) - 1))
+		}
+		resource.Status.ProducedKeys = append(resource.Status.ProducedKeys, status)
 		if err != nil {
 			seenError = true
+			if !previouslyFailed[produces.Key] {
+				observeFailure(ctx, resource, producedDirection, produces.Key, produces.Sensitive,
+					r.MetricsSensitiveKeys, failureReason(err))
+			}
 		}
 	}
+	// kinds of sources which are not looked up anymore are released
+	r.sources.Release(resource.UID, resolved)
 	resource.Status.SortProduces()
 	r.updateStatus(resource)
+	if !seenError {
+		if err := r.recordChanges(ctx, resource, keys, sensitiveKeys); err != nil {
+			return err
+		}
+	}
 
 	if !seenError && len(keys) > 0 {
 		reconcilers.StashValue(ctx, producerConfigMapKeysStashKey, keys)
@@ -103,15 +214,104 @@ func (r *ProducerReconciler) Sync(ctx context.Context, resource *v1alpha1.Tenseg
 		reconcilers.ClearValue(ctx, producerSecretNameStashKey)
 		resource.Status.ProducedSecretName = ""
 	}
+	if err := r.updateConsumers(ctx, resource); err != nil {
+		return err
+	}
 	if seenError {
+		if err := data.keysNotLoadedError(); err != nil {
+			return err
+		}
 		return reconcilers.ErrHaltSubReconcilers
 	}
 	return nil
 }
 
+// recordChanges records an event when produced keys are added, changed or removed.
+func (r *ProducerReconciler) recordChanges(
+	ctx context.Context, resource *v1alpha1.Tensegrity, keys, sensitiveKeys map[string]string) error {
+
+	data, sensitiveData, err := getOwnedData(ctx, resource.Namespace,
+		resource.Spec.ProducesConfigMapName, producerConfigMapReconcilerName,
+		resource.Spec.ProducesSecretName, producerSecretReconcilerName)
+	if err != nil {
+		return err
+	}
+	if changes := diffKeys(data, sensitiveData, keys, sensitiveKeys); len(changes) > 0 {
+		recordEvent(ctx, resource, corev1.EventTypeNormal, ProducedKeysChangedReason,
+			"Produced keys changed: %s.", formatKeyChanges(changes, r.ShowEventValues))
+	}
+	return nil
+}
+
+// resolveValue resolves a key value from a source, a named object or objects selected by labels.
+func (r *ProducerReconciler) resolveValue(ctx context.Context, resource *v1alpha1.Tensegrity, key string,
+	source v1alpha1.ProducesSourceSpec) (*unstructured.Unstructured, string, error) {
+
+	if source.Selector != nil {
+		object, objects, err := r.listObjects(ctx, resource, source)
+		if err != nil {
+			return object, "", err
+		}
+		value, err := r.aggregateValue(objects, key, source)
+		return object, value, err
+	}
+	object, err := r.getObject(ctx, resource.Namespace, source)
+	if err != nil {
+		return nil, "", err
+	}
+	if source.ReadyWhen != nil {
+		if err = r.sources.Watch(ctx, resource.UID, object.GroupVersionKind()); err != nil {
+			return object, "", errors.Wrap(err, "unable to watch source object")
+		}
+		if err = r.checkReady(object, source.ReadyWhen); err != nil {
+			return object, "", err
+		}
+	}
+	value, err := r.parseValue(object, key, source)
+	return object, value, err
+}
+
+// checkReady returns an error telling why an object is not ready.
+func (r *ProducerReconciler) checkReady(obj *unstructured.Unstructured, ready *v1alpha1.ReadySpec) error {
+	if ready == nil {
+		return nil
+	}
+	if len(ready.Expression) > 0 {
+		ok, err := v1alpha1.EvalReadyExpression(ready.Expression, obj.Object)
+		if err != nil {
+			return withFailureReason(ExpressionFailure, errors.Wrap(err, "readyWhen"))
+		}
+		if !ok {
+			return withFailureReason(NotReadyFailure, errors.New("source is not ready"))
+		}
+		return nil
+	}
+
+	status := ready.Status
+	if len(status) == 0 {
+		status = metav1.ConditionTrue
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != ready.ConditionType {
+			continue
+		}
+		if condition["status"] == string(status) {
+			return nil
+		}
+		return withFailureReason(NotReadyFailure, errors.Errorf(
+			"source is not ready, condition %s is %v", ready.ConditionType, condition["status"]))
+	}
+	return withFailureReason(NotReadyFailure, errors.Errorf(
+		"source is not ready, condition %s is not found", ready.ConditionType))
+}
+
 func (r *ProducerReconciler) getObject(
-	ctx context.Context, namespace string, produces v1alpha1.ProducesSpec) (*unstructured.Unstructured, error) {
+	ctx context.Context, namespace string, produces v1alpha1.ProducesSourceSpec) (*unstructured.Unstructured, error) {
 
+	// sources without a kind are rejected by validation, an empty object is kept for ones created before it,
+	// which resolve fieldPaths without JSONPath expressions as literals, literals of Statics belong in data
 	if len(produces.Kind) == 0 && len(produces.APIVersion) == 0 {
 		return new(unstructured.Unstructured), nil
 	}
@@ -131,23 +331,114 @@ func (r *ProducerReconciler) getObject(
 	return obj, nil
 }
 
+// listObjects lists objects selected by labels ordered by name, and returns a reference object of their kind.
+func (r *ProducerReconciler) listObjects(ctx context.Context, resource *v1alpha1.Tensegrity,
+	produces v1alpha1.ProducesSourceSpec) (*unstructured.Unstructured, *unstructured.UnstructuredList, error) {
+
+	config := reconcilers.RetrieveConfigOrDie(ctx)
+	obj := new(unstructured.Unstructured)
+	obj.SetKind(produces.Kind)
+	obj.SetNamespace(resource.Namespace)
+	obj.SetAPIVersion(produces.APIVersion)
+
+	selector, err := metav1.LabelSelectorAsSelector(produces.Selector)
+	if err != nil {
+		return obj, nil, withFailureReason(SelectorFailure, errors.Wrap(err, "selector"))
+	}
+	if err = r.sources.Watch(ctx, resource.UID, obj.GroupVersionKind()); err != nil {
+		return obj, nil, errors.Wrap(err, "unable to watch selected objects")
+	}
+
+	list := new(unstructured.UnstructuredList)
+	list.SetAPIVersion(produces.APIVersion)
+	list.SetKind(produces.Kind + "List")
+	err = config.TrackAndList(ctx, list,
+		client.InNamespace(resource.Namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return obj, nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	return obj, list, nil
+}
+
+// aggregateValue combines values of selected objects, objects being deleted, objects which are not ready
+// and objects a value is not resolved from yet, e.g. pods without an IP, are left out.
+func (r *ProducerReconciler) aggregateValue(
+	objects *unstructured.UnstructuredList, key string, produces v1alpha1.ProducesSourceSpec) (string, error) {
+
+	items := make([]unstructured.Unstructured, 0, len(objects.Items))
+	for i := range objects.Items {
+		item := &objects.Items[i]
+		if item.GetDeletionTimestamp() == nil && r.checkReady(item, produces.ReadyWhen) == nil {
+			items = append(items, *item)
+		}
+	}
+	if produces.Aggregation == v1alpha1.CountAggregation {
+		return strconv.Itoa(len(items)), nil
+	}
+
+	values := make([]string, 0, len(items))
+	for i := range items {
+		if value, err := r.parseValue(&items[i], key, produces); err == nil {
+			values = append(values, value)
+		}
+	}
+
+	switch produces.Aggregation {
+	case v1alpha1.FirstAggregation:
+		if len(values) == 0 {
+			return "", withFailureReason(EmptyValueFailure, errors.Wrap(errors.New("no selected objects"), "selector"))
+		}
+		return values[0], nil
+	case v1alpha1.JSONAggregation:
+		value, err := json.Marshal(values)
+		if err != nil {
+			return "", errors.Wrap(err, "selector")
+		}
+		return string(value), nil
+	default:
+		separator := v1alpha1.DefaultAggregationSeparator
+		if produces.Separator != nil {
+			separator = *produces.Separator
+		}
+		value := strings.Join(values, separator)
+		if len(value) == 0 {
+			return "", withFailureReason(EmptyValueFailure, errors.Wrap(errors.New("value is empty"), "selector"))
+		}
+		return value, nil
+	}
+}
+
 func (r *ProducerReconciler) parseValue(
-	obj *unstructured.Unstructured, produces v1alpha1.ProducesSpec) (string, error) {
+	obj *unstructured.Unstructured, key string, produces v1alpha1.ProducesSourceSpec) (string, error) {
+
+	if len(produces.Expression) > 0 {
+		value, err := v1alpha1.EvalExpression(produces.Expression, obj.Object)
+		if err != nil {
+			return "", withFailureReason(ExpressionFailure, errors.Wrap(err, "expression"))
+		}
+		if len(value) == 0 {
+			return "", withFailureReason(EmptyValueFailure, errors.Wrap(errors.New("value is empty"), "expression"))
+		}
+		return value, nil
+	}
 
-	jp := jsonpath.New(produces.Key)
+	jp := jsonpath.New(key)
 	jp.AllowMissingKeys(false)
 	if err := jp.Parse(produces.FieldPath); err != nil {
-		return "", errors.Wrap(err, "fieldPath")
+		return "", withFailureReason(JSONPathFailure, errors.Wrap(err, "fieldPath"))
 	}
 
 	buf := new(bytes.Buffer)
 	if err := jp.Execute(buf, obj.Object); err != nil {
-		return "", errors.Wrap(err, "fieldPath")
+		return "", withFailureReason(JSONPathFailure, errors.Wrap(err, "fieldPath"))
 	}
 
 	value := buf.String()
 	if len(value) == 0 {
-		return "", errors.Wrap(errors.New("value is empty"), "fieldPath")
+		return "", withFailureReason(EmptyValueFailure, errors.Wrap(errors.New("value is empty"), "fieldPath"))
 	}
 
 	return buf.String(), nil
@@ -156,6 +447,7 @@ func (r *ProducerReconciler) parseValue(
 func (r *ProducerReconciler) getKeyStatus(
 	obj *unstructured.Unstructured,
 	produces v1alpha1.ProducesSpec,
+	source v1alpha1.ProducesSourceSpec,
 	value string,
 	err error) v1alpha1.ProducedKeyStatus {
 
@@ -171,9 +463,10 @@ func (r *ProducerReconciler) getKeyStatus(
 			Namespace:  obj.GetNamespace(),
 			Name:       obj.GetName(),
 			APIVersion: obj.GetAPIVersion(),
-			FieldPath:  produces.FieldPath,
+			FieldPath:  source.FieldPath,
 		}
 	}
+	status.Selector = r.getSourceSelector(source)
 
 	if len(value) > 0 && !produces.Sensitive {
 		status.Value = ptr.To(value)
@@ -187,6 +480,52 @@ func (r *ProducerReconciler) getKeyStatus(
 	return status
 }
 
+// literalValue returns a value of a key produced from a literal of the resource itself, a Static
+// referenced by data, empty values are resolved from the resource to fail as any other empty value.
+func (r *ProducerReconciler) literalValue(
+	reference corev1.ObjectReference, produces v1alpha1.ProducesSpec) (string, bool) {
+
+	self := corev1.ObjectReference{APIVersion: reference.APIVersion, Kind: reference.Kind, Name: reference.Name}
+	value, ok := produces.LiteralValue(self)
+	return value, ok && len(value) > 0
+}
+
+// getDataKeyStatus returns a status of a key produced with a literal value from a field of the resource.
+func (r *ProducerReconciler) getDataKeyStatus(
+	reference corev1.ObjectReference, fieldPath, key, value string, sensitive bool) v1alpha1.ProducedKeyStatus {
+
+	reference.FieldPath = fieldPath
+	status := v1alpha1.ProducedKeyStatus{
+		ObjectReference: reference,
+		Status:          v1alpha1.ProducedSuccess,
+		Key:             key,
+		Sensitive:       sensitive,
+	}
+	if len(value) > 0 && !sensitive {
+		status.Value = ptr.To(value)
+	}
+	return status
+}
+
+func (r *ProducerReconciler) getSourceReference(
+	namespace string, source v1alpha1.ProducesSourceSpec) corev1.ObjectReference {
+
+	return corev1.ObjectReference{
+		Kind:       source.Kind,
+		Namespace:  namespace,
+		Name:       source.Name,
+		APIVersion: source.APIVersion,
+		FieldPath:  source.FieldPath,
+	}
+}
+
+func (r *ProducerReconciler) getSourceSelector(source v1alpha1.ProducesSourceSpec) string {
+	if source.Selector == nil {
+		return ""
+	}
+	return metav1.FormatLabelSelector(source.Selector)
+}
+
 func (r *ProducerReconciler) updateStatus(resource *v1alpha1.Tensegrity) {
 	resource.Status.Produced = ptr.To(v1alpha1.ProducedSuccess)
 	condition := v1alpha1.NewTensegrityCondition(
@@ -21,6 +21,7 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reconciler.io/runtime/reconcilers"
 )
@@ -80,6 +81,10 @@ func (r *ProducerConfigMapReconciler) DesiredChild(
 }
 
 func (r *ProducerConfigMapReconciler) MergeBeforeUpdate(current, desired *corev1.ConfigMap) {
+	if !equality.Semantic.DeepEqual(current.Data, desired.Data) ||
+		!equality.Semantic.DeepEqual(current.BinaryData, desired.BinaryData) {
+		setChangedAnnotation(current)
+	}
 	current.Labels = desired.Labels
 	current.Data = desired.Data
 	current.BinaryData = desired.BinaryData
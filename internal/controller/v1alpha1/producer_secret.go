@@ -22,6 +22,7 @@ import (
 	"encoding/base64"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reconciler.io/runtime/reconcilers"
 )
@@ -90,6 +91,9 @@ func (r *ProducerSecretReconciler) OurChild(_ *metav1.PartialObjectMetadata, chi
 }
 
 func (r *ProducerSecretReconciler) MergeBeforeUpdate(current, desired *corev1.Secret) {
+	if !equality.Semantic.DeepEqual(current.Data, desired.Data) {
+		setChangedAnnotation(current)
+	}
 	current.Labels = desired.Labels
 	current.Data = desired.Data
 }
@@ -0,0 +1,451 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// createProducing creates a Static producing keys from sources in the default namespace with names the webhook defaults.
+func createProducing(ctx context.Context, name string, produces ...apiv1alpha1.ProducesSpec) *apiv1alpha1.Static {
+	static := &apiv1alpha1.Static{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: apiv1alpha1.StaticSpec{TensegritySpec: apiv1alpha1.TensegritySpec{
+			ProducesConfigMapName: name + apiv1alpha1.DefaultProducesConfigMapNamePrefix,
+			ProducesSecretName:    name + apiv1alpha1.DefaultProducesSecretNamePrefix,
+			Produces:              produces,
+		}},
+	}
+	Expect(k8sClient.Create(ctx, static)).To(Succeed())
+	return static
+}
+
+// configMapSource refers to a ConfigMap source of a produced key.
+func configMapSource(name, expression string) apiv1alpha1.ProducesSourceSpec {
+	return apiv1alpha1.ProducesSourceSpec{
+		ObjectReference: corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: name},
+		Expression:      expression,
+	}
+}
+
+// selectorSource selects ConfigMap sources of a produced key by labels.
+func selectorSource(labels map[string]string, aggregation apiv1alpha1.AggregationMode) apiv1alpha1.ProducesSourceSpec {
+	return apiv1alpha1.ProducesSourceSpec{
+		ObjectReference: corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap"},
+		Selector:        &metav1.LabelSelector{MatchLabels: labels},
+		Aggregation:     aggregation,
+		Expression:      "self.data.host",
+	}
+}
+
+// producedConfigMapData reads the produced ConfigMap of a Static, which is empty when missing.
+func producedConfigMapData(ctx context.Context, static *apiv1alpha1.Static) map[string]string {
+	produced := new(corev1.ConfigMap)
+	if err := k8sClient.Get(ctx, client.ObjectKey{
+		Namespace: static.Namespace, Name: static.Spec.ProducesConfigMapName}, produced); err != nil {
+		Expect(client.IgnoreNotFound(err)).To(Succeed())
+	}
+	return produced.Data
+}
+
+func producedKey(static *apiv1alpha1.Static, key string) apiv1alpha1.ProducedKeyStatus {
+	for _, produced := range static.Status.ProducedKeys {
+		if produced.Key == key {
+			return produced
+		}
+	}
+	Fail("key " + key + " is not produced")
+	return apiv1alpha1.ProducedKeyStatus{}
+}
+
+var _ = Describe("Producer Reconciler", func() {
+	ctx := context.Background()
+
+	Context("When producing keys by expressions", Ordered, func() {
+		BeforeAll(func() {
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "expression-source", Namespace: "default"},
+				Data:       map[string]string{"host": "postgres.testing", "port": "5432", "sslmode": ""},
+			})).To(Succeed())
+		})
+
+		It("should produce values of expressions", func() {
+			static := createProducing(ctx, "expression-produces",
+				apiv1alpha1.ProducesSpec{Key: "url", ProducesSourceSpec: configMapSource("expression-source",
+					`"postgres://" + self.data.host + ":" + self.data.port`)},
+				apiv1alpha1.ProducesSpec{Key: "sslmode", ProducesSourceSpec: configMapSource("expression-source",
+					`self.data.sslmode != "" ? self.data.sslmode : "disable"`)},
+				apiv1alpha1.ProducesSpec{Key: "port", ProducesSourceSpec: configMapSource("expression-source",
+					`int(self.data.port) + 1`)},
+			)
+			_, err := reconcileStatic(ctx, static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(producedConfigMapData(ctx, static)).To(Equal(map[string]string{
+				"url": "postgres://postgres.testing:5432", "sslmode": "disable", "port": "5433"}))
+			Expect(producedKey(static, "url").Status).To(Equal(apiv1alpha1.ProducedSuccess))
+		})
+
+		It("should fail keys of expressions which fail or evaluate to an empty value", func() {
+			static := createProducing(ctx, "expression-failures",
+				apiv1alpha1.ProducesSpec{Key: "user", ProducesSourceSpec: configMapSource("expression-source",
+					`self.data.user`)},
+				apiv1alpha1.ProducesSpec{Key: "sslmode", ProducesSourceSpec: configMapSource("expression-source",
+					`self.data.sslmode`)},
+			)
+			_, _ = reconcileStatic(ctx, static)
+			Expect(producedConfigMapData(ctx, static)).To(BeEmpty())
+
+			user := producedKey(static, "user")
+			Expect(user.Status).To(Equal(apiv1alpha1.ProducedFailure))
+			Expect(user.Reason).To(HaveValue(HavePrefix("expression: ")))
+			sslmode := producedKey(static, "sslmode")
+			Expect(sslmode.Status).To(Equal(apiv1alpha1.ProducedFailure))
+			Expect(sslmode.Reason).To(HaveValue(ContainSubstring("value is empty")))
+		})
+	})
+
+	Context("When checking readiness of source objects", func() {
+		object := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": int64(2)},
+			"status": map[string]interface{}{
+				"readyReplicas": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "True"},
+					map[string]interface{}{"type": "Progressing", "status": "False"},
+				},
+			},
+		}}
+
+		It("should check a status condition", func() {
+			Expect(producerReconcilerInstance.checkReady(object, nil)).To(Succeed())
+			Expect(producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{
+				ConditionType: "Available"})).To(Succeed())
+			Expect(producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{
+				ConditionType: "Progressing", Status: metav1.ConditionFalse})).To(Succeed())
+
+			err := producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{ConditionType: "Progressing"})
+			Expect(err).To(MatchError("source is not ready, condition Progressing is False"))
+			Expect(failureReason(err)).To(Equal(NotReadyFailure))
+			err = producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{ConditionType: "Ready"})
+			Expect(err).To(MatchError("source is not ready, condition Ready is not found"))
+		})
+
+		It("should check an expression", func() {
+			Expect(producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{
+				Expression: "self.status.readyReplicas > 0"})).To(Succeed())
+
+			err := producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{
+				Expression: "self.status.readyReplicas == self.spec.replicas"})
+			Expect(err).To(MatchError("source is not ready"))
+			Expect(failureReason(err)).To(Equal(NotReadyFailure))
+
+			err = producerReconcilerInstance.checkReady(object, &apiv1alpha1.ReadySpec{
+				Expression: "self.status.updatedReplicas > 0"})
+			Expect(err).To(MatchError(HavePrefix("readyWhen: ")))
+			Expect(failureReason(err)).To(Equal(ExpressionFailure))
+		})
+	})
+
+	Context("When producing keys from sources with readyWhen", Ordered, func() {
+		source := configMapSource("ready-source", "self.data.host")
+		source.ReadyWhen = &apiv1alpha1.ReadySpec{Expression: `self.data.ready == "true"`}
+
+		It("should hold a key back until the source is ready", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "ready-source", Namespace: "default"},
+				Data:       map[string]string{"host": "postgres.testing", "ready": "false"},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+			static := createProducing(ctx, "ready-when",
+				apiv1alpha1.ProducesSpec{Key: "host", ProducesSourceSpec: source})
+			_, _ = reconcileStatic(ctx, static)
+			Expect(producedConfigMapData(ctx, static)).To(BeEmpty())
+			host := producedKey(static, "host")
+			Expect(host.Status).To(Equal(apiv1alpha1.ProducedFailure))
+			Expect(host.Reason).To(HaveValue(Equal("source is not ready")))
+
+			configMap.Data["ready"] = "true"
+			Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+			_, err := reconcileStatic(ctx, static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(producedConfigMapData(ctx, static)).To(Equal(map[string]string{"host": "postgres.testing"}))
+		})
+	})
+
+	Context("When producing keys from fallback sources", Ordered, func() {
+		BeforeAll(func() {
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "fallback-empty", Namespace: "default"},
+				Data:       map[string]string{"host": ""},
+			})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "fallback-source", Namespace: "default"},
+				Data:       map[string]string{"host": "postgres.fallback"},
+			})).To(Succeed())
+		})
+
+		fallbacks := func(sources ...string) []apiv1alpha1.ProducesSourceSpec {
+			specs := make([]apiv1alpha1.ProducesSourceSpec, 0, len(sources))
+			for _, name := range sources {
+				specs = append(specs, configMapSource(name, "self.data.host"))
+			}
+			return specs
+		}
+
+		It("should produce a key from the source when it resolves", func() {
+			static := createProducing(ctx, "fallback-unused", apiv1alpha1.ProducesSpec{
+				Key: "host", ProducesSourceSpec: configMapSource("fallback-source", "self.data.host"),
+				Fallbacks: fallbacks("fallback-missing"),
+			})
+			_, err := reconcileStatic(ctx, static)
+			Expect(err).NotTo(HaveOccurred())
+			host := producedKey(static, "host")
+			Expect(host.Fallback).To(BeNil())
+			Expect(host.FailedSources).To(BeEmpty())
+		})
+
+		It("should produce a key from the first fallback which resolves", func() {
+			static := createProducing(ctx, "fallback-used", apiv1alpha1.ProducesSpec{
+				Key: "host", ProducesSourceSpec: configMapSource("fallback-missing", "self.data.host"),
+				Fallbacks: fallbacks("fallback-empty", "fallback-source", "fallback-missing"),
+			})
+			_, err := reconcileStatic(ctx, static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(producedConfigMapData(ctx, static)).To(Equal(map[string]string{"host": "postgres.fallback"}))
+
+			host := producedKey(static, "host")
+			Expect(host.Status).To(Equal(apiv1alpha1.ProducedSuccess))
+			Expect(host.Name).To(Equal("fallback-source"))
+			Expect(host.Fallback).To(HaveValue(BeEquivalentTo(1)))
+			Expect(host.FailedSources).To(HaveLen(2))
+			Expect(host.FailedSources[0].Name).To(Equal("fallback-missing"))
+			Expect(host.FailedSources[0].Reason).To(ContainSubstring("not found"))
+			Expect(host.FailedSources[1].Name).To(Equal("fallback-empty"))
+			Expect(host.FailedSources[1].Reason).To(ContainSubstring("value is empty"))
+		})
+
+		It("should fail a key with a reason of the last source when no source resolves", func() {
+			static := createProducing(ctx, "fallback-failed", apiv1alpha1.ProducesSpec{
+				Key: "host", ProducesSourceSpec: configMapSource("fallback-missing", "self.data.host"),
+				Fallbacks: fallbacks("fallback-empty"),
+			})
+			_, _ = reconcileStatic(ctx, static)
+			Expect(producedConfigMapData(ctx, static)).To(BeEmpty())
+
+			host := producedKey(static, "host")
+			Expect(host.Status).To(Equal(apiv1alpha1.ProducedFailure))
+			Expect(host.Reason).To(HaveValue(ContainSubstring("value is empty")))
+			Expect(host.Fallback).To(BeNil())
+			Expect(host.FailedSources).To(HaveLen(1))
+			Expect(host.FailedSources[0].Name).To(Equal("fallback-missing"))
+		})
+	})
+
+	Context("When producing keys from selected objects", Ordered, func() {
+		selected := map[string]string{"tensegrity.fastforge.io/test": "aggregation"}
+
+		createSelected := func(name, host string, data map[string]string) {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: selected},
+				Data:       map[string]string{"host": host},
+			}
+			for key, value := range data {
+				configMap.Data[key] = value
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		}
+
+		BeforeAll(func() {
+			createSelected("aggregation-b", "replica-b.testing", nil)
+			createSelected("aggregation-a", "replica-a.testing", nil)
+			createSelected("aggregation-c", "", nil)
+		})
+
+		It("should aggregate values of selected objects ordered by name", func() {
+			joined := selectorSource(selected, "")
+			custom := selectorSource(selected, apiv1alpha1.JoinAggregation)
+			custom.Separator = ptr.To(";")
+			static := createProducing(ctx, "aggregation-modes",
+				apiv1alpha1.ProducesSpec{Key: "join", ProducesSourceSpec: joined},
+				apiv1alpha1.ProducesSpec{Key: "separator", ProducesSourceSpec: custom},
+				apiv1alpha1.ProducesSpec{Key: "json", ProducesSourceSpec: selectorSource(
+					selected, apiv1alpha1.JSONAggregation)},
+				apiv1alpha1.ProducesSpec{Key: "first", ProducesSourceSpec: selectorSource(
+					selected, apiv1alpha1.FirstAggregation)},
+				apiv1alpha1.ProducesSpec{Key: "count", ProducesSourceSpec: selectorSource(
+					selected, apiv1alpha1.CountAggregation)},
+			)
+			_, err := reconcileStatic(ctx, static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(producedConfigMapData(ctx, static)).To(Equal(map[string]string{
+				"join":      "replica-a.testing,replica-b.testing",
+				"separator": "replica-a.testing;replica-b.testing",
+				"json":      `["replica-a.testing","replica-b.testing"]`,
+				"first":     "replica-a.testing",
+				"count":     "3",
+			}))
+			Expect(producedKey(static, "join").Selector).To(Equal("tensegrity.fastforge.io/test=aggregation"))
+		})
+
+		It("should leave out selected objects which are not ready", func() {
+			createSelected("aggregation-d", "replica-d.testing", map[string]string{"ready": "false"})
+			source := selectorSource(selected, apiv1alpha1.JoinAggregation)
+			source.ReadyWhen = &apiv1alpha1.ReadySpec{Expression: `!has(self.data.ready) || self.data.ready == "true"`}
+			static := createProducing(ctx, "aggregation-ready",
+				apiv1alpha1.ProducesSpec{Key: "hosts", ProducesSourceSpec: source})
+			_, err := reconcileStatic(ctx, static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(producedConfigMapData(ctx, static)).To(HaveKeyWithValue(
+				"hosts", "replica-a.testing,replica-b.testing"))
+		})
+
+		It("should fail when no selected object has a value", func() {
+			static := createProducing(ctx, "aggregation-empty",
+				apiv1alpha1.ProducesSpec{Key: "first", ProducesSourceSpec: selectorSource(
+					map[string]string{"tensegrity.fastforge.io/test": "nothing"}, apiv1alpha1.FirstAggregation)})
+			_, _ = reconcileStatic(ctx, static)
+			first := producedKey(static, "first")
+			Expect(first.Status).To(Equal(apiv1alpha1.ProducedFailure))
+			Expect(first.Reason).To(HaveValue(ContainSubstring("no selected objects")))
+		})
+
+		It("should fail selected objects of kinds which are not allowed", func() {
+			source := selectorSource(selected, apiv1alpha1.CountAggregation)
+			source.Kind = "Secret"
+			static := createProducing(ctx, "aggregation-not-allowed",
+				apiv1alpha1.ProducesSpec{Key: "count", ProducesSourceSpec: source})
+			_, _ = reconcileStatic(ctx, static)
+			count := producedKey(static, "count")
+			Expect(count.Status).To(Equal(apiv1alpha1.ProducedFailure))
+			Expect(count.Reason).To(HaveValue(ContainSubstring("kind Secret is not allowed to be watched")))
+		})
+
+		It("should stop watching kinds which are not referred anymore", func() {
+			sources := producerReconcilerInstance.sources
+			configMaps := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+			static := &apiv1alpha1.Static{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "aggregation-ready"}, static)).
+				To(Succeed())
+			static.Spec.Produces = nil
+			Expect(k8sClient.Update(ctx, static)).To(Succeed())
+
+			for _, name := range []string{"aggregation-modes", "aggregation-empty", "aggregation-not-allowed"} {
+				Expect(k8sClient.Delete(ctx, &apiv1alpha1.Static{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}})).To(Succeed())
+			}
+			_, _ = reconcileStatic(ctx, static)
+			sources.mu.Lock()
+			Expect(sources.watched).To(HaveKey(configMaps))
+			Expect(sources.watched[configMaps].referrers).NotTo(HaveKey(static.UID))
+			sources.mu.Unlock()
+
+			// deleted referrers are forgotten by a sweep once they have not been seen for a while
+			sources.now = func() time.Time { return time.Now().Add(sourceWatchReferrerTTL + time.Minute) }
+			defer func() { sources.now = time.Now }()
+			sources.sweep()
+			sources.mu.Lock()
+			defer sources.mu.Unlock()
+			Expect(sources.watched).NotTo(HaveKey(configMaps))
+		})
+	})
+
+	Context("When listing consumers", Ordered, func() {
+		consumes := apiv1alpha1.ConsumesSpec{
+			ObjectReference: corev1.ObjectReference{
+				APIVersion: apiv1alpha1.GroupVersion.String(), Kind: "Static", Name: "consumers-postgres"},
+			Maps: map[string]string{"POSTGRES_HOST": "host"},
+		}
+
+		BeforeAll(func() {
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "consumers-source", Namespace: "default"},
+				Data:       map[string]string{"host": "postgres.testing"},
+			})).To(Succeed())
+			createNamespace(ctx, "consumers-delegating", nil)
+		})
+
+		It("should list consumers of the same namespace and through Namespace delegates", func() {
+			producer := createProducing(ctx, "consumers-postgres", apiv1alpha1.ProducesSpec{
+				Key: "host", ProducesSourceSpec: configMapSource("consumers-source", "self.data.host")})
+			_, err := reconcileStatic(ctx, producer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(producer.Status.Consumers).To(BeEmpty())
+
+			local := createConsumer(ctx, "consumers-local", apiv1alpha1.TensegritySpec{
+				Delegates: []apiv1alpha1.DelegateSpec{namespaceDelegate("default")},
+				Consumes:  []apiv1alpha1.ConsumesSpec{consumes},
+			})
+			_, err = reconcileStatic(ctx, local)
+			Expect(err).NotTo(HaveOccurred())
+
+			delegating := &apiv1alpha1.Static{
+				ObjectMeta: metav1.ObjectMeta{Name: "consumers-delegating", Namespace: "consumers-delegating"},
+				Spec: apiv1alpha1.StaticSpec{TensegritySpec: apiv1alpha1.TensegritySpec{
+					Delegates:             []apiv1alpha1.DelegateSpec{namespaceDelegate("default")},
+					Consumes:              []apiv1alpha1.ConsumesSpec{consumes},
+					ConsumesConfigMapName: "consumers-delegating" + apiv1alpha1.DefaultConsumesConfigMapNamePrefix,
+					ConsumesSecretName:    "consumers-delegating" + apiv1alpha1.DefaultConsumesSecretNamePrefix,
+				}},
+			}
+			Expect(k8sClient.Create(ctx, delegating)).To(Succeed())
+			_, err = reconcileStatic(ctx, delegating)
+			Expect(err).NotTo(HaveOccurred())
+
+			delegate := corev1.ObjectReference{Kind: "Namespace", Name: "default"}
+			Eventually(func() []apiv1alpha1.ConsumerStatus {
+				_, err := reconcileStatic(ctx, producer)
+				Expect(err).NotTo(HaveOccurred())
+				return producer.Status.Consumers
+			}).Should(Equal([]apiv1alpha1.ConsumerStatus{
+				{
+					ObjectReference: corev1.ObjectReference{APIVersion: apiv1alpha1.GroupVersion.String(),
+						Kind: "Static", Namespace: "consumers-delegating", Name: "consumers-delegating"},
+					Delegate: delegate,
+					Keys:     []string{"host"},
+				},
+				{
+					ObjectReference: corev1.ObjectReference{APIVersion: apiv1alpha1.GroupVersion.String(),
+						Kind: "Static", Namespace: "default", Name: "consumers-local"},
+					Delegate: delegate,
+					Keys:     []string{"host"},
+				},
+			}))
+			Expect(producer.Status.ConsumerCount).To(BeEquivalentTo(2))
+
+			By("removing a consumer")
+			Expect(k8sClient.Delete(ctx, delegating)).To(Succeed())
+			Eventually(func() int32 {
+				_, err := reconcileStatic(ctx, producer)
+				Expect(err).NotTo(HaveOccurred())
+				return producer.Status.ConsumerCount
+			}).Should(BeEquivalentTo(1))
+		})
+	})
+})
@@ -0,0 +1,246 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// sourceWatchSweepInterval is how often referrers which are not seen anymore are looked up.
+	sourceWatchSweepInterval = 1 * time.Hour
+	// sourceWatchReferrerTTL forgets a referrer which has not watched a kind for longer, e.g. a deleted one,
+	// it exceeds the default resync period of the manager cache, so live referrers are seen in the meantime.
+	sourceWatchReferrerTTL = 24 * time.Hour
+)
+
+// AnySourceKind allows objects of any kind to be watched when it is one of SourceWatcher kinds.
+var AnySourceKind = schema.GroupKind{Kind: "*"}
+
+// DefaultSourceKinds are kinds of objects SourceWatcher watches unless other kinds are allowed,
+// informers of watched kinds cache objects of all namespaces, so kinds of large or sensitive objects are left out.
+var DefaultSourceKinds = []schema.GroupKind{
+	{Kind: "Pod"},
+	{Kind: "Service"},
+	{Kind: "Endpoints"},
+	{Kind: "ConfigMap"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "apps", Kind: "DaemonSet"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "discovery.k8s.io", Kind: "EndpointSlice"},
+}
+
+var sourcelog = ctrl.Log.WithName("source-watcher")
+
+// SourceWatcher watches kinds of objects produced keys are selected from on demand,
+// and forwards their events to controllers, so membership changes are enqueued as tracked ones.
+// Only allowed kinds are watched, and a kind is not watched anymore when no resource refers to it.
+type SourceWatcher struct {
+	// Kinds are kinds of objects which are allowed to be watched, DefaultSourceKinds when empty.
+	Kinds []schema.GroupKind
+
+	mu      sync.Mutex
+	once    sync.Once
+	ctx     context.Context
+	cache   cache.Cache
+	watched map[schema.GroupVersionKind]*sourceWatch
+	events  []chan event.GenericEvent
+	now     func() time.Time
+}
+
+type sourceWatch struct {
+	registration toolscache.ResourceEventHandlerRegistration
+	informer     cache.Informer
+	referrers    map[types.UID]time.Time
+}
+
+func NewSourceWatcher() *SourceWatcher {
+	return &SourceWatcher{
+		watched: make(map[schema.GroupVersionKind]*sourceWatch),
+		now:     time.Now,
+	}
+}
+
+// Setup returns a source of selected object events for a controller.
+func (w *SourceWatcher) Setup(ctx context.Context, mgr ctrl.Manager) (source.Source, error) {
+	var err error
+	w.once.Do(func() {
+		w.cache = mgr.GetCache()
+		err = mgr.Add(w)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := make(chan event.GenericEvent, 1024)
+	w.events = append(w.events, events)
+	return source.Channel(events, reconcilers.EnqueueTracked(ctx)), nil
+}
+
+// Start implements manager.Runnable, watches of kinds which are not referred anymore are stopped periodically.
+func (w *SourceWatcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	w.ctx = ctx
+	w.mu.Unlock()
+
+	ticker := time.NewTicker(sourceWatchSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.sweep()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Watch starts watching objects of a kind referred by a resource once, objects are watched as unstructured ones
+// the same way as they are read by ProducerReconciler.
+func (w *SourceWatcher) Watch(ctx context.Context, referrer types.UID, gvk schema.GroupVersionKind) error {
+	if !w.allowed(gvk.GroupKind()) {
+		return withFailureReason(NotAllowedFailure, errors.Errorf(
+			"kind %s is not allowed to be watched", gvk.GroupKind()))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx == nil {
+		return errors.New("source watcher is not started")
+	}
+	if watch, ok := w.watched[gvk]; ok {
+		watch.referrers[referrer] = w.now()
+		return nil
+	}
+	obj := new(unstructured.Unstructured)
+	obj.SetGroupVersionKind(gvk)
+	informer, err := w.cache.GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	handle := func(o interface{}) {
+		if tombstone, ok := o.(toolscache.DeletedFinalStateUnknown); ok {
+			o = tombstone.Obj
+		}
+		if obj, ok := o.(client.Object); ok {
+			w.send(obj)
+		}
+	}
+	registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    handle,
+		UpdateFunc: func(_, o interface{}) { handle(o) },
+		DeleteFunc: handle,
+	})
+	if err != nil {
+		return err
+	}
+	w.watched[gvk] = &sourceWatch{
+		registration: registration,
+		informer:     informer,
+		referrers:    map[types.UID]time.Time{referrer: w.now()},
+	}
+	return nil
+}
+
+// Release forgets a referrer of kinds it has not watched since a time, e.g. before resolving its keys,
+// and stops watching kinds which are not referred anymore.
+func (w *SourceWatcher) Release(referrer types.UID, since time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for gvk, watch := range w.watched {
+		if seen, ok := watch.referrers[referrer]; ok && seen.Before(since) {
+			delete(watch.referrers, referrer)
+			w.stopUnreferred(gvk, watch)
+		}
+	}
+}
+
+// sweep forgets referrers which have not watched kinds for sourceWatchReferrerTTL,
+// and stops watching kinds which are not referred anymore.
+func (w *SourceWatcher) sweep() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	expired := w.now().Add(-sourceWatchReferrerTTL)
+	for gvk, watch := range w.watched {
+		for uid, seen := range watch.referrers {
+			if seen.Before(expired) {
+				delete(watch.referrers, uid)
+			}
+		}
+		w.stopUnreferred(gvk, watch)
+	}
+}
+
+func (w *SourceWatcher) stopUnreferred(gvk schema.GroupVersionKind, watch *sourceWatch) {
+	if len(watch.referrers) > 0 {
+		return
+	}
+	sourcelog.Info("stopping watch of a kind which is not referred", "kind", gvk)
+	delete(w.watched, gvk)
+	if err := watch.informer.RemoveEventHandler(watch.registration); err != nil {
+		sourcelog.Error(err, "unable to remove event handler", "kind", gvk)
+	}
+	obj := new(unstructured.Unstructured)
+	obj.SetGroupVersionKind(gvk)
+	if err := w.cache.RemoveInformer(w.ctx, obj); err != nil {
+		sourcelog.Error(err, "unable to remove informer", "kind", gvk)
+	}
+}
+
+func (w *SourceWatcher) allowed(kind schema.GroupKind) bool {
+	kinds := w.Kinds
+	if len(kinds) == 0 {
+		kinds = DefaultSourceKinds
+	}
+	for _, allowed := range kinds {
+		if allowed == AnySourceKind || allowed == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *SourceWatcher) send(obj client.Object) {
+	w.mu.Lock()
+	ctx, events := w.ctx, w.events
+	w.mu.Unlock()
+	for _, ch := range events {
+		select {
+		case ch <- event.GenericEvent{Object: obj}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
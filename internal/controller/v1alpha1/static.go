@@ -32,34 +32,58 @@ import (
 func NewStaticReconciler(
 	config *reconcilers.Config,
 	validationReconciler *ValidationReconciler,
+	staticDataReconciler *StaticDataReconciler,
+	consumerReconciler *ConsumerReconciler,
+	consumerSecretReconciler *ConsumerSecretReconciler,
+	consumerConfigMapReconciler *ConsumerConfigMapReconciler,
 	producerReconciler *ProducerReconciler,
 	producerSecretReconciler *ProducerSecretReconciler,
-	producerConfigMapReconciler *ProducerConfigMapReconciler) *StaticReconciler {
+	producerConfigMapReconciler *ProducerConfigMapReconciler,
+	statusReconciler *StatusReconciler) *StaticReconciler {
 
 	return &StaticReconciler{
 		Name: "StaticReconciler",
 		Setup: func(ctx context.Context, _ ctrl.Manager, builder *builder.Builder) error {
 			builder.Watches(new(apiv1alpha1.Static), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.CronJob), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.DaemonSet), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.Deployment), reconcilers.EnqueueTracked(ctx))
+			builder.Watches(new(k8sv1alpha1.Job), reconcilers.EnqueueTracked(ctx))
 			builder.Watches(new(k8sv1alpha1.StatefulSet), reconcilers.EnqueueTracked(ctx))
 			return nil
 		},
 		Config: *config,
-		Reconciler: reconcilers.Sequence[*apiv1alpha1.Static]{
-			&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
-				Reconciler: validationReconciler,
-			},
-			&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
-				Reconciler: producerReconciler,
-			},
-			&reconcilers.CastResource[*apiv1alpha1.Static, *metav1.PartialObjectMetadata]{
-				Reconciler: producerSecretReconciler,
-			},
-			&reconcilers.CastResource[*apiv1alpha1.Static, *metav1.PartialObjectMetadata]{
-				Reconciler: producerConfigMapReconciler,
-			},
-		},
+		Reconciler: Trace[*apiv1alpha1.Static](&reconcilers.TryCatch[*apiv1alpha1.Static]{
+			Name: "StaticReconciler",
+			Try: TraceSequence(reconcilers.Sequence[*apiv1alpha1.Static]{
+				&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
+					Reconciler: validationReconciler,
+				},
+				staticDataReconciler,
+				&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
+					Reconciler: consumerReconciler,
+				},
+				&reconcilers.CastResource[*apiv1alpha1.Static, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerSecretReconciler,
+				},
+				&reconcilers.CastResource[*apiv1alpha1.Static, *metav1.PartialObjectMetadata]{
+					Reconciler: consumerConfigMapReconciler,
+				},
+				&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
+					Reconciler: producerReconciler,
+				},
+				&reconcilers.CastResource[*apiv1alpha1.Static, *metav1.PartialObjectMetadata]{
+					Reconciler: producerSecretReconciler,
+				},
+				&reconcilers.CastResource[*apiv1alpha1.Static, *metav1.PartialObjectMetadata]{
+					Reconciler: producerConfigMapReconciler,
+				},
+			}),
+			Catch: CatchReconcileError[*apiv1alpha1.Static],
+			Finally: Trace[*apiv1alpha1.Static](&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
+				Reconciler: statusReconciler,
+			}),
+		}),
 	}
 }
 
@@ -0,0 +1,138 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"reconciler.io/runtime/reconcilers"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/internal/sealing"
+)
+
+const producerDataStashKey reconcilers.StashKey = "tensegrity.fastforge.io/producer/data"
+
+// producedData is keys produced with literal values, and a reference to the resource they are produced from.
+type producedData struct {
+	Reference     corev1.ObjectReference
+	Data          map[string]string
+	SensitiveData map[string]string
+	// SealedData is opened values of sealed keys, keys which are not opened are in SealedErrors.
+	SealedData   map[string]string
+	SealedErrors map[string]error
+}
+
+// keysNotLoadedError returns an error of a sealed key which fails to be opened with keys not loaded yet,
+// so the resource is requeued until they are loaded instead of halting.
+func (d *producedData) keysNotLoadedError() error {
+	for _, key := range slices.Sorted(maps.Keys(d.SealedErrors)) {
+		if sealing.IsKeyNotLoaded(d.SealedErrors[key]) {
+			return d.SealedErrors[key]
+		}
+	}
+	return nil
+}
+
+// SealedKeys opens values sealed for a key of a Static, and tells whether they are sealed with the current key.
+type SealedKeys interface {
+	Open(scope sealing.Scope, sealed string) (string, bool, error)
+}
+
+func NewStaticDataReconciler(sealedKeys SealedKeys) *StaticDataReconciler {
+	r := &StaticDataReconciler{SealedKeys: sealedKeys}
+	r.staticDataReconciler = staticDataReconciler{
+		Name: "StaticDataReconciler",
+		Sync: r.Sync,
+	}
+	return r
+}
+
+// StaticDataReconciler validates keys of a Static produced with literal values, opens sealed ones,
+// and stashes them for ProducerReconciler to produce along with keys resolved from sources.
+type StaticDataReconciler struct {
+	staticDataReconciler
+	// SealedKeys opens SealedData, sealed keys fail to be produced without it.
+	SealedKeys SealedKeys
+}
+
+type staticDataReconciler = reconcilers.SyncReconciler[*v1alpha1.Static]
+
+func (r *StaticDataReconciler) Sync(ctx context.Context, resource *v1alpha1.Static) error {
+	if errs := resource.Spec.ValidateData(); len(errs) != 0 {
+		aggrErr := errs.ToAggregate()
+		message := fmt.Sprintf(v1alpha1.SpecInvalidMessage, aggrErr.Error())
+		condition := v1alpha1.NewTensegrityCondition(
+			v1alpha1.TensegrityInvalid, corev1.ConditionTrue,
+			v1alpha1.SpecInvalidReason, message)
+		v1alpha1.SetTensegrityCondition(&resource.Status.TensegrityStatus, *condition)
+		return aggrErr
+	}
+	data := producedData{
+		Reference: corev1.ObjectReference{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "Static",
+			Namespace:  resource.Namespace,
+			Name:       resource.Name,
+		},
+		Data:          resource.Spec.Data,
+		SensitiveData: resource.Spec.SensitiveData,
+	}
+	r.openSealedData(ctx, resource, &data)
+	reconcilers.StashValue(ctx, producerDataStashKey, data)
+	return nil
+}
+
+// openSealedData opens SealedData of a Static, and records an event when keys are sealed with a previous key.
+func (r *StaticDataReconciler) openSealedData(ctx context.Context, resource *v1alpha1.Static, data *producedData) {
+	if len(resource.Spec.SealedData) == 0 {
+		return
+	}
+	data.SealedData = make(map[string]string, len(resource.Spec.SealedData))
+	data.SealedErrors = make(map[string]error)
+	var outdatedKeys []string
+	for key, sealed := range resource.Spec.SealedData {
+		if r.SealedKeys == nil {
+			data.SealedErrors[key] = withFailureReason(SealedFailure, errors.New("sealed values are not enabled"))
+			continue
+		}
+		scope := sealing.Scope{Namespace: resource.Namespace, Name: resource.Name, Key: key}
+		value, current, err := r.SealedKeys.Open(scope, sealed)
+		if err != nil {
+			data.SealedErrors[key] = withFailureReason(SealedFailure, errors.Wrap(err, "sealedData"))
+			continue
+		}
+		data.SealedData[key] = value
+		if !current {
+			outdatedKeys = append(outdatedKeys, key)
+		}
+	}
+	if len(outdatedKeys) > 0 {
+		sort.Strings(outdatedKeys)
+		recordEvent(ctx, resource, corev1.EventTypeWarning, SealedKeysOutdatedReason,
+			"Keys are sealed with a previous sealing key: %s, seal them again with the current key.",
+			strings.Join(outdatedKeys, ", "))
+	}
+}
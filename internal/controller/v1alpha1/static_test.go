@@ -22,6 +22,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -29,8 +30,23 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	tensegrityfastforgeiov1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+	"github.com/fastforgeinc/tensegrity/internal/sealing"
 )
 
+// testSealedKeys opens sealed values by looking them up.
+type testSealedKeys map[string]string
+
+func (k testSealedKeys) Open(_ sealing.Scope, sealed string) (string, bool, error) {
+	return k[sealed], true, nil
+}
+
+// notLoadedSealedKeys fails to open sealed values as if keys are not loaded yet.
+type notLoadedSealedKeys struct{}
+
+func (notLoadedSealedKeys) Open(_ sealing.Scope, _ string) (string, bool, error) {
+	return "", false, sealing.ErrKeysNotLoaded
+}
+
 var _ = Describe("Static Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -70,8 +86,10 @@ var _ = Describe("Static Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := NewStaticReconciler(
-				reconcilerConfig, validationReconciler,
-				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance)
+				reconcilerConfig, validationReconciler, NewStaticDataReconciler(nil),
+				consumerReconcilerInstance, consumerSecretReconcilerInstance, consumerConfigMapReconcilerInstance,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
@@ -80,4 +98,161 @@ var _ = Describe("Static Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When reconciling a resource with data", func() {
+		const resourceName = "test-resource-data"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind Static with data")
+			resource := &tensegrityfastforgeiov1alpha1.Static{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: tensegrityfastforgeiov1alpha1.StaticSpec{
+					TensegritySpec: tensegrityfastforgeiov1alpha1.TensegritySpec{
+						ProducesConfigMapName: resourceName + "-produced",
+						ProducesSecretName:    resourceName + "-produced",
+					},
+					Data:          map[string]string{"host": "postgres.testing"},
+					SensitiveData: map[string]string{"password": "P@33W0RD"},
+					SealedData:    map[string]string{"token": "sealed:v1:0123456789abcdef:dG9rZW4="},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &tensegrityfastforgeiov1alpha1.Static{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should produce data without sources", func() {
+			controllerReconciler := NewStaticReconciler(
+				reconcilerConfig, validationReconciler,
+				NewStaticDataReconciler(testSealedKeys{"sealed:v1:0123456789abcdef:dG9rZW4=": "t0k3n"}),
+				consumerReconcilerInstance, consumerSecretReconcilerInstance, consumerConfigMapReconcilerInstance,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			produced := types.NamespacedName{Name: resourceName + "-produced", Namespace: "default"}
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, produced, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("host", "postgres.testing"))
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, produced, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("password", []byte("P@33W0RD")))
+			Expect(secret.Data).To(HaveKeyWithValue("token", []byte("t0k3n")))
+
+			resource := &tensegrityfastforgeiov1alpha1.Static{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ProducedKeys).To(HaveLen(3))
+			Expect(resource.Status.ProducedKeys[0].Key).To(Equal("host"))
+			Expect(resource.Status.ProducedKeys[0].FieldPath).To(Equal("spec.data"))
+			Expect(resource.Status.ProducedKeys[1].Value).To(BeNil())
+		})
+
+		It("should requeue sealed data until sealing keys are loaded", func() {
+			controllerReconciler := NewStaticReconciler(
+				reconcilerConfig, validationReconciler, NewStaticDataReconciler(notLoadedSealedKeys{}),
+				consumerReconcilerInstance, consumerSecretReconcilerInstance, consumerConfigMapReconcilerInstance,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(sealing.IsKeyNotLoaded(err)).To(BeTrue())
+
+			resource := &tensegrityfastforgeiov1alpha1.Static{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ProducedKeys).To(ContainElement(
+				HaveField("Status", tensegrityfastforgeiov1alpha1.ProducedFailure)))
+		})
+	})
+
+	Context("When reconciling a resource with literal keys", func() {
+		const resourceName = "test-resource-literal"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind Static producing its own literals")
+			self := corev1.ObjectReference{
+				APIVersion: tensegrityfastforgeiov1alpha1.GroupVersion.String(), Kind: "Static", Name: resourceName}
+			host, password := self, self
+			host.FieldPath, password.FieldPath = "postgres.testing", "UEAzM1cwUkQ="
+			resource := &tensegrityfastforgeiov1alpha1.Static{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: tensegrityfastforgeiov1alpha1.StaticSpec{
+					TensegritySpec: tensegrityfastforgeiov1alpha1.TensegritySpec{
+						ProducesConfigMapName: resourceName + "-produced",
+						ProducesSecretName:    resourceName + "-produced",
+						Produces: []tensegrityfastforgeiov1alpha1.ProducesSpec{
+							{Key: "host", ProducesSourceSpec: tensegrityfastforgeiov1alpha1.ProducesSourceSpec{
+								ObjectReference: host}},
+							{Key: "password", Sensitive: true, Encoded: true,
+								ProducesSourceSpec: tensegrityfastforgeiov1alpha1.ProducesSourceSpec{
+									ObjectReference: password}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &tensegrityfastforgeiov1alpha1.Static{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should produce literal keys as written", func() {
+			controllerReconciler := NewStaticReconciler(
+				reconcilerConfig, validationReconciler, NewStaticDataReconciler(nil),
+				consumerReconcilerInstance, consumerSecretReconcilerInstance, consumerConfigMapReconcilerInstance,
+				producerReconcilerInstance, producerSecretReconcilerInstance, producerConfigMapReconcilerInstance,
+				statusReconcilerInstance)
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			produced := types.NamespacedName{Name: resourceName + "-produced", Namespace: "default"}
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, produced, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("host", "postgres.testing"))
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, produced, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("password", []byte("P@33W0RD")))
+
+			resource := &tensegrityfastforgeiov1alpha1.Static{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Spec.Data).To(BeEmpty())
+			Expect(resource.Spec.SensitiveData).To(BeEmpty())
+			Expect(resource.Spec.Produces).To(HaveLen(2))
+			Expect(resource.Status.ProducedKeys).To(HaveLen(2))
+			Expect(resource.Status.ProducedKeys[0].Name).To(Equal(resourceName))
+			Expect(resource.Status.ProducedKeys[0].FieldPath).To(Equal("postgres.testing"))
+		})
+	})
 })
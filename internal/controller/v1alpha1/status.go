@@ -0,0 +1,126 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"reconciler.io/runtime/reconcilers"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+const (
+	workloadRolloutStashKey reconcilers.StashKey = "tensegrity.fastforge.io/workloadRollout"
+	reconcileErrorStashKey  reconcilers.StashKey = "tensegrity.fastforge.io/reconcileError"
+)
+
+// StashWorkloadRollout stashes a reason a child workload is not rolled out yet, empty when it is rolled out.
+func StashWorkloadRollout(ctx context.Context, reason string) {
+	reconcilers.StashValue(ctx, workloadRolloutStashKey, reason)
+}
+
+func workloadRolloutFromContext(ctx context.Context) string {
+	if reason, ok := reconcilers.RetrieveValue(ctx, workloadRolloutStashKey).(string); ok {
+		return reason
+	}
+	return ""
+}
+
+// CatchReconcileError stashes an error of reconcilers run before StatusReconciler, so the resource
+// is reported not ready, it is meant to be a Catch of TryCatch and returns the error as it is.
+// ErrHaltSubReconcilers is not stashed, since conditions already tell why reconcilers are halted.
+func CatchReconcileError[T client.Object](
+	ctx context.Context, _ T, result reconcilers.Result, err error) (reconcilers.Result, error) {
+
+	if err != nil && !errors.Is(err, reconcilers.ErrHaltSubReconcilers) {
+		reconcilers.StashValue(ctx, reconcileErrorStashKey, err)
+	}
+	return result, err
+}
+
+func reconcileErrorFromContext(ctx context.Context) error {
+	if err, ok := reconcilers.RetrieveValue(ctx, reconcileErrorStashKey).(error); ok {
+		return err
+	}
+	return nil
+}
+
+func NewStatusReconciler() *StatusReconciler {
+	r := new(StatusReconciler)
+	r.workloadReconciler = workloadReconciler{
+		Name: "StatusReconciler",
+		Sync: r.Sync,
+	}
+	return r
+}
+
+// StatusReconciler aggregates spec validity, consumed and produced keys, and a child workload rollout
+// into Ready, Reconciling and Stalled conditions with kstatus semantics, and sets ObservedGeneration.
+// It is expected to run after all other reconcilers, even when they fail, their errors are expected
+// to be stashed by CatchReconcileError and are reported as Reconciling, since they are retried.
+type StatusReconciler struct {
+	workloadReconciler
+}
+
+func (r *StatusReconciler) Sync(ctx context.Context, resource *v1alpha1.Tensegrity) error {
+	resource.Status.ObservedGeneration = resource.Generation
+
+	var stalled bool
+	var reason, message string
+	if invalid := v1alpha1.GetTensegrityCondition(resource.Status, v1alpha1.TensegrityInvalid); invalid != nil &&
+		invalid.Status == corev1.ConditionTrue {
+		stalled, reason, message = true, invalid.Reason, invalid.Message
+	} else if consumed := v1alpha1.GetTensegrityCondition(resource.Status, v1alpha1.TensegrityConsumed); consumed != nil &&
+		consumed.Status != corev1.ConditionTrue {
+		reason, message = consumed.Reason, consumed.Message
+	} else if produced := v1alpha1.GetTensegrityCondition(resource.Status, v1alpha1.TensegrityProduced); produced != nil &&
+		produced.Status != corev1.ConditionTrue {
+		reason, message = produced.Reason, produced.Message
+	} else if err := reconcileErrorFromContext(ctx); err != nil {
+		reason, message = v1alpha1.ReconcileFailedReason, fmt.Sprintf(v1alpha1.ReconcileFailedMessage, err)
+	} else if rollout := workloadRolloutFromContext(ctx); len(rollout) > 0 {
+		reason, message = v1alpha1.RolloutInProgressReason, fmt.Sprintf(v1alpha1.RolloutInProgressMessage, rollout)
+	}
+
+	if len(reason) == 0 {
+		v1alpha1.SetTensegrityCondition(&resource.Status, *v1alpha1.NewTensegrityCondition(
+			v1alpha1.TensegrityReady, corev1.ConditionTrue, v1alpha1.ReadyReason, v1alpha1.ReadyMessage))
+		v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityReconciling)
+		v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityStalled)
+		return nil
+	}
+
+	v1alpha1.SetTensegrityCondition(&resource.Status, *v1alpha1.NewTensegrityCondition(
+		v1alpha1.TensegrityReady, corev1.ConditionFalse, reason, message))
+	if stalled {
+		v1alpha1.SetTensegrityCondition(&resource.Status, *v1alpha1.NewTensegrityCondition(
+			v1alpha1.TensegrityStalled, corev1.ConditionTrue, reason, message))
+		v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityReconciling)
+	} else {
+		v1alpha1.SetTensegrityCondition(&resource.Status, *v1alpha1.NewTensegrityCondition(
+			v1alpha1.TensegrityReconciling, corev1.ConditionTrue, reason, message))
+		v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityStalled)
+	}
+	return nil
+}
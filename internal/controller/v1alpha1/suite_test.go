@@ -18,6 +18,7 @@ this program. If not, see http://www.gnu.org/licenses/.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"runtime"
@@ -38,6 +39,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
 	//+kubebuilder:scaffold:imports
@@ -48,13 +50,18 @@ import (
 
 var cfg *rest.Config
 var mgr manager.Manager
+var cancel context.CancelFunc
 var k8sClient client.Client
 var testEnv *envtest.Environment
 var reconcilerConfig *reconcilers.Config
 var validationReconciler *ValidationReconciler
+var consumerReconcilerInstance *ConsumerReconciler
+var consumerSecretReconcilerInstance *ConsumerSecretReconciler
+var consumerConfigMapReconcilerInstance *ConsumerConfigMapReconciler
 var producerReconcilerInstance *ProducerReconciler
 var producerSecretReconcilerInstance *ProducerSecretReconciler
 var producerConfigMapReconcilerInstance *ProducerConfigMapReconciler
+var statusReconcilerInstance *StatusReconciler
 
 func TestControllers(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -67,7 +74,7 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "config", "crd", "bases")},
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "manifests", "crd", "bases")},
 		ErrorIfCRDPathMissing: true,
 
 		// The BinaryAssetsDirectory is only required if you want to run the tests directly
@@ -94,25 +101,65 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
-	mgr, err = controllerruntime.NewManager(cfg, controllerruntime.Options{})
+	mgr, err = controllerruntime.NewManager(cfg, controllerruntime.Options{
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(mgr).NotTo(BeNil())
 
+	// consumers are listed by a field index of the manager cache
+	Expect(indexConsumers(context.Background(), mgr)).To(Succeed())
 	reconcilerConfig = &reconcilers.Config{
-		Client:    k8sClient,
+		Client:    indexedClient{Client: k8sClient},
 		APIReader: mgr.GetAPIReader(),
 		Recorder:  mgr.GetEventRecorderFor("tensegrity"),
 		Tracker:   tracker.New(scheme.Scheme, 1*time.Hour),
 	}
 
 	validationReconciler = NewValidationReconciler()
+	consumerReconcilerInstance = NewConsumerReconciler()
+	consumerSecretReconcilerInstance = NewConsumerSecretReconciler()
+	consumerConfigMapReconcilerInstance = NewConsumerConfigMapReconciler()
 	producerReconcilerInstance = NewProducerReconciler()
 	producerSecretReconcilerInstance = NewProducerSecretReconciler()
 	producerConfigMapReconcilerInstance = NewProducerConfigMapReconciler()
+	statusReconcilerInstance = NewStatusReconciler()
+
+	// selected source objects are watched by the manager cache, events are not forwarded without controllers
+	producerReconcilerInstance.sources.cache = mgr.GetCache()
+	Expect(mgr.Add(producerReconcilerInstance.sources)).To(Succeed())
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(ctx)).To(Succeed())
+	}()
+	Eventually(func() context.Context {
+		producerReconcilerInstance.sources.mu.Lock()
+		defer producerReconcilerInstance.sources.mu.Unlock()
+		return producerReconcilerInstance.sources.ctx
+	}).ShouldNot(BeNil())
 })
 
+// indexedClient lists objects by field indexes from the manager cache, since the API server doesn't support them,
+// other objects are read from the API server, so tests don't wait for the cache.
+type indexedClient struct {
+	client.Client
+}
+
+func (c indexedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := new(client.ListOptions).ApplyOptions(opts)
+	if listOpts.FieldSelector != nil {
+		if _, ok := listOpts.FieldSelector.RequiresExactMatch(consumedProducersIndex); ok {
+			return mgr.GetCache().List(ctx, list, opts...)
+		}
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
+	cancel()
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
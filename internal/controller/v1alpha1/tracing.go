@@ -0,0 +1,154 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"reconciler.io/runtime/reconcilers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TracerName is a name of a tracer spans of reconcilers are started with, spans are not exported
+// until a global tracer provider is set.
+const TracerName = "github.com/fastforgeinc/tensegrity"
+
+const (
+	workloadKindAttribute       = attribute.Key("tensegrity.workload.kind")
+	workloadNamespaceAttribute  = attribute.Key("tensegrity.workload.namespace")
+	workloadNameAttribute       = attribute.Key("tensegrity.workload.name")
+	delegateKindAttribute       = attribute.Key("tensegrity.delegate.kind")
+	delegateNameAttribute       = attribute.Key("tensegrity.delegate.name")
+	delegateNamespaceAttribute  = attribute.Key("tensegrity.delegate.namespace")
+	consumedAPIVersionAttribute = attribute.Key("tensegrity.consumed.apiVersion")
+	consumedKindAttribute       = attribute.Key("tensegrity.consumed.kind")
+	consumedNameAttribute       = attribute.Key("tensegrity.consumed.name")
+	objectKindAttribute         = attribute.Key("tensegrity.object.kind")
+	objectNameAttribute         = attribute.Key("tensegrity.object.name")
+	objectFoundAttribute        = attribute.Key("tensegrity.object.found")
+)
+
+// Trace wraps a sub reconciler into a span named after the reconciler, or after a reconciler
+// it casts a resource for, and tagged with a reconciled workload.
+func Trace[T client.Object](reconciler reconcilers.SubReconciler[T]) reconcilers.SubReconciler[T] {
+	return &tracedReconciler[T]{name: reconcilerName(reconciler), reconciler: reconciler}
+}
+
+// TraceSequence wraps each sub reconciler of a sequence into a span.
+func TraceSequence[T client.Object](sequence reconcilers.Sequence[T]) reconcilers.Sequence[T] {
+	traced := make(reconcilers.Sequence[T], 0, len(sequence))
+	for _, reconciler := range sequence {
+		traced = append(traced, Trace(reconciler))
+	}
+	return traced
+}
+
+type tracedReconciler[T client.Object] struct {
+	name       string
+	reconciler reconcilers.SubReconciler[T]
+}
+
+func (r *tracedReconciler[T]) SetupWithManager(ctx context.Context, mgr ctrl.Manager, bldr *builder.Builder) error {
+	return r.reconciler.SetupWithManager(ctx, mgr, bldr)
+}
+
+func (r *tracedReconciler[T]) Reconcile(ctx context.Context, resource T) (reconcilers.Result, error) {
+	ctx, span := startSpan(ctx, r.name)
+	defer span.End()
+	result, err := r.reconciler.Reconcile(ctx, resource)
+	endSpan(span, err)
+	return result, err
+}
+
+// reconcilerName returns a Name field of a reconciler, CastResource without a name is named after its reconciler,
+// and sequences are named Sequence.
+func reconcilerName(reconciler interface{}) string {
+	value := reflect.ValueOf(reconciler)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Slice {
+		return "Sequence"
+	}
+	if value.Kind() != reflect.Struct {
+		return value.Type().Name()
+	}
+	if name := value.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String && len(name.String()) > 0 {
+		return name.String()
+	}
+	if inner := value.FieldByName("Reconciler"); inner.IsValid() && !inner.IsNil() {
+		return reconcilerName(inner.Interface())
+	}
+	return value.Type().Name()
+}
+
+// startSpan starts a span tagged with a workload of a reconciled request.
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	request := reconcilers.RetrieveRequest(ctx)
+	attributes = append(attributes,
+		workloadNamespaceAttribute.String(request.Namespace),
+		workloadNameAttribute.String(request.Name))
+	if resourceType := reconcilers.RetrieveOriginalResourceType(ctx); resourceType != nil {
+		attributes = append(attributes, workloadKindAttribute.String(resourceKind(ctx, resourceType)))
+	}
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan records an error of a span, halting of sub reconcilers is not an error.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, reconcilers.ErrHaltSubReconcilers) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func delegateAttributes(delegate corev1.ObjectReference, namespace string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		delegateKindAttribute.String(delegate.Kind),
+		delegateNameAttribute.String(delegate.Name),
+		delegateNamespaceAttribute.String(namespace),
+	}
+}
+
+func consumedAttributes(ref corev1.ObjectReference) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		consumedAPIVersionAttribute.String(ref.APIVersion),
+		consumedKindAttribute.String(ref.Kind),
+		consumedNameAttribute.String(ref.Name),
+	}
+}
+
+func objectAttributes(obj client.Object) []attribute.KeyValue {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if len(kind) == 0 {
+		kind = reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	}
+	return []attribute.KeyValue{
+		objectKindAttribute.String(kind),
+		objectNameAttribute.String(obj.GetName()),
+	}
+}
@@ -0,0 +1,92 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	"reconciler.io/runtime/reconcilers"
+
+	apiv1alpha1 "github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("Tracing", func() {
+	It("should name spans after reconcilers", func() {
+		consumerReconciler := NewConsumerReconciler()
+		Expect(reconcilerName(consumerReconciler)).To(Equal("ConsumerReconciler"))
+		Expect(reconcilerName(&reconcilers.CastResource[*apiv1alpha1.Static, *apiv1alpha1.Tensegrity]{
+			Reconciler: consumerReconciler,
+		})).To(Equal("ConsumerReconciler"))
+		Expect(reconcilerName(reconcilers.Sequence[*apiv1alpha1.Tensegrity]{consumerReconciler})).To(Equal("Sequence"))
+	})
+
+	It("should nest spans of a reconcile tagged with a workload, a delegate and a consumed reference", func() {
+		const delegate = "tracing-delegate"
+		ctx := context.Background()
+		recorder := tracetest.NewSpanRecorder()
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		DeferCleanup(otel.SetTracerProvider, previous)
+
+		createNamespace(ctx, delegate, nil)
+		createProducer(ctx, delegate, "postgres", map[string]string{"host": "postgres.tracing"})
+		static := createConsumer(ctx, "traced", apiv1alpha1.TensegritySpec{
+			Delegates: []apiv1alpha1.DelegateSpec{namespaceDelegate(delegate)},
+			Consumes:  []apiv1alpha1.ConsumesSpec{consumesPostgres(map[string]string{"DATABASE_HOST": "host"})},
+		})
+		_, err := reconcileStatic(ctx, static)
+		Expect(err).NotTo(HaveOccurred())
+
+		spansByName := make(map[string][]sdktrace.ReadOnlySpan)
+		for _, span := range recorder.Ended() {
+			spansByName[span.Name()] = append(spansByName[span.Name()], span)
+		}
+		workload := []attribute.KeyValue{
+			workloadKindAttribute.String("Static"),
+			workloadNamespaceAttribute.String("default"),
+			workloadNameAttribute.String("traced"),
+		}
+		consumed := consumedAttributes(consumesPostgres(nil).ObjectReference)
+		delegated := delegateAttributes(corev1.ObjectReference{Kind: "Namespace", Name: delegate}, delegate)
+
+		Expect(spansByName["ConsumerReconciler"]).To(HaveLen(1))
+		consumer := spansByName["ConsumerReconciler"][0]
+		Expect(consumer.Attributes()).To(ContainElements(workload))
+
+		Expect(spansByName["DelegateLookup"]).To(HaveLen(1))
+		lookup := spansByName["DelegateLookup"][0]
+		Expect(lookup.Parent().SpanID()).To(Equal(consumer.SpanContext().SpanID()))
+		Expect(lookup.Attributes()).To(ContainElements(append(workload, delegated...)))
+
+		// the producer Static and its produced ConfigMap are tracked
+		Expect(spansByName["TrackAndGet"]).To(HaveLen(2))
+		for _, get := range spansByName["TrackAndGet"] {
+			Expect(get.Parent().SpanID()).To(Equal(lookup.SpanContext().SpanID()))
+			Expect(get.Attributes()).To(ContainElements(append(append(workload, delegated...), consumed...)))
+		}
+		Expect(spansByName["TrackAndGet"][0].Attributes()).To(ContainElement(objectKindAttribute.String("Static")))
+		Expect(spansByName["TrackAndGet"][1].Attributes()).To(ContainElement(objectKindAttribute.String("ConfigMap")))
+	})
+})
@@ -32,5 +32,6 @@ func (r *ValidationReconciler) Sync(_ context.Context, resource *v1alpha1.Tenseg
 		v1alpha1.SetTensegrityCondition(&resource.Status, *condition)
 		return aggrErr
 	}
+	v1alpha1.RemoveTensegrityCondition(&resource.Status, v1alpha1.TensegrityInvalid)
 	return nil
 }
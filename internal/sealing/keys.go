@@ -0,0 +1,334 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package sealing
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// PublicKeyKey is the key of the ConfigMap holding the current public key.
+	PublicKeyKey = "public.pem"
+	// KeyIDKey is the key of the ConfigMap holding an ID of the current public key.
+	KeyIDKey = "keyID"
+)
+
+const (
+	DefaultRotationPeriod = 30 * 24 * time.Hour
+	DefaultCheckInterval  = 10 * time.Minute
+
+	retryInterval = 5 * time.Second
+	keySuffix     = ".pem"
+	createdHeader = "Created"
+)
+
+var keyslog = logf.Log.WithName("sealing-keys")
+
+var (
+	// ErrKeysNotLoaded is returned when values are opened before keys are loaded.
+	ErrKeysNotLoaded = errors.New("sealing keys are not loaded")
+	// ErrUnknownKey is returned when a value is sealed with a key which is not loaded,
+	// it may be added by another replica and loaded by a next sync.
+	ErrUnknownKey = errors.New("value is sealed with an unknown key")
+)
+
+// IsKeyNotLoaded tells whether a value failed to be opened with keys which are not loaded yet,
+// so opening it again after keys are synced may succeed.
+func IsKeyNotLoaded(err error) bool {
+	return errors.Is(err, ErrKeysNotLoaded) || errors.Is(err, ErrUnknownKey)
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+
+// KeyManager manages keys of sealed values, it keeps private keys in a Secret shared by all replicas, adds a new
+// key every RotationPeriod, and publishes the current public key in a ConfigMap values are sealed with.
+// Previous keys are kept, so values sealed with them are still opened until they are removed from the Secret.
+type KeyManager struct {
+	// Client writes the Secret and the ConfigMap, defaults to the manager client.
+	Client client.Client
+	// Reader reads the Secret and the ConfigMap, defaults to the uncached manager API reader.
+	Reader client.Reader
+	// Secret is the Secret private keys are kept in.
+	Secret types.NamespacedName
+	// ConfigMap is the ConfigMap the current public key is published in.
+	ConfigMap types.NamespacedName
+
+	// RotationPeriod is an age of the current key a new key is added at, zero never adds keys.
+	RotationPeriod time.Duration
+	CheckInterval  time.Duration
+
+	now  func() time.Time
+	keys atomic.Pointer[keyring]
+	// resync triggers a sync before CheckInterval, when a value is sealed with an unknown key.
+	resync chan struct{}
+}
+
+// keyring is private keys by IDs, and an ID and a creation time of the current key.
+type keyring struct {
+	current string
+	created time.Time
+	keys    map[string]*ecdh.PrivateKey
+	// invalid is errors of entries of the Secret which fail to be parsed by their names.
+	invalid map[string]error
+}
+
+// NewKeyManager creates a KeyManager of keys kept in the Secret and published in the ConfigMap.
+func NewKeyManager(client client.Client, reader client.Reader, secret, configMap types.NamespacedName) *KeyManager {
+	return &KeyManager{
+		Client:         client,
+		Reader:         reader,
+		Secret:         secret,
+		ConfigMap:      configMap,
+		RotationPeriod: DefaultRotationPeriod,
+		CheckInterval:  DefaultCheckInterval,
+		now:            time.Now,
+		resync:         make(chan struct{}, 1),
+	}
+}
+
+// SetupWithManager adds the KeyManager to the manager, and a ready check passing once keys are loaded.
+func (m *KeyManager) SetupWithManager(mgr ctrl.Manager) error {
+	if m.Client == nil {
+		m.Client = mgr.GetClient()
+	}
+	if m.Reader == nil {
+		m.Reader = mgr.GetAPIReader()
+	}
+	if err := mgr.Add(m); err != nil {
+		return err
+	}
+	return mgr.AddReadyzCheck("sealing-keys", m.ReadyCheck)
+}
+
+var _ manager.LeaderElectionRunnable = &KeyManager{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica opens sealed values
+// and so loads keys, concurrent rotations are resolved by conflicts on the Secret.
+func (m *KeyManager) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, it syncs keys every CheckInterval, or when a value is sealed
+// with an unknown key, until the context is done.
+func (m *KeyManager) Start(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-m.resync:
+		case <-timer.C:
+		}
+		interval := m.CheckInterval
+		if err := m.Sync(ctx); err != nil {
+			keyslog.Error(err, "failed to sync sealing keys", "secret", m.Secret)
+			interval = retryInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// ReadyCheck fails until keys are loaded.
+func (m *KeyManager) ReadyCheck(_ *http.Request) error {
+	if m.keys.Load() == nil {
+		return ErrKeysNotLoaded
+	}
+	return nil
+}
+
+// Sync adds a key to the Secret if there are none or the current one is older than RotationPeriod,
+// loads keys, and publishes the current public key.
+func (m *KeyManager) Sync(ctx context.Context) error {
+	secret, err := m.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := loadKeys(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load sealing keys of Secret %s", m.Secret)
+	}
+	for name, err := range keys.invalid {
+		keyslog.Error(err, "skipping invalid sealing key", "secret", m.Secret, "key", name)
+	}
+	m.keys.Store(keys)
+	return m.publish(ctx, keys)
+}
+
+// Open opens a sealed value of the key of a Static, and tells whether it is sealed with the current key,
+// a value sealed with an unknown key triggers a sync to load keys added by other replicas.
+func (m *KeyManager) Open(scope Scope, sealed string) (string, bool, error) {
+	keys := m.keys.Load()
+	if keys == nil {
+		return "", false, ErrKeysNotLoaded
+	}
+	keyID, err := SealedKeyID(sealed)
+	if err != nil {
+		return "", false, err
+	}
+	privateKey, ok := keys.keys[keyID]
+	if !ok {
+		select {
+		case m.resync <- struct{}{}:
+		default:
+		}
+		return "", false, errors.Wrapf(ErrUnknownKey, "key %s", keyID)
+	}
+	value, err := Open(privateKey, scope, sealed)
+	return value, keyID == keys.current, err
+}
+
+// ensureSecret gets the Secret, and creates or updates it with a new key when rotation is due,
+// conflicting writes of other replicas are retried with the Secret they wrote.
+func (m *KeyManager) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := new(corev1.Secret)
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret = new(corev1.Secret)
+		if err := m.Reader.Get(ctx, m.Secret, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{Type: corev1.SecretTypeOpaque}
+			secret.Name = m.Secret.Name
+			secret.Namespace = m.Secret.Namespace
+			if _, err := m.rotate(secret); err != nil {
+				return err
+			}
+			keyslog.Info("creating sealing keys", "secret", m.Secret)
+			return m.Client.Create(ctx, secret)
+		}
+
+		rotated, err := m.rotate(secret)
+		if err != nil || !rotated {
+			return err
+		}
+		keyslog.Info("adding sealing key", "secret", m.Secret)
+		return m.Client.Update(ctx, secret)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to ensure sealing keys of Secret %s", m.Secret)
+	}
+	return secret, nil
+}
+
+// rotate adds a new key to the Secret when it has no valid keys or the current one is older than RotationPeriod,
+// it returns true if the Secret data is changed. Invalid keys are left for loadKeys to skip, so they don't
+// add a key on every sync.
+func (m *KeyManager) rotate(secret *corev1.Secret) (bool, error) {
+	now := m.now()
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+
+	if keys, err := loadKeys(secret); err == nil {
+		if m.RotationPeriod <= 0 || now.Before(keys.created.Add(m.RotationPeriod)) {
+			return false, nil
+		}
+	}
+
+	privateKey, err := GenerateKey()
+	if err != nil {
+		return false, err
+	}
+	data, err := EncodePrivateKey(privateKey, map[string]string{createdHeader: now.UTC().Format(time.RFC3339)})
+	if err != nil {
+		return false, err
+	}
+	secret.Data[KeyID(privateKey.PublicKey())+keySuffix] = data
+	return true, nil
+}
+
+// publish creates or updates the ConfigMap with the current public key.
+func (m *KeyManager) publish(ctx context.Context, keys *keyring) error {
+	publicKey, err := EncodePublicKey(keys.keys[keys.current].PublicKey())
+	if err != nil {
+		return err
+	}
+	err = retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		configMap := new(corev1.ConfigMap)
+		if err := m.Reader.Get(ctx, m.ConfigMap, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			configMap.Name = m.ConfigMap.Name
+			configMap.Namespace = m.ConfigMap.Namespace
+			configMap.Data = map[string]string{PublicKeyKey: string(publicKey), KeyIDKey: keys.current}
+			return m.Client.Create(ctx, configMap)
+		}
+		if bytes.Equal([]byte(configMap.Data[PublicKeyKey]), publicKey) && configMap.Data[KeyIDKey] == keys.current {
+			return nil
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[PublicKeyKey] = string(publicKey)
+		configMap.Data[KeyIDKey] = keys.current
+		keyslog.Info("publishing sealing public key", "configMap", m.ConfigMap, "keyID", keys.current)
+		return m.Client.Update(ctx, configMap)
+	})
+	return errors.Wrapf(err, "failed to publish sealing public key to ConfigMap %s", m.ConfigMap)
+}
+
+// loadKeys parses keys of the Secret, the current key is the one created last,
+// keys which fail to be parsed are skipped and kept in invalid.
+func loadKeys(secret *corev1.Secret) (*keyring, error) {
+	keys := &keyring{keys: make(map[string]*ecdh.PrivateKey, len(secret.Data)), invalid: make(map[string]error)}
+	for name, data := range secret.Data {
+		if !strings.HasSuffix(name, keySuffix) {
+			continue
+		}
+		privateKey, headers, err := ParsePrivateKey(data)
+		if err != nil {
+			keys.invalid[name] = err
+			continue
+		}
+		keyID := KeyID(privateKey.PublicKey())
+		keys.keys[keyID] = privateKey
+		created, _ := time.Parse(time.RFC3339, headers[createdHeader])
+		if len(keys.current) == 0 || created.After(keys.created) ||
+			(created.Equal(keys.created) && keyID > keys.current) {
+			keys.current = keyID
+			keys.created = created
+		}
+	}
+	if len(keys.current) == 0 {
+		return nil, errors.Errorf("no valid keys found, %d keys are invalid", len(keys.invalid))
+	}
+	return keys, nil
+}
@@ -0,0 +1,154 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package sealing
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("KeyManager", func() {
+	secretKey := types.NamespacedName{Namespace: "tensegrity", Name: "tensegrity-sealing-keys"}
+	configMapKey := types.NamespacedName{Namespace: "tensegrity", Name: "tensegrity-sealing-public-key"}
+	scope := Scope{Namespace: "default", Name: "postgres", Key: "password"}
+
+	var ctx context.Context
+	var c client.Client
+	var keys *KeyManager
+	var now time.Time
+
+	getPublicKey := func() *corev1.ConfigMap {
+		configMap := new(corev1.ConfigMap)
+		Expect(c.Get(ctx, configMapKey, configMap)).To(Succeed())
+		return configMap
+	}
+
+	seal := func(value string) string {
+		publicKey, err := ParsePublicKey([]byte(getPublicKey().Data[PublicKeyKey]))
+		Expect(err).NotTo(HaveOccurred())
+		sealed, err := Seal(publicKey, scope, value)
+		Expect(err).NotTo(HaveOccurred())
+		return sealed
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now()
+		c = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+		keys = NewKeyManager(c, c, secretKey, configMapKey)
+		keys.now = func() time.Time { return now }
+	})
+
+	It("should create a key and publish its public key", func() {
+		Expect(keys.ReadyCheck(nil)).NotTo(Succeed())
+		Expect(keys.Sync(ctx)).To(Succeed())
+		Expect(keys.ReadyCheck(nil)).To(Succeed())
+
+		secret := new(corev1.Secret)
+		Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveLen(1))
+		Expect(secret.Data).To(HaveKey(getPublicKey().Data[KeyIDKey] + ".pem"))
+
+		value, current, err := keys.Open(scope, seal("P@33W0RD"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("P@33W0RD"))
+		Expect(current).To(BeTrue())
+	})
+
+	It("should keep the key until rotation is due", func() {
+		Expect(keys.Sync(ctx)).To(Succeed())
+		keyID := getPublicKey().Data[KeyIDKey]
+
+		now = now.Add(DefaultRotationPeriod - time.Hour)
+		Expect(keys.Sync(ctx)).To(Succeed())
+		Expect(getPublicKey().Data[KeyIDKey]).To(Equal(keyID))
+	})
+
+	It("should add a key and keep opening values sealed with the previous one", func() {
+		Expect(keys.Sync(ctx)).To(Succeed())
+		keyID := getPublicKey().Data[KeyIDKey]
+		sealed := seal("P@33W0RD")
+
+		now = now.Add(DefaultRotationPeriod + time.Hour)
+		Expect(keys.Sync(ctx)).To(Succeed())
+		Expect(getPublicKey().Data[KeyIDKey]).NotTo(Equal(keyID))
+
+		value, current, err := keys.Open(scope, sealed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("P@33W0RD"))
+		Expect(current).To(BeFalse())
+
+		_, current, err = keys.Open(scope, seal("P@33W0RD"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(BeTrue())
+	})
+
+	It("should not rotate keys when rotation is disabled", func() {
+		keys.RotationPeriod = 0
+		Expect(keys.Sync(ctx)).To(Succeed())
+		keyID := getPublicKey().Data[KeyIDKey]
+
+		now = now.Add(10 * DefaultRotationPeriod)
+		Expect(keys.Sync(ctx)).To(Succeed())
+		Expect(getPublicKey().Data[KeyIDKey]).To(Equal(keyID))
+	})
+
+	It("should skip invalid keys without adding a key", func() {
+		Expect(keys.Sync(ctx)).To(Succeed())
+		keyID := getPublicKey().Data[KeyIDKey]
+		secret := new(corev1.Secret)
+		Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+		secret.Data["invalid.pem"] = []byte("not a key")
+		Expect(c.Update(ctx, secret)).To(Succeed())
+
+		for range 3 {
+			Expect(keys.Sync(ctx)).To(Succeed())
+		}
+		Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveLen(2))
+		Expect(getPublicKey().Data[KeyIDKey]).To(Equal(keyID))
+	})
+
+	It("should tell values which fail to be opened with keys not loaded yet", func() {
+		other := NewKeyManager(c, c, types.NamespacedName{Namespace: "other", Name: secretKey.Name}, configMapKey)
+		Expect(other.Sync(ctx)).To(Succeed())
+		sealed := seal("P@33W0RD")
+
+		_, _, err := keys.Open(scope, sealed)
+		Expect(IsKeyNotLoaded(err)).To(BeTrue())
+
+		keys.RotationPeriod = 0
+		keys.ConfigMap = types.NamespacedName{Namespace: "other", Name: configMapKey.Name}
+		Expect(keys.Sync(ctx)).To(Succeed())
+		_, _, err = keys.Open(scope, sealed)
+		Expect(IsKeyNotLoaded(err)).To(BeTrue())
+		Expect(keys.resync).To(Receive())
+
+		_, _, err = keys.Open(scope, "sealed:v1:")
+		Expect(IsKeyNotLoaded(err)).To(BeFalse())
+	})
+})
@@ -0,0 +1,218 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+// Package sealing encrypts values with a public key of Tensegrity controller, so sensitive values of Statics
+// can be kept in Git, and only the controller holding the private key decrypts them into produced Secrets.
+//
+// A value is sealed with an ephemeral X25519 key agreed with the controller key, the shared secret is expanded
+// by HKDF-SHA256 into an AES-256-GCM key, and the namespace, name and key of the Static are authenticated
+// along with the value, so a sealed value can't be moved to another Static or key.
+package sealing
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+const (
+	// Prefix starts every sealed value, followed by an ID of the key it is sealed with.
+	Prefix = v1alpha1.SealedValuePrefix
+
+	publicKeyBlockType  = "PUBLIC KEY"
+	privateKeyBlockType = "PRIVATE KEY"
+	hkdfInfo            = "tensegrity.fastforge.io/sealed-value/v1"
+	keyIDLength         = 8
+	nonceSize           = 12
+)
+
+// Scope is a key of a Static a value is sealed for.
+type Scope struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+func (s Scope) additionalData() []byte {
+	return []byte(s.Namespace + "/" + s.Name + "/" + s.Key)
+}
+
+// GenerateKey generates a new private key.
+func GenerateKey() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// KeyID returns an ID of a public key, a hex prefix of its SHA-256 digest.
+func KeyID(publicKey *ecdh.PublicKey) string {
+	digest := sha256.Sum256(publicKey.Bytes())
+	return hex.EncodeToString(digest[:keyIDLength])
+}
+
+// EncodePublicKey encodes a public key into PEM.
+func EncodePublicKey(publicKey *ecdh.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyBlockType, Bytes: der}), nil
+}
+
+// ParsePublicKey parses a public key encoded by EncodePublicKey.
+func ParsePublicKey(data []byte) (*ecdh.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != publicKeyBlockType {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*ecdh.PublicKey)
+	if !ok || publicKey.Curve() != ecdh.X25519() {
+		return nil, errors.New("public key is not an X25519 key")
+	}
+	return publicKey, nil
+}
+
+// EncodePrivateKey encodes a private key into PEM, with headers if any.
+func EncodePrivateKey(privateKey *ecdh.PrivateKey, headers map[string]string) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: privateKeyBlockType, Headers: headers, Bytes: der}), nil
+}
+
+// ParsePrivateKey parses a private key encoded by EncodePrivateKey, and returns its headers.
+func ParsePrivateKey(data []byte) (*ecdh.PrivateKey, map[string]string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != privateKeyBlockType {
+		return nil, nil, errors.New("no PEM encoded private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, ok := key.(*ecdh.PrivateKey)
+	if !ok || privateKey.Curve() != ecdh.X25519() {
+		return nil, nil, errors.New("private key is not an X25519 key")
+	}
+	return privateKey, block.Headers, nil
+}
+
+// Seal encrypts a value of the key of a Static with a public key.
+func Seal(publicKey *ecdh.PublicKey, scope Scope, value string) (string, error) {
+	ephemeral, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(ephemeral, publicKey, ephemeral.PublicKey(), publicKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := append(ephemeral.PublicKey().Bytes(), nonce...)
+	sealed = aead.Seal(sealed, nonce, []byte(value), scope.additionalData())
+	return Prefix + KeyID(publicKey) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value of the key of a Static with a private key.
+func Open(privateKey *ecdh.PrivateKey, scope Scope, sealed string) (string, error) {
+	keyID, data, err := parse(sealed)
+	if err != nil {
+		return "", err
+	}
+	if keyID != KeyID(privateKey.PublicKey()) {
+		return "", errors.Errorf("value is sealed with unknown key %s", keyID)
+	}
+	size := len(privateKey.PublicKey().Bytes())
+	if len(data) < size+nonceSize {
+		return "", errors.New("sealed value is too short")
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(data[:size])
+	if err != nil {
+		return "", errors.Wrap(err, "invalid sealed value")
+	}
+	aead, err := newAEAD(privateKey, ephemeral, ephemeral, privateKey.PublicKey())
+	if err != nil {
+		return "", err
+	}
+	value, err := aead.Open(nil, data[size:size+nonceSize], data[size+nonceSize:], scope.additionalData())
+	if err != nil {
+		return "", errors.New("sealed value can't be decrypted, it is sealed for another Static or key")
+	}
+	return string(value), nil
+}
+
+// SealedKeyID returns an ID of the key a value is sealed with.
+func SealedKeyID(sealed string) (string, error) {
+	keyID, _, err := parse(sealed)
+	return keyID, err
+}
+
+func parse(sealed string) (string, []byte, error) {
+	if !strings.HasPrefix(sealed, Prefix) {
+		return "", nil, errors.Errorf("sealed value must start with %q", Prefix)
+	}
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(sealed, Prefix), ":")
+	if !ok || len(keyID) != hex.EncodedLen(keyIDLength) {
+		return "", nil, errors.New("sealed value has no key ID")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "invalid sealed value")
+	}
+	return keyID, data, nil
+}
+
+// newAEAD derives an AES-GCM cipher from a key agreement, bound to both public keys.
+func newAEAD(
+	privateKey *ecdh.PrivateKey,
+	peerKey, ephemeralKey, recipientKey *ecdh.PublicKey) (cipher.AEAD, error) {
+
+	shared, err := privateKey.ECDH(peerKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := bytes.Join([][]byte{ephemeralKey.Bytes(), recipientKey.Bytes()}, nil)
+	key, err := hkdf.Key(sha256.New, shared, salt, hkdfInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,94 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package sealing
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Seal", func() {
+	scope := Scope{Namespace: "default", Name: "postgres", Key: "password"}
+
+	It("should open a sealed value", func() {
+		privateKey, err := GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		sealed, err := Seal(privateKey.PublicKey(), scope, "P@33W0RD")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sealed).To(HavePrefix(Prefix + KeyID(privateKey.PublicKey()) + ":"))
+		Expect(sealed).NotTo(ContainSubstring("P@33W0RD"))
+
+		value, err := Open(privateKey, scope, sealed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("P@33W0RD"))
+	})
+
+	It("should not open a value sealed for another Static or key", func() {
+		privateKey, err := GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		sealed, err := Seal(privateKey.PublicKey(), scope, "P@33W0RD")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Open(privateKey, Scope{Namespace: "default", Name: "redis", Key: "password"}, sealed)
+		Expect(err).To(HaveOccurred())
+		_, err = Open(privateKey, Scope{Namespace: "default", Name: "postgres", Key: "user"}, sealed)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not open a value sealed with another key", func() {
+		privateKey, err := GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		otherKey, err := GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		sealed, err := Seal(otherKey.PublicKey(), scope, "P@33W0RD")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = Open(privateKey, scope, sealed)
+		Expect(err).To(MatchError(ContainSubstring("unknown key")))
+	})
+
+	It("should reject malformed values", func() {
+		privateKey, err := GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		keyID := KeyID(privateKey.PublicKey())
+		for _, sealed := range []string{"P@33W0RD", Prefix, Prefix + keyID + ":!", Prefix + keyID + ":AAAA"} {
+			_, err = Open(privateKey, scope, sealed)
+			Expect(err).To(HaveOccurred(), sealed)
+		}
+	})
+
+	It("should encode and parse keys", func() {
+		privateKey, err := GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		data, err := EncodePrivateKey(privateKey, map[string]string{"Created": "2026-01-01T00:00:00Z"})
+		Expect(err).NotTo(HaveOccurred())
+		parsed, headers, err := ParsePrivateKey(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.Equal(privateKey)).To(BeTrue())
+		Expect(headers).To(HaveKeyWithValue("Created", "2026-01-01T00:00:00Z"))
+
+		data, err = EncodePublicKey(privateKey.PublicKey())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.HasPrefix(string(data), "-----BEGIN PUBLIC KEY-----")).To(BeTrue())
+		publicKey, err := ParsePublicKey(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publicKey.Equal(privateKey.PublicKey())).To(BeTrue())
+	})
+})
@@ -0,0 +1,177 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	certificateBlockType = "CERTIFICATE"
+	privateKeyBlockType  = "EC PRIVATE KEY"
+)
+
+// keyPair is a parsed certificate together with its private key and their PEM encodings.
+type keyPair struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newCA creates a self-signed CA valid for the given duration.
+func newCA(commonName string, validity time.Duration, now time.Time) (*keyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newKeyPair(template, nil)
+}
+
+// newServingCert creates a serving certificate for the given DNS names signed by the CA.
+func newServingCert(ca *keyPair, dnsNames []string, validity time.Duration, now time.Time) (*keyPair, error) {
+	if len(dnsNames) == 0 {
+		return nil, errors.New("no DNS names of serving certificate")
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return newKeyPair(template, ca)
+}
+
+// newKeyPair generates a key and a certificate from the template, signed by the parent or self-signed if nil.
+func newKeyPair(template *x509.Certificate, parent *keyPair) (*keyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate key")
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial number")
+	}
+	template.SerialNumber = serialNumber
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, key.Public(), parentKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create certificate %s", template.Subject.CommonName)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse certificate %s", template.Subject.CommonName)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal key")
+	}
+	return &keyPair{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: certificateBlockType, Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: privateKeyBlockType, Bytes: keyDER}),
+	}, nil
+}
+
+// parseKeyPair parses the first certificate of certPEM and the key of keyPEM,
+// and checks that they belong together.
+func parseKeyPair(certPEM, keyPEM []byte) (*keyPair, error) {
+	certs := parseCertificates(certPEM)
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != privateKeyBlockType {
+		return nil, errors.New("no private key found")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+	if !key.PublicKey.Equal(certs[0].PublicKey) {
+		return nil, errors.New("private key does not match certificate")
+	}
+	return &keyPair{
+		cert:    certs[0],
+		key:     key,
+		certPEM: encodeCertificates(certs[0]),
+		keyPEM:  keyPEM,
+	}, nil
+}
+
+// parseCertificates parses all certificates of a PEM bundle, blocks which fail to parse are skipped.
+func parseCertificates(bundle []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return certs
+		}
+		if block.Type != certificateBlockType {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
+
+// encodeCertificates encodes certificates into a PEM bundle.
+func encodeCertificates(certs ...*x509.Certificate) []byte {
+	var bundle []byte
+	for _, cert := range certs {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: certificateBlockType, Bytes: cert.Raw})...)
+	}
+	return bundle
+}
+
+// needsRenewal returns true if the certificate expires within renewBefore.
+func needsRenewal(cert *x509.Certificate, now time.Time, renewBefore time.Duration) bool {
+	return now.Add(renewBefore).After(cert.NotAfter)
+}
+
+// coversDNSNames returns true if the certificate is valid for all DNS names.
+func coversDNSNames(cert *x509.Certificate, dnsNames []string) bool {
+	for _, dnsName := range dnsNames {
+		if cert.VerifyHostname(dnsName) != nil {
+			return false
+		}
+	}
+	return true
+}
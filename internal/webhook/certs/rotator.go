@@ -0,0 +1,378 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// CACertKey is the key of the Secret holding the CA bundle, the current CA goes first,
+	// followed by previous CAs until they expire so serving certificates signed by them stay trusted.
+	CACertKey = "ca.crt"
+	// CAKeyKey is the key of the Secret holding the private key of the current CA.
+	CAKeyKey = "ca.key"
+)
+
+const (
+	DefaultCAValidity    = 10 * 365 * 24 * time.Hour
+	DefaultCertValidity  = 365 * 24 * time.Hour
+	DefaultRenewBefore   = 90 * 24 * time.Hour
+	DefaultCheckInterval = 10 * time.Minute
+
+	retryInterval = 5 * time.Second
+	caCommonName  = "tensegrity-webhook-ca"
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+var rotatorlog = logf.Log.WithName("cert-rotator")
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;patch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;patch
+
+// Rotator manages serving certificates of the webhook server without cert-manager, it generates a CA and
+// a serving certificate, keeps them in a Secret shared by all replicas, renews them before they expire,
+// and injects the CA into caBundle of webhook configurations and conversion webhooks of CustomResourceDefinitions.
+type Rotator struct {
+	// Client writes the Secret and webhook configurations, defaults to the manager client.
+	Client client.Client
+	// Reader reads the Secret and webhook configurations, defaults to the uncached manager API reader.
+	Reader client.Reader
+	// Secret is the Secret certificates are kept in.
+	Secret types.NamespacedName
+	// DNSNames are names the serving certificate is valid for.
+	DNSNames []string
+	// MutatingWebhookConfigurations are names of configurations caBundle is injected into.
+	MutatingWebhookConfigurations []string
+	// ValidatingWebhookConfigurations are names of configurations caBundle is injected into.
+	ValidatingWebhookConfigurations []string
+	// CustomResourceDefinitions are names of CRDs caBundle of a conversion webhook is injected into.
+	CustomResourceDefinitions []string
+
+	CAValidity    time.Duration
+	CertValidity  time.Duration
+	RenewBefore   time.Duration
+	CheckInterval time.Duration
+
+	now         func() time.Time
+	certificate atomic.Pointer[tls.Certificate]
+}
+
+// NewRotator creates a Rotator of a serving certificate for the DNS names kept in the Secret.
+func NewRotator(client client.Client, reader client.Reader, secret types.NamespacedName, dnsNames ...string) *Rotator {
+	return &Rotator{
+		Client:        client,
+		Reader:        reader,
+		Secret:        secret,
+		DNSNames:      dnsNames,
+		CAValidity:    DefaultCAValidity,
+		CertValidity:  DefaultCertValidity,
+		RenewBefore:   DefaultRenewBefore,
+		CheckInterval: DefaultCheckInterval,
+		now:           time.Now,
+	}
+}
+
+// ServiceDNSNames returns DNS names of a Service inside the cluster.
+func ServiceDNSNames(name, namespace string) []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", name, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace),
+	}
+}
+
+// InClusterNamespace returns namespace of the service account the controller runs as,
+// or the fallback when it runs outside a cluster.
+func InClusterNamespace(fallback string) string {
+	if namespace, err := os.ReadFile(namespaceFile); err == nil {
+		return strings.TrimSpace(string(namespace))
+	}
+	return fallback
+}
+
+// SetupWithManager adds the Rotator to the manager, and a ready check passing once a certificate is served.
+func (r *Rotator) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	if r.Reader == nil {
+		r.Reader = mgr.GetAPIReader()
+	}
+	if err := mgr.Add(r); err != nil {
+		return err
+	}
+	return mgr.AddReadyzCheck("webhook-certs", r.ReadyCheck)
+}
+
+var _ manager.LeaderElectionRunnable = &Rotator{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica serves webhooks
+// and so loads certificates, concurrent renewals are resolved by conflicts on the Secret.
+func (r *Rotator) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, it rotates certificates every CheckInterval until the context is done.
+func (r *Rotator) Start(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+		interval := r.CheckInterval
+		if err := r.Rotate(ctx); err != nil {
+			rotatorlog.Error(err, "failed to rotate webhook certificates", "secret", r.Secret)
+			interval = retryInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// ConfigureTLS sets GetCertificate of the webhook server TLS config, so certificates are served from memory.
+func (r *Rotator) ConfigureTLS(config *tls.Config) {
+	config.GetCertificate = r.GetCertificate
+}
+
+// GetCertificate returns the current serving certificate.
+func (r *Rotator) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if certificate := r.certificate.Load(); certificate != nil {
+		return certificate, nil
+	}
+	return nil, errors.New("webhook serving certificate is not ready")
+}
+
+// ReadyCheck fails until a serving certificate is loaded.
+func (r *Rotator) ReadyCheck(_ *http.Request) error {
+	if r.certificate.Load() == nil {
+		return errors.New("webhook serving certificate is not ready")
+	}
+	return nil
+}
+
+// Rotate renews certificates kept in the Secret if they are missing, invalid or about to expire,
+// injects the CA bundle into webhook configurations, and only then loads the serving certificate,
+// so it is never served before its CA is trusted. A certificate which is served already is kept
+// while the CA bundle fails to be injected.
+func (r *Rotator) Rotate(ctx context.Context) error {
+	secret, err := r.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return errors.Wrapf(err, "failed to load serving certificate of Secret %s", r.Secret)
+	}
+	if err = r.injectCABundle(ctx, secret.Data[CACertKey]); err != nil {
+		r.certificate.CompareAndSwap(nil, &certificate)
+		return err
+	}
+	r.certificate.Store(&certificate)
+	return nil
+}
+
+// ensureSecret gets the Secret, and creates or updates it with renewed certificates,
+// conflicting writes of other replicas are retried with the Secret they wrote.
+func (r *Rotator) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := new(corev1.Secret)
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret = new(corev1.Secret)
+		if err := r.Reader.Get(ctx, r.Secret, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{Type: corev1.SecretTypeTLS}
+			secret.Name = r.Secret.Name
+			secret.Namespace = r.Secret.Namespace
+			if _, err := r.renew(secret); err != nil {
+				return err
+			}
+			rotatorlog.Info("creating webhook certificates", "secret", r.Secret)
+			return r.Client.Create(ctx, secret)
+		}
+
+		renewed, err := r.renew(secret)
+		if err != nil || !renewed {
+			return err
+		}
+		rotatorlog.Info("renewing webhook certificates", "secret", r.Secret)
+		return r.Client.Update(ctx, secret)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to ensure webhook certificates of Secret %s", r.Secret)
+	}
+	return secret, nil
+}
+
+// renew generates the CA and the serving certificate of the Secret when they are missing, invalid,
+// or expire within RenewBefore, it returns true if the Secret data is changed. A renewed CA is added
+// to the CA bundle one check before it signs the serving certificate, so the bundle is injected by then,
+// unless the serving certificate isn't trusted or has expired already.
+func (r *Rotator) renew(secret *corev1.Secret) (bool, error) {
+	now := r.now()
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+
+	renewed, caRenewed := false, false
+	ca, err := parseKeyPair(secret.Data[CACertKey], secret.Data[CAKeyKey])
+	if err != nil || needsRenewal(ca.cert, now, r.RenewBefore) {
+		ca, err = newCA(caCommonName, r.CAValidity, now)
+		if err != nil {
+			return false, err
+		}
+		bundle := []*x509.Certificate{ca.cert}
+		for _, previous := range parseCertificates(secret.Data[CACertKey]) {
+			if now.Before(previous.NotAfter) {
+				bundle = append(bundle, previous)
+			}
+		}
+		secret.Data[CACertKey] = encodeCertificates(bundle...)
+		secret.Data[CAKeyKey] = ca.keyPEM
+		renewed, caRenewed = true, true
+	}
+
+	serving, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if caRenewed && err == nil && now.Before(serving.cert.NotAfter) &&
+		isSignedByAny(serving.cert, parseCertificates(secret.Data[CACertKey])) {
+		return renewed, nil
+	}
+	if renewed || err != nil ||
+		needsRenewal(serving.cert, now, r.RenewBefore) ||
+		!coversDNSNames(serving.cert, r.DNSNames) ||
+		serving.cert.CheckSignatureFrom(ca.cert) != nil {
+
+		serving, err = newServingCert(ca, r.DNSNames, r.CertValidity, now)
+		if err != nil {
+			return false, err
+		}
+		secret.Data[corev1.TLSCertKey] = serving.certPEM
+		secret.Data[corev1.TLSPrivateKeyKey] = serving.keyPEM
+		renewed = true
+	}
+	return renewed, nil
+}
+
+// isSignedByAny returns true if a certificate is signed by any of CAs.
+func isSignedByAny(cert *x509.Certificate, cas []*x509.Certificate) bool {
+	for _, ca := range cas {
+		if cert.CheckSignatureFrom(ca) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// injectCABundle patches caBundle of all webhooks of the configured webhook configurations and CRDs,
+// ones which are not installed are skipped.
+func (r *Rotator) injectCABundle(ctx context.Context, caBundle []byte) error {
+	for _, name := range r.MutatingWebhookConfigurations {
+		config := new(admissionregistrationv1.MutatingWebhookConfiguration)
+		if err := r.patchCABundle(ctx, name, config, caBundle, func() []*[]byte {
+			caBundles := make([]*[]byte, len(config.Webhooks))
+			for i := range config.Webhooks {
+				caBundles[i] = &config.Webhooks[i].ClientConfig.CABundle
+			}
+			return caBundles
+		}); err != nil {
+			return err
+		}
+	}
+	for _, name := range r.ValidatingWebhookConfigurations {
+		config := new(admissionregistrationv1.ValidatingWebhookConfiguration)
+		if err := r.patchCABundle(ctx, name, config, caBundle, func() []*[]byte {
+			caBundles := make([]*[]byte, len(config.Webhooks))
+			for i := range config.Webhooks {
+				caBundles[i] = &config.Webhooks[i].ClientConfig.CABundle
+			}
+			return caBundles
+		}); err != nil {
+			return err
+		}
+	}
+	for _, name := range r.CustomResourceDefinitions {
+		crd := new(apiextensionsv1.CustomResourceDefinition)
+		if err := r.patchCABundle(ctx, name, crd, caBundle, func() []*[]byte {
+			conversion := crd.Spec.Conversion
+			if conversion == nil || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+				return nil
+			}
+			return []*[]byte{&conversion.Webhook.ClientConfig.CABundle}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchCABundle gets a webhook configuration or a CRD, and patches its caBundles if they differ.
+func (r *Rotator) patchCABundle(
+	ctx context.Context,
+	name string,
+	obj client.Object,
+	caBundle []byte,
+	caBundles func() []*[]byte) error {
+
+	if err := r.Reader.Get(ctx, client.ObjectKey{Name: name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get %T %s", obj, name)
+	}
+
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	changed := false
+	for _, current := range caBundles() {
+		if !bytes.Equal(*current, caBundle) {
+			*current = caBundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	rotatorlog.Info("injecting webhook CA bundle", "name", name)
+	return errors.Wrapf(r.Client.Patch(ctx, obj, patch), "failed to patch %T %s", obj, name)
+}
@@ -0,0 +1,250 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Rotator", func() {
+	secretKey := types.NamespacedName{Namespace: "tensegrity", Name: "tensegrity-webhook-server-cert"}
+	dnsNames := ServiceDNSNames("tensegrity-webhook-service", "tensegrity")
+
+	var ctx context.Context
+	var c client.Client
+	var rotator *Rotator
+	var now time.Time
+	var failPatches bool
+
+	getSecret := func() *corev1.Secret {
+		secret := new(corev1.Secret)
+		Expect(c.Get(ctx, secretKey, secret)).To(Succeed())
+		return secret
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now()
+		failPatches = false
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&admissionregistrationv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "tensegrity-mutating-webhook-configuration"},
+				Webhooks: []admissionregistrationv1.MutatingWebhook{
+					{Name: "mdeployment.kb.io"}, {Name: "mstatic.kb.io"}},
+			},
+			&admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "tensegrity-validating-webhook-configuration"},
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{
+					{Name: "vdeployment.kb.io"}, {Name: "vstatic.kb.io"}},
+			},
+			&apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "statics.tensegrity.fastforge.io"},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Conversion: &apiextensionsv1.CustomResourceConversion{
+						Strategy: apiextensionsv1.WebhookConverter,
+						Webhook: &apiextensionsv1.WebhookConversion{
+							ClientConfig: new(apiextensionsv1.WebhookClientConfig)},
+					},
+				},
+			},
+			&apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployments.k8s.tensegrity.fastforge.io"},
+			},
+		).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+				opts ...client.PatchOption) error {
+				if failPatches {
+					return errors.New("patches are forbidden")
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+
+		rotator = NewRotator(c, c, secretKey, dnsNames...)
+		rotator.MutatingWebhookConfigurations = []string{"tensegrity-mutating-webhook-configuration"}
+		rotator.ValidatingWebhookConfigurations = []string{
+			"tensegrity-validating-webhook-configuration", "missing-webhook-configuration"}
+		rotator.CustomResourceDefinitions = []string{
+			"statics.tensegrity.fastforge.io", "deployments.k8s.tensegrity.fastforge.io"}
+		rotator.now = func() time.Time { return now }
+	})
+
+	It("should create certificates and inject the CA bundle", func() {
+		Expect(rotator.ReadyCheck(nil)).NotTo(Succeed())
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		Expect(rotator.ReadyCheck(nil)).To(Succeed())
+
+		secret := getSecret()
+		Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+		roots := x509.NewCertPool()
+		Expect(roots.AppendCertsFromPEM(secret.Data[CACertKey])).To(BeTrue())
+
+		certificate, err := rotator.GetCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+		serving, err := x509.ParseCertificate(certificate.Certificate[0])
+		Expect(err).NotTo(HaveOccurred())
+		for _, dnsName := range dnsNames {
+			_, err = serving.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		mutating := new(admissionregistrationv1.MutatingWebhookConfiguration)
+		Expect(c.Get(ctx, client.ObjectKey{Name: "tensegrity-mutating-webhook-configuration"}, mutating)).To(Succeed())
+		for _, webhook := range mutating.Webhooks {
+			Expect(webhook.ClientConfig.CABundle).To(Equal(secret.Data[CACertKey]))
+		}
+		validating := new(admissionregistrationv1.ValidatingWebhookConfiguration)
+		Expect(c.Get(ctx, client.ObjectKey{Name: "tensegrity-validating-webhook-configuration"}, validating)).To(Succeed())
+		for _, webhook := range validating.Webhooks {
+			Expect(webhook.ClientConfig.CABundle).To(Equal(secret.Data[CACertKey]))
+		}
+		crd := new(apiextensionsv1.CustomResourceDefinition)
+		Expect(c.Get(ctx, client.ObjectKey{Name: "statics.tensegrity.fastforge.io"}, crd)).To(Succeed())
+		Expect(crd.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal(secret.Data[CACertKey]))
+	})
+
+	It("should keep valid certificates", func() {
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		secret := getSecret()
+
+		now = now.Add(24 * time.Hour)
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		Expect(getSecret().ResourceVersion).To(Equal(secret.ResourceVersion))
+	})
+
+	It("should renew the serving certificate before it expires", func() {
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		secret := getSecret()
+
+		now = now.Add(DefaultCertValidity - DefaultRenewBefore + time.Hour)
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		renewed := getSecret()
+		Expect(renewed.Data[CACertKey]).To(Equal(secret.Data[CACertKey]))
+		Expect(renewed.Data[corev1.TLSCertKey]).NotTo(Equal(secret.Data[corev1.TLSCertKey]))
+	})
+
+	It("should renew the serving certificate when DNS names change", func() {
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		secret := getSecret()
+
+		rotator.DNSNames = ServiceDNSNames("tensegrity-webhook-service", "tensegrity-system")
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		Expect(getSecret().Data[corev1.TLSCertKey]).NotTo(Equal(secret.Data[corev1.TLSCertKey]))
+	})
+
+	It("should renew the CA and keep trusting the previous one", func() {
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		secret := getSecret()
+		previous := parseCertificates(secret.Data[CACertKey])
+
+		now = now.Add(DefaultCAValidity - DefaultRenewBefore + time.Hour)
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		bundle := parseCertificates(getSecret().Data[CACertKey])
+		Expect(bundle).To(HaveLen(2))
+		Expect(bundle[0].Equal(previous[0])).To(BeFalse())
+		Expect(bundle[1].Equal(previous[0])).To(BeTrue())
+	})
+
+	It("should replace invalid certificates", func() {
+		Expect(c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("invalid")},
+		})).To(Succeed())
+
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		Expect(parseCertificates(getSecret().Data[CACertKey])).To(HaveLen(1))
+	})
+
+	It("should inject a renewed CA before serving a certificate signed by it", func() {
+		servedCertificate := func() *x509.Certificate {
+			certificate, err := rotator.GetCertificate(nil)
+			Expect(err).NotTo(HaveOccurred())
+			served, err := x509.ParseCertificate(certificate.Certificate[0])
+			Expect(err).NotTo(HaveOccurred())
+			return served
+		}
+		injectedCABundle := func() []byte {
+			mutating := new(admissionregistrationv1.MutatingWebhookConfiguration)
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tensegrity-mutating-webhook-configuration"}, mutating)).To(Succeed())
+			return mutating.Webhooks[0].ClientConfig.CABundle
+		}
+
+		rotator.CAValidity = DefaultCertValidity
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		previous := servedCertificate()
+
+		By("adding the renewed CA to the bundle, while the serving certificate is kept")
+		now = now.Add(DefaultCertValidity - DefaultRenewBefore + time.Hour)
+		failPatches = true
+		Expect(rotator.Rotate(ctx)).NotTo(Succeed())
+		secret := getSecret()
+		bundle := parseCertificates(secret.Data[CACertKey])
+		Expect(bundle).To(HaveLen(2))
+		Expect(previous.CheckSignatureFrom(bundle[1])).To(Succeed())
+		Expect(servedCertificate().Equal(previous)).To(BeTrue())
+
+		By("keeping the served certificate until the bundle is injected")
+		Expect(rotator.Rotate(ctx)).NotTo(Succeed())
+		renewed := parseCertificates(getSecret().Data[corev1.TLSCertKey])
+		Expect(renewed[0].CheckSignatureFrom(bundle[0])).To(Succeed())
+		Expect(servedCertificate().Equal(previous)).To(BeTrue())
+		Expect(injectedCABundle()).NotTo(Equal(getSecret().Data[CACertKey]))
+
+		By("serving the certificate signed by the renewed CA once the bundle is injected")
+		failPatches = false
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		Expect(injectedCABundle()).To(Equal(getSecret().Data[CACertKey]))
+		Expect(servedCertificate().Equal(renewed[0])).To(BeTrue())
+	})
+
+	It("should serve a certificate signed by the current CA only a check after the CA is renewed", func() {
+		rotator.CAValidity = DefaultCertValidity
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		secret := getSecret()
+
+		now = now.Add(DefaultCertValidity - DefaultRenewBefore + time.Hour)
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		Expect(getSecret().Data[corev1.TLSCertKey]).To(Equal(secret.Data[corev1.TLSCertKey]))
+
+		Expect(rotator.Rotate(ctx)).To(Succeed())
+		renewed := getSecret()
+		Expect(renewed.Data[corev1.TLSCertKey]).NotTo(Equal(secret.Data[corev1.TLSCertKey]))
+		serving := parseCertificates(renewed.Data[corev1.TLSCertKey])
+		Expect(serving[0].CheckSignatureFrom(parseCertificates(renewed.Data[CACertKey])[0])).To(Succeed())
+	})
+})
@@ -0,0 +1,201 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+// maxOwnerDepth limits how far controller owners of a Pod are followed looking for a binding,
+// e.g. Pod -> ReplicaSet -> Rollout.
+const maxOwnerDepth = 3
+
+const (
+	injectedSecretPrefix    = "Secret/"
+	injectedConfigMapPrefix = "ConfigMap/"
+)
+
+var podlog = logf.Log.WithName("pod-resource")
+
+// SetupPodWebhookWithManager will setup the manager to manage the Pod webhook
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(new(corev1.Pod)).
+		WithDefaulter(&PodCustomDefaulter{Client: mgr.GetClient(), APIReader: mgr.GetAPIReader()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.kb.io,admissionReviewVersions=v1
+
+// PodCustomDefaulter injects consumed Secret and ConfigMap of a Static bound to a Pod into envFrom
+// of its containers, it covers Pods created by controllers which cannot be wrapped.
+// The webhook is registered only for namespaces labeled with InjectionLabel.
+type PodCustomDefaulter struct {
+	// Client reads bound Statics, which are cached by the manager anyway.
+	Client client.Reader
+	// APIReader reads metadata of owners of arbitrary kinds, so no informers are started for them.
+	APIReader client.Reader
+}
+
+var _ webhook.CustomDefaulter = &PodCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (d *PodCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod but got a %T", obj)
+	}
+
+	namespace := pod.GetNamespace()
+	if len(namespace) == 0 {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			namespace = req.Namespace
+		}
+	}
+
+	name, err := d.getBinding(ctx, namespace, &pod.ObjectMeta)
+	if err != nil {
+		podlog.Error(err, "unable to get binding", "namespace", namespace, "pod", podName(pod))
+		return nil
+	}
+	if len(name) == 0 {
+		return nil
+	}
+
+	static := new(v1alpha1.Static)
+	if err = d.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, static); err != nil {
+		if !apierrors.IsNotFound(err) {
+			podlog.Error(err, "unable to get Static", "namespace", namespace, "name", name)
+		}
+		return nil
+	}
+
+	injectConsumed(pod, static.Status.ConsumedSecretName, static.Status.ConsumedConfigMapName)
+	return nil
+}
+
+// getBinding returns name of a Static bound to a Pod by StaticLabel,
+// the label or annotation is looked up on the Pod first and then on its controller owners.
+func (d *PodCustomDefaulter) getBinding(ctx context.Context, namespace string, meta *metav1.ObjectMeta) (string, error) {
+	for depth := 0; ; depth++ {
+		if name := bindingOf(meta); len(name) > 0 {
+			return name, nil
+		}
+		owner := metav1.GetControllerOfNoCopy(meta)
+		if owner == nil || depth == maxOwnerDepth {
+			return "", nil
+		}
+		object := new(metav1.PartialObjectMetadata)
+		object.SetGroupVersionKind(schema.FromAPIVersionAndKind(owner.APIVersion, owner.Kind))
+		if err := d.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: owner.Name}, object); err != nil {
+			return "", client.IgnoreNotFound(err)
+		}
+		meta = &object.ObjectMeta
+	}
+}
+
+func bindingOf(meta *metav1.ObjectMeta) string {
+	if name := meta.Labels[v1alpha1.StaticLabel]; len(name) > 0 {
+		return name
+	}
+	return meta.Annotations[v1alpha1.StaticLabel]
+}
+
+// injectConsumed adds envFrom of consumed Secret and ConfigMap to all containers of a Pod,
+// skipping ones already present, and lists them in InjectedAnnotation.
+func injectConsumed(pod *corev1.Pod, secretName, configMapName string) {
+	var sources []string
+	var envFrom []corev1.EnvFromSource
+	if len(secretName) > 0 {
+		sources = append(sources, injectedSecretPrefix+secretName)
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+			},
+		})
+	}
+	if len(configMapName) > 0 {
+		sources = append(sources, injectedConfigMapPrefix+configMapName)
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+			},
+		})
+	}
+	if len(envFrom) == 0 {
+		return
+	}
+
+	for i := range pod.Spec.InitContainers {
+		appendEnvFrom(&pod.Spec.InitContainers[i], envFrom)
+	}
+	for i := range pod.Spec.Containers {
+		appendEnvFrom(&pod.Spec.Containers[i], envFrom)
+	}
+
+	sort.Strings(sources)
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[v1alpha1.InjectedAnnotation] = strings.Join(sources, ",")
+}
+
+func appendEnvFrom(container *corev1.Container, envFrom []corev1.EnvFromSource) {
+	for _, source := range envFrom {
+		if !hasEnvFrom(container.EnvFrom, source) {
+			container.EnvFrom = append(container.EnvFrom, source)
+		}
+	}
+}
+
+func hasEnvFrom(envFrom []corev1.EnvFromSource, source corev1.EnvFromSource) bool {
+	for _, existing := range envFrom {
+		if source.SecretRef != nil && existing.SecretRef != nil &&
+			existing.SecretRef.Name == source.SecretRef.Name {
+			return true
+		}
+		if source.ConfigMapRef != nil && existing.ConfigMapRef != nil &&
+			existing.ConfigMapRef.Name == source.ConfigMapRef.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func podName(pod *corev1.Pod) string {
+	if len(pod.Name) > 0 {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
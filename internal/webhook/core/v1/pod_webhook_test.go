@@ -0,0 +1,123 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package v1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fastforgeinc/tensegrity/api/v1alpha1"
+)
+
+var _ = Describe("Pod Webhook", func() {
+	const namespace = "default"
+
+	ctx := context.Background()
+
+	static := &v1alpha1.Static{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout", Namespace: namespace},
+		Status: v1alpha1.StaticStatus{TensegrityStatus: v1alpha1.TensegrityStatus{
+			ConsumedSecretName:    "rollout-consumed",
+			ConsumedConfigMapName: "rollout-consumed",
+		}},
+	}
+
+	newPod := func(labels map[string]string, owners ...metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName:    "rollout-",
+				Namespace:       namespace,
+				Labels:          labels,
+				OwnerReferences: owners,
+			},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "migrate"}},
+				Containers:     []corev1.Container{{Name: "app"}},
+			},
+		}
+	}
+
+	expectInjected := func(pod *corev1.Pod) {
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			Expect(container.EnvFrom).To(ConsistOf(
+				corev1.EnvFromSource{SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "rollout-consumed"}}},
+				corev1.EnvFromSource{ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "rollout-consumed"}}},
+			))
+		}
+		Expect(pod.Annotations).To(HaveKeyWithValue(
+			v1alpha1.InjectedAnnotation, "ConfigMap/rollout-consumed,Secret/rollout-consumed"))
+	}
+
+	Context("When creating Pod", func() {
+		It("Should inject consumed Secret and ConfigMap of a Static bound by a label", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(static.DeepCopy()).Build()
+			defaulter := &PodCustomDefaulter{Client: c, APIReader: c}
+			pod := newPod(map[string]string{v1alpha1.StaticLabel: "rollout"})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectInjected(pod)
+
+			By("Not duplicating envFrom on reinvocation")
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectInjected(pod)
+		})
+
+		It("Should inject consumed Secret and ConfigMap of a Static bound to a controller owner read by APIReader", func() {
+			deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name: "rollout", Namespace: namespace,
+				Annotations: map[string]string{v1alpha1.StaticLabel: "rollout"},
+			}}
+			replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name: "rollout-5d4f8", Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1", Kind: "Deployment", Name: "rollout", Controller: ptr.To(true),
+				}},
+			}}
+			defaulter := &PodCustomDefaulter{
+				Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(static.DeepCopy()).Build(),
+				APIReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, replicaSet).Build(),
+			}
+			pod := newPod(nil, metav1.OwnerReference{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rollout-5d4f8", Controller: ptr.To(true),
+			})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			expectInjected(pod)
+		})
+
+		It("Should not modify a Pod without a binding or with a missing Static", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			defaulter := &PodCustomDefaulter{Client: c, APIReader: c}
+			pod := newPod(nil)
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			Expect(pod).To(Equal(newPod(nil)))
+
+			pod = newPod(map[string]string{v1alpha1.StaticLabel: "missing"})
+			Expect(defaulter.Default(ctx, pod)).To(Succeed())
+			Expect(pod).To(Equal(newPod(map[string]string{v1alpha1.StaticLabel: "missing"})))
+		})
+	})
+})
@@ -0,0 +1,181 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package migration
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	DefaultPageSize = 100
+
+	retryInterval = 30 * time.Second
+)
+
+var migratorlog = logf.Log.WithName("storage-version-migrator")
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update
+//+kubebuilder:rbac:groups=k8s.tensegrity.fastforge.io,resources=deployments;statefulsets;daemonsets;jobs;cronjobs,verbs=list
+//+kubebuilder:rbac:groups=k8s.tensegrity.fastforge.io,resources=deployments/status;statefulsets/status;daemonsets/status;jobs/status;cronjobs/status,verbs=update
+//+kubebuilder:rbac:groups=tensegrity.fastforge.io,resources=statics,verbs=list
+//+kubebuilder:rbac:groups=tensegrity.fastforge.io,resources=statics/status,verbs=update
+
+// StorageVersionMigrator rewrites resources of CustomResourceDefinitions which were stored in versions
+// other than the storage version, and then drops those versions from status.storedVersions of CRDs,
+// so they could be removed from CRDs in later releases.
+type StorageVersionMigrator struct {
+	// Client updates resources and CRDs, defaults to the manager client.
+	Client client.Client
+	// Reader reads resources and CRDs, defaults to the uncached manager API reader.
+	Reader client.Reader
+	// CustomResourceDefinitions are names of CRDs to migrate.
+	CustomResourceDefinitions []string
+	// PageSize is the number of resources listed at once.
+	PageSize int64
+}
+
+// NewStorageVersionMigrator creates a StorageVersionMigrator of the CRDs.
+func NewStorageVersionMigrator(client client.Client, reader client.Reader, crds ...string) *StorageVersionMigrator {
+	return &StorageVersionMigrator{
+		Client:                    client,
+		Reader:                    reader,
+		CustomResourceDefinitions: crds,
+		PageSize:                  DefaultPageSize,
+	}
+}
+
+// SetupWithManager adds the StorageVersionMigrator to the manager.
+func (m *StorageVersionMigrator) SetupWithManager(mgr ctrl.Manager) error {
+	if m.Client == nil {
+		m.Client = mgr.GetClient()
+	}
+	if m.Reader == nil {
+		m.Reader = mgr.GetAPIReader()
+	}
+	return mgr.Add(m)
+}
+
+var _ manager.LeaderElectionRunnable = &StorageVersionMigrator{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader migrates resources.
+func (m *StorageVersionMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable, it migrates CRDs and retries failed ones until all are migrated
+// or the context is done.
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	pending := slices.Clone(m.CustomResourceDefinitions)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+		pending = slices.DeleteFunc(pending, func(name string) bool {
+			if err := m.Migrate(ctx, name); err != nil {
+				migratorlog.Error(err, "failed to migrate storage version", "crd", name)
+				return false
+			}
+			return true
+		})
+		timer.Reset(retryInterval)
+	}
+	return nil
+}
+
+// Migrate rewrites all resources of the CRD in its storage version, unless the storage version is
+// the only stored version, and then sets it as the only stored version.
+func (m *StorageVersionMigrator) Migrate(ctx context.Context, name string) error {
+	crd := new(apiextensionsv1.CustomResourceDefinition)
+	if err := m.Reader.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		return errors.Wrapf(err, "failed to get CustomResourceDefinition %s", name)
+	}
+
+	storageVersion := ""
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			storageVersion = version.Name
+		}
+	}
+	if len(storageVersion) == 0 {
+		return errors.Errorf("CustomResourceDefinition %s has no storage version", name)
+	}
+	if slices.Equal(crd.Status.StoredVersions, []string{storageVersion}) {
+		return nil
+	}
+
+	migratorlog.Info("migrating storage version", "crd", name,
+		"storedVersions", crd.Status.StoredVersions, "storageVersion", storageVersion)
+	gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: storageVersion, Kind: crd.Spec.Names.ListKind}
+	if err := m.rewrite(ctx, gvk); err != nil {
+		return err
+	}
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	return errors.Wrapf(m.Client.Status().Update(ctx, crd),
+		"failed to update stored versions of CustomResourceDefinition %s", name)
+}
+
+// rewrite lists resources page by page and updates the status of each of them without changes,
+// which makes the API server store them in the storage version. Webhooks don't admit status updates,
+// so specs are neither defaulted nor validated again. Resources which fail to be rewritten are skipped,
+// so others are still migrated, and an error is returned once all resources are listed.
+func (m *StorageVersionMigrator) rewrite(ctx context.Context, gvk schema.GroupVersionKind) error {
+	list := new(unstructured.UnstructuredList)
+	list.SetGroupVersionKind(gvk)
+	opts := []client.ListOption{client.Limit(m.PageSize)}
+	failed := 0
+	for {
+		if err := m.Reader.List(ctx, list, opts...); err != nil {
+			return errors.Wrapf(err, "failed to list %s", gvk.Kind)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			// resources deleted or changed since listing are already stored in the storage version
+			if err := m.Client.Status().Update(ctx, item); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+				migratorlog.Error(err, "failed to migrate storage version", "kind", item.GetKind(),
+					"namespace", item.GetNamespace(), "name", item.GetName())
+				failed++
+			}
+		}
+		if len(list.GetContinue()) == 0 {
+			break
+		}
+		opts = []client.ListOption{client.Limit(m.PageSize), client.Continue(list.GetContinue())}
+	}
+	if failed > 0 {
+		return errors.Errorf("failed to migrate %d resources of %s", failed, gvk.Kind)
+	}
+	return nil
+}
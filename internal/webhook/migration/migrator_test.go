@@ -0,0 +1,147 @@
+/*
+This file is part of the Tensegrity distribution (https://github.com/fastforgeinc/tensegrity)
+Copyright (C) 2024 FastForge, Inc.
+
+Tensegrity is free software: you can redistribute it and/or modify it
+under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE. See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along with
+this program. If not, see http://www.gnu.org/licenses/.
+*/
+
+package migration
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	apiv1beta1 "github.com/fastforgeinc/tensegrity/api/v1beta1"
+)
+
+var _ = Describe("StorageVersionMigrator", func() {
+	const crdName = "statics.tensegrity.fastforge.io"
+
+	var ctx context.Context
+	var c client.Client
+	var migrator *StorageVersionMigrator
+
+	newCRD := func(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: apiv1beta1.GroupVersion.Group,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Static", ListKind: "StaticList"},
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1", Served: true},
+					{Name: "v1beta1", Served: true, Storage: true},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+
+	build := func(crd *apiextensionsv1.CustomResourceDefinition, funcs interceptor.Funcs) {
+		scheme := runtime.NewScheme()
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(apiv1beta1.AddToScheme(scheme)).To(Succeed())
+		objects := []client.Object{crd}
+		for _, name := range []string{"postgres", "redis", "kafka"} {
+			objects = append(objects, &apiv1beta1.Static{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			})
+		}
+		c = fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(objects...).WithStatusSubresource(crd, new(apiv1beta1.Static)).
+			WithInterceptorFuncs(funcs).Build()
+		migrator = NewStorageVersionMigrator(c, c, crdName)
+		migrator.PageSize = 2
+	}
+
+	getStatic := func(name string) *apiv1beta1.Static {
+		static := new(apiv1beta1.Static)
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, static)).To(Succeed())
+		return static
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should rewrite resources and drop previous stored versions", func() {
+		build(newCRD("v1alpha1", "v1beta1"), interceptor.Funcs{})
+		previous := getStatic("kafka").ResourceVersion
+
+		Expect(migrator.Migrate(ctx, crdName)).To(Succeed())
+		Expect(getStatic("kafka").ResourceVersion).NotTo(Equal(previous))
+
+		crd := new(apiextensionsv1.CustomResourceDefinition)
+		Expect(c.Get(ctx, client.ObjectKey{Name: crdName}, crd)).To(Succeed())
+		Expect(crd.Status.StoredVersions).To(Equal([]string{"v1beta1"}))
+	})
+
+	It("should skip CRDs stored only in the storage version", func() {
+		build(newCRD("v1beta1"), interceptor.Funcs{})
+		previous := getStatic("kafka").ResourceVersion
+
+		Expect(migrator.Migrate(ctx, crdName)).To(Succeed())
+		Expect(getStatic("kafka").ResourceVersion).To(Equal(previous))
+	})
+
+	It("should fail when a CRD is missing", func() {
+		build(newCRD("v1beta1"), interceptor.Funcs{})
+		Expect(migrator.Migrate(ctx, "missing.tensegrity.fastforge.io")).NotTo(Succeed())
+	})
+
+	It("should rewrite resources through the status subresource, so webhooks don't admit them", func() {
+		build(newCRD("v1alpha1", "v1beta1"), interceptor.Funcs{
+			Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+				return errors.New("admission webhook denied the request")
+			},
+		})
+		previous := getStatic("kafka").ResourceVersion
+
+		Expect(migrator.Migrate(ctx, crdName)).To(Succeed())
+		Expect(getStatic("kafka").ResourceVersion).NotTo(Equal(previous))
+	})
+
+	It("should migrate other resources when one fails, and keep previous stored versions", func() {
+		build(newCRD("v1alpha1", "v1beta1"), interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object,
+				opts ...client.SubResourceUpdateOption) error {
+				if obj.GetName() == "postgres" {
+					return errors.New("status is invalid")
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		})
+		previous := map[string]string{}
+		for _, name := range []string{"postgres", "redis", "kafka"} {
+			previous[name] = getStatic(name).ResourceVersion
+		}
+
+		Expect(migrator.Migrate(ctx, crdName)).To(MatchError(ContainSubstring("failed to migrate 1 resources")))
+		Expect(getStatic("postgres").ResourceVersion).To(Equal(previous["postgres"]))
+		Expect(getStatic("redis").ResourceVersion).NotTo(Equal(previous["redis"]))
+		Expect(getStatic("kafka").ResourceVersion).NotTo(Equal(previous["kafka"]))
+
+		crd := new(apiextensionsv1.CustomResourceDefinition)
+		Expect(c.Get(ctx, client.ObjectKey{Name: crdName}, crd)).To(Succeed())
+		Expect(crd.Status.StoredVersions).To(Equal([]string{"v1alpha1", "v1beta1"}))
+	})
+})